@@ -8,9 +8,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
+	// SYNC_TIMEZONE must load on hosts without zoneinfo.
+	_ "time/tzdata"
 
+	"partijgedrag/internal/analysis"
 	"partijgedrag/internal/cache"
 	"partijgedrag/internal/categorize"
 	"partijgedrag/internal/config"
@@ -19,8 +25,12 @@ import (
 	"partijgedrag/internal/ingest"
 	"partijgedrag/internal/inspect"
 	"partijgedrag/internal/migrate"
+	"partijgedrag/internal/quality"
+	"partijgedrag/internal/schedule"
 	"partijgedrag/internal/source/officielebekendmakingen"
+	"partijgedrag/internal/source/transport"
 	"partijgedrag/internal/source/tweedekamer"
+	"partijgedrag/internal/source/tweedekamer/syncfeed"
 	"partijgedrag/internal/status"
 )
 
@@ -59,9 +69,9 @@ func run() error {
 	case "sync":
 		return runSync(ctx, cfg, database, args[1:])
 	case "status":
-		return runStatus(ctx, database, args[1:])
+		return runStatus(ctx, cfg, database, args[1:])
 	case "maintenance":
-		return runMaintenance(ctx, database, args[1:])
+		return runMaintenance(ctx, cfg, database, args[1:])
 	case "inspect":
 		return runInspect(ctx, database, args[1:])
 	case "serve":
@@ -70,8 +80,8 @@ func run() error {
 		}
 		cache.Global().Init(ctx, database.Pool)
 
-		if cfg.SyncInterval > 0 {
-			fmt.Printf("built-in sync scheduler enabled interval=%s (set SYNC_INTERVAL=0 to disable)\n", cfg.SyncInterval)
+		if cfg.SyncInterval > 0 || len(cfg.SyncSchedules) > 0 {
+			fmt.Printf("built-in sync scheduler enabled interval=%s schedules=%d (see status schedule)\n", cfg.SyncInterval, len(cfg.SyncSchedules))
 			go runPeriodicSync(ctx, cfg, database)
 		}
 
@@ -92,7 +102,7 @@ func run() error {
 	}
 }
 
-func runMaintenance(ctx context.Context, database *db.DB, args []string) error {
+func runMaintenance(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
 	if len(args) == 0 {
 		return usage()
 	}
@@ -102,6 +112,16 @@ func runMaintenance(ctx context.Context, database *db.DB, args []string) error {
 		return runMaintenanceFailStaleRuns(ctx, database, args[1:])
 	case "categorize":
 		return runMaintenanceCategorize(ctx, database, args[1:])
+	case "reproject":
+		return runMaintenanceReproject(ctx, database, args[1:])
+	case "retry-failures":
+		return runMaintenanceRetryFailures(ctx, cfg, database, args[1:])
+	case "reconcile":
+		return runMaintenanceReconcile(ctx, cfg, database, args[1:])
+	case "data-quality":
+		return runMaintenanceDataQuality(ctx, database, args[1:])
+	case "ideal-points":
+		return runMaintenanceIdealPoints(ctx, database, args[1:])
 	default:
 		return usage()
 	}
@@ -138,6 +158,308 @@ func runMaintenanceCategorize(ctx context.Context, database *db.DB, args []strin
 	return nil
 }
 
+func runMaintenanceReproject(ctx context.Context, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("maintenance reproject", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	collectionValue := flags.String("collection", "", "raw_records collection to replay (Fractie, Persoon, Zaak, Besluit, Stemming), default all")
+	batchSize := flags.Int("batch-size", 500, "records to replay per transaction")
+	limit := flags.Int("limit", 10, "maximum changed records to list per collection")
+	apply := flags.Bool("apply", false, "write changes; without this flag the command is a dry run")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be greater than 0")
+	}
+	if *limit < 0 {
+		return fmt.Errorf("--limit must be 0 or greater")
+	}
+
+	var collections []string
+	if *collectionValue != "" {
+		collections = []string{*collectionValue}
+	}
+
+	stats, err := ingest.Reproject(ctx, database.Pool, ingest.ReprojectOptions{
+		Collections:  collections,
+		Apply:        *apply,
+		BatchSize:    *batchSize,
+		ExampleLimit: *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	action := "would_change"
+	if *apply {
+		action = "changed"
+	}
+	changed := 0
+	for _, collection := range stats.Collections {
+		changed += collection.Changed
+		fields := make([]string, 0, len(collection.Fields))
+		for field, count := range collection.Fields {
+			fields = append(fields, fmt.Sprintf("%s:%d", field, count))
+		}
+		sort.Strings(fields)
+		fmt.Printf("collection=%s seen=%d %s=%d skipped=%d fields=%s\n", collection.Collection, collection.Seen, action, collection.Changed, collection.Skipped, strings.Join(fields, ","))
+		for _, example := range collection.Examples {
+			fmt.Printf("  %s %s\n", example.SourceID, strings.Join(example.Fields, ","))
+		}
+	}
+	if !*apply && changed > 0 {
+		fmt.Println("dry_run=true rerun_with=--apply")
+	}
+	return nil
+}
+
+// runMaintenanceRetryFailures reruns the motions recorded in
+// ingestion_failures right away instead of waiting for their backoff. A motion
+// that succeeds is cleared; one that fails again is rescheduled.
+func runMaintenanceRetryFailures(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("maintenance retry-failures", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	pipeline := flags.String("pipeline", "", "motion-votes or motion-documents, default both")
+	limit := flags.Int("limit", 100, "maximum failed motions to retry per pipeline")
+	concurrency := flags.Int("concurrency", 4, "number of motions to retry in parallel")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("--limit must be greater than 0")
+	}
+	if *concurrency <= 0 {
+		return fmt.Errorf("--concurrency must be greater than 0")
+	}
+	switch *pipeline {
+	case "", "motion-votes", "motion-documents":
+	default:
+		return fmt.Errorf("--pipeline must be motion-votes or motion-documents")
+	}
+
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	if *pipeline == "" || *pipeline == "motion-votes" {
+		job := ingest.TweedeKamerMotionVotesIngest{
+			Pool:          database.Pool,
+			Client:        clients.TweedeKamer,
+			Limit:         *limit,
+			Concurrency:   *concurrency,
+			RetryFailures: true,
+		}
+		if err := job.Run(ctx); err != nil {
+			return err
+		}
+	}
+	if *pipeline == "" || *pipeline == "motion-documents" {
+		job := ingest.TweedeKamerMotionDocumentsIngest{
+			Pool:          database.Pool,
+			Client:        clients.TweedeKamer,
+			Documents:     clients.Documents,
+			Limit:         *limit,
+			Concurrency:   *concurrency,
+			RetryFailures: true,
+		}
+		if err := job.Run(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runMaintenanceReconcile compares upstream counts and ids with the local
+// rows per month, to find records the change feeds missed. Besluiten and
+// Stemmingen it repairs are picked up by the next motion-votes run.
+func runMaintenanceReconcile(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
+	if len(args) == 0 || args[0] != "tweedekamer" {
+		return usage()
+	}
+	flags := flag.NewFlagSet("maintenance reconcile", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	collectionValue := flags.String("collection", "", "comma-separated collections to compare (Fractie, Zaak, Besluit, Stemming), default all")
+	sinceValue := flags.String("since", "", "first GestartOp day to compare as YYYY-MM-DD, default the start of the month a year ago")
+	untilValue := flags.String("until", "", "day to compare up to, exclusive, as YYYY-MM-DD, default now")
+	full := flags.Bool("full", false, "diff the ids of every month, also where the counts agree")
+	limit := flags.Int("limit", 10, "maximum missing ids to list per month")
+	apply := flags.Bool("apply", false, "refetch missing records and tombstone deleted ones; without this flag the command is a dry run")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *limit < 0 {
+		return fmt.Errorf("--limit must be 0 or greater")
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year()-1, now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if *sinceValue != "" {
+		parsed, err := time.Parse(time.DateOnly, *sinceValue)
+		if err != nil {
+			return fmt.Errorf("parse --since: %w", err)
+		}
+		since = parsed
+	}
+	var until time.Time
+	if *untilValue != "" {
+		parsed, err := time.Parse(time.DateOnly, *untilValue)
+		if err != nil {
+			return fmt.Errorf("parse --until: %w", err)
+		}
+		until = parsed
+	}
+
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+	stats, err := ingest.Reconcile(ctx, database.Pool, clients.TweedeKamer, ingest.ReconcileOptions{
+		Collections: splitList(*collectionValue),
+		Since:       since,
+		Until:       until,
+		Full:        *full,
+		Apply:       *apply,
+	})
+	if err != nil {
+		return err
+	}
+
+	differences := 0
+	for _, window := range stats.Windows {
+		if !window.Differs() {
+			continue
+		}
+		line := fmt.Sprintf("collection=%s window=%s remote=%d local=%d", window.Collection, window.Label(), window.RemoteCount, window.LocalCount)
+		if window.Compared {
+			line += fmt.Sprintf(" missing_local=%d missing_remote=%d", len(window.MissingLocal), len(window.MissingRemote))
+			differences += len(window.MissingLocal) + len(window.MissingRemote)
+		}
+		if *apply {
+			line += fmt.Sprintf(" refetched=%d tombstoned=%d", window.Refetched, window.Tombstoned)
+		}
+		fmt.Println(line)
+		for _, id := range window.MissingLocal[:min(len(window.MissingLocal), *limit)] {
+			fmt.Printf("  + %s\n", id)
+		}
+		for _, id := range window.MissingRemote[:min(len(window.MissingRemote), *limit)] {
+			fmt.Printf("  - %s\n", id)
+		}
+	}
+	fmt.Printf("reconcile complete windows=%d differences=%d\n", len(stats.Windows), differences)
+	if !*apply && differences > 0 {
+		fmt.Println("dry_run=true rerun_with=--apply")
+	}
+	return nil
+}
+
+// runMaintenanceDataQuality evaluates the data-quality rules now instead of
+// waiting for the next sync.
+func runMaintenanceDataQuality(ctx context.Context, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("maintenance data-quality", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	rules := flags.String("rule", "", "comma-separated rules to run, default all")
+	staleAfter := flags.Duration("stale-after", quality.DefaultStaleAfter, "how long a voted decision may lack votes before it is reported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *staleAfter <= 0 {
+		return fmt.Errorf("--stale-after must be greater than 0")
+	}
+
+	stats, err := quality.Run(ctx, database.Pool, quality.Options{
+		Rules:      splitList(*rules),
+		StaleAfter: *staleAfter,
+	})
+	if err != nil {
+		return err
+	}
+	printDataQualityStats(stats)
+	return nil
+}
+
+func printDataQualityStats(stats []quality.RuleStats) {
+	for _, rule := range stats {
+		fmt.Printf("data-quality rule=%s open=%d new=%d resolved=%d\n", rule.Rule, rule.Open, rule.New, rule.Resolved)
+	}
+}
+
+// runMaintenanceIdealPoints estimates the ideal points of every cabinet
+// period, or the ones asked for, and replaces the stored estimates.
+func runMaintenanceIdealPoints(ctx context.Context, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("maintenance ideal-points", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	periodValue := flags.String("period", "", "comma-separated cabinet periods to estimate, default all")
+	scopeValue := flags.String("scope", "party,member", "comma-separated scopes to estimate (party, member)")
+	kindValue := flags.String("kind", "", "motion kind to scale (motie, amendement, wetsvoorstel, all), default motie")
+	minVotes := flags.Int("min-votes", 0, "fewest votes on contested motions to be scaled, 0 means 20 for parties and 5 for members")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *minVotes < 0 {
+		return fmt.Errorf("--min-votes must be 0 or greater")
+	}
+	kind, ok := analysis.NormalizeMotionKind(*kindValue)
+	if !ok {
+		return fmt.Errorf("unknown motion kind %q", *kindValue)
+	}
+	scopes := []string{}
+	for _, value := range splitList(*scopeValue) {
+		scope, ok := analysis.NormalizeIdealPointScope(value)
+		if !ok {
+			return fmt.Errorf("unknown ideal-point scope %q", value)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	periods, err := analysis.LoadCabinetPeriods(ctx, database.Pool, "")
+	if err != nil {
+		return err
+	}
+	if keys := splitList(*periodValue); len(keys) > 0 {
+		selected := []analysis.CabinetPeriod{}
+		for _, key := range keys {
+			index := slices.IndexFunc(periods, func(period analysis.CabinetPeriod) bool { return period.PeriodKey == key })
+			if index < 0 {
+				return fmt.Errorf("unknown cabinet period %q", key)
+			}
+			selected = append(selected, periods[index])
+		}
+		periods = selected
+	}
+
+	for _, period := range periods {
+		for _, scope := range scopes {
+			run, err := analysis.ComputeIdealPoints(ctx, database.Pool, analysis.IdealPointOptions{
+				Period:   period,
+				Scope:    scope,
+				Kind:     kind,
+				MinVotes: *minVotes,
+			})
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", period.PeriodKey, scope, err)
+			}
+			fmt.Printf("ideal-points period=%s scope=%s kind=%s subjects=%d motions=%d classified=%.3f apre=%.3f\n", run.PeriodKey, run.Scope, run.Kind, run.Subjects, run.Motions, run.Classified, run.APRE)
+		}
+	}
+	return nil
+}
+
 func runMaintenanceFailStaleRuns(ctx context.Context, database *db.DB, args []string) error {
 	flags := flag.NewFlagSet("maintenance fail-stale-runs", flag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
@@ -183,10 +505,30 @@ func runMaintenanceFailStaleRuns(ctx context.Context, database *db.DB, args []st
 }
 
 func runInspect(ctx context.Context, database *db.DB, args []string) error {
-	if len(args) != 2 || args[0] != "motion" {
+	if len(args) < 2 {
+		return usage()
+	}
+
+	switch args[0] {
+	case "motion":
+		if len(args) != 2 {
+			return usage()
+		}
+		return inspect.PrintMotion(ctx, database.Pool, os.Stdout, args[1])
+	case "history":
+		flags := flag.NewFlagSet("inspect history", flag.ContinueOnError)
+		flags.SetOutput(os.Stderr)
+		all := flags.Bool("all", false, "also list records that never changed")
+		if err := flags.Parse(args[2:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 0 {
+			return usage()
+		}
+		return inspect.PrintHistory(ctx, database.Pool, os.Stdout, args[1], *all)
+	default:
 		return usage()
 	}
-	return inspect.PrintMotion(ctx, database.Pool, os.Stdout, args[1])
 }
 
 func runIngest(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
@@ -199,17 +541,52 @@ func runIngest(ctx context.Context, cfg config.Config, database *db.DB, args []s
 		return runIngestParties(ctx, cfg, database, args[2:])
 	case "party-logos":
 		return runIngestPartyLogos(ctx, cfg, database, args[2:])
+	case "members":
+		return runIngestMembers(ctx, cfg, database, args[2:])
 	case "motions":
-		return runIngestMotions(ctx, cfg, database, args[2:])
+		return runIngestZaken(ctx, cfg, database, "motions", tweedekamer.ZaakSoortMotie, args[2:])
+	case "amendments":
+		return runIngestZaken(ctx, cfg, database, "amendments", tweedekamer.ZaakSoortAmendement, args[2:])
+	case "bills":
+		return runIngestZaken(ctx, cfg, database, "bills", tweedekamer.ZaakSoortWetsvoorstel, args[2:])
 	case "motion-votes":
 		return runIngestMotionVotes(ctx, cfg, database, args[2:])
 	case "motion-documents":
 		return runIngestMotionDocuments(ctx, cfg, database, args[2:])
+	case "syncfeed":
+		return runIngestSyncFeed(ctx, cfg, database, args[2:])
 	default:
 		return usage()
 	}
 }
 
+// sourceClients are the upstream clients of one command. They share the
+// transport selected by SOURCE_TRANSPORT, so a recording covers every request
+// the command makes.
+type sourceClients struct {
+	TweedeKamer *tweedekamer.Client
+	SyncFeed    *syncfeed.Client
+	Documents   *officielebekendmakingen.Client
+}
+
+func newSourceClients(cfg config.Config) (sourceClients, error) {
+	roundTripper, err := transport.New(cfg.SourceTransport, cfg.SourceFixtureDir)
+	if err != nil {
+		return sourceClients{}, err
+	}
+	requestsPerSecond := float64(cfg.TweedeKamerRequestsPerSecond)
+	if cfg.SourceTransport == transport.ModeReplay {
+		// Replayed responses come from disk; there is no server to spare.
+		requestsPerSecond = 0
+	}
+	tweedekamer.ConfigureRateLimit(requestsPerSecond, cfg.TweedeKamerRequestBurst)
+	return sourceClients{
+		TweedeKamer: tweedekamer.NewClientWithTransport(cfg.TweedeKamerODataBaseURL, roundTripper),
+		SyncFeed:    syncfeed.NewClientWithTransport(cfg.TweedeKamerSyncFeedBaseURL, roundTripper),
+		Documents:   officielebekendmakingen.NewClientWithTransport(roundTripper),
+	}, nil
+}
+
 func runIngestParties(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
 	flags := flag.NewFlagSet("ingest tweedekamer parties", flag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
@@ -236,9 +613,58 @@ func runIngestParties(ctx context.Context, cfg config.Config, database *db.DB, a
 		sinceOverride = &parsed
 	}
 
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
 	job := ingest.TweedeKamerPartyIngest{
 		Pool:          database.Pool,
-		Client:        tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL),
+		Client:        clients.TweedeKamer,
+		BatchSize:     *batchSize,
+		MaxPages:      *maxPages,
+		InitialSince:  cfg.TweedeKamerInitialSince,
+		CursorOverlap: cfg.CursorOverlap,
+		SinceOverride: sinceOverride,
+		ResetCursor:   *resetCursor,
+	}
+	return job.Run(ctx)
+}
+
+func runIngestMembers(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("ingest tweedekamer members", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	maxPages := flags.Int("max-pages", cfg.TweedeKamerMaxPages, "maximum OData pages to process, 0 means all")
+	batchSize := flags.Int("batch-size", cfg.TweedeKamerBatchSize, "records per OData page")
+	resetCursor := flags.Bool("reset-cursor", false, "delete the stored cursor before ingesting")
+	sinceValue := flags.String("since", "", "override cursor with an RFC3339 ApiGewijzigdOp timestamp")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be greater than 0")
+	}
+	if *maxPages < 0 {
+		return fmt.Errorf("--max-pages must be 0 or greater")
+	}
+
+	var sinceOverride *time.Time
+	if *sinceValue != "" {
+		parsed, err := time.Parse(time.RFC3339, *sinceValue)
+		if err != nil {
+			return fmt.Errorf("parse --since: %w", err)
+		}
+		sinceOverride = &parsed
+	}
+
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	job := ingest.TweedeKamerMemberIngest{
+		Pool:          database.Pool,
+		Client:        clients.TweedeKamer,
 		BatchSize:     *batchSize,
 		MaxPages:      *maxPages,
 		InitialSince:  cfg.TweedeKamerInitialSince,
@@ -268,9 +694,14 @@ func runIngestPartyLogos(ctx context.Context, cfg config.Config, database *db.DB
 		return fmt.Errorf("--resync-after must be 0 or greater")
 	}
 
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
 	job := ingest.TweedeKamerPartyLogoIngest{
 		Pool:        database.Pool,
-		Client:      tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL),
+		Client:      clients.TweedeKamer,
 		BatchSize:   *batchSize,
 		Concurrency: *concurrency,
 		ResyncAfter: *resyncAfter,
@@ -278,8 +709,10 @@ func runIngestPartyLogos(ctx context.Context, cfg config.Config, database *db.DB
 	return job.Run(ctx)
 }
 
-func runIngestMotions(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
-	flags := flag.NewFlagSet("ingest tweedekamer motions", flag.ContinueOnError)
+// runIngestZaken backs "ingest tweedekamer motions", "amendments" and "bills":
+// they only differ in the Zaak Soort they page through.
+func runIngestZaken(ctx context.Context, cfg config.Config, database *db.DB, name string, kind string, args []string) error {
+	flags := flag.NewFlagSet("ingest tweedekamer "+name, flag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
 	maxPages := flags.Int("max-pages", cfg.TweedeKamerMaxPages, "maximum OData pages to process, 0 means all")
 	batchSize := flags.Int("batch-size", cfg.TweedeKamerBatchSize, "records per OData page")
@@ -304,9 +737,15 @@ func runIngestMotions(ctx context.Context, cfg config.Config, database *db.DB, a
 		sinceOverride = &parsed
 	}
 
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
 	job := ingest.TweedeKamerMotionIngest{
 		Pool:          database.Pool,
-		Client:        tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL),
+		Client:        clients.TweedeKamer,
+		Kind:          kind,
 		BatchSize:     *batchSize,
 		MaxPages:      *maxPages,
 		InitialSince:  cfg.TweedeKamerInitialSince,
@@ -336,9 +775,14 @@ func runIngestMotionVotes(ctx context.Context, cfg config.Config, database *db.D
 		return fmt.Errorf("--resync-after must be 0 or greater")
 	}
 
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
 	job := ingest.TweedeKamerMotionVotesIngest{
 		Pool:        database.Pool,
-		Client:      tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL),
+		Client:      clients.TweedeKamer,
 		Limit:       *limit,
 		Concurrency: *concurrency,
 		ResyncAfter: *resyncAfter,
@@ -365,10 +809,15 @@ func runIngestMotionDocuments(ctx context.Context, cfg config.Config, database *
 		return fmt.Errorf("--resync-after must be 0 or greater")
 	}
 
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
 	job := ingest.TweedeKamerMotionDocumentsIngest{
 		Pool:        database.Pool,
-		Client:      tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL),
-		Documents:   officielebekendmakingen.NewClient(),
+		Client:      clients.TweedeKamer,
+		Documents:   clients.Documents,
 		Limit:       *limit,
 		Concurrency: *concurrency,
 		ResyncAfter: *resyncAfter,
@@ -376,6 +825,44 @@ func runIngestMotionDocuments(ctx context.Context, cfg config.Config, database *
 	return job.Run(ctx)
 }
 
+func runIngestSyncFeed(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("ingest tweedekamer syncfeed", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	categoryValue := flags.String("category", "", "comma-separated feed categories to follow, default Fractie,Zaak,Besluit,Stemming")
+	maxPages := flags.Int("max-pages", cfg.TweedeKamerMaxPages, "maximum feed pages to process per category, 0 means all")
+	skipTokenValue := flags.Int64("skiptoken", -1, "override the cursor with this skiptoken; 0 starts at the beginning of the feed")
+	resetCursor := flags.Bool("reset-cursor", false, "delete the stored cursor before ingesting")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *maxPages < 0 {
+		return fmt.Errorf("--max-pages must be 0 or greater")
+	}
+
+	categories := splitList(*categoryValue)
+
+	var skipTokenOverride *int64
+	if *skipTokenValue >= 0 {
+		skipTokenOverride = skipTokenValue
+	}
+
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	job := ingest.TweedeKamerSyncFeedIngest{
+		Pool:              database.Pool,
+		Client:            clients.TweedeKamer,
+		Feed:              clients.SyncFeed,
+		Categories:        categories,
+		MaxPages:          *maxPages,
+		SkipTokenOverride: skipTokenOverride,
+		ResetCursor:       *resetCursor,
+	}
+	return job.Run(ctx)
+}
+
 func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
 	if len(args) == 0 || args[0] != "tweedekamer" {
 		return usage()
@@ -385,9 +872,15 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	flags.SetOutput(os.Stderr)
 	motionMaxPages := flags.Int("motion-max-pages", cfg.TweedeKamerMaxPages, "maximum motion OData pages to process, 0 means all")
 	motionBatchSize := flags.Int("motion-batch-size", cfg.TweedeKamerBatchSize, "motion records per OData page")
+	amendmentMaxPages := flags.Int("amendment-max-pages", cfg.TweedeKamerMaxPages, "maximum amendment OData pages to process, 0 means all")
+	amendmentBatchSize := flags.Int("amendment-batch-size", cfg.TweedeKamerBatchSize, "amendment records per OData page")
+	billMaxPages := flags.Int("bill-max-pages", cfg.TweedeKamerMaxPages, "maximum bill OData pages to process, 0 means all")
+	billBatchSize := flags.Int("bill-batch-size", cfg.TweedeKamerBatchSize, "bill records per OData page")
 	partyMaxPages := flags.Int("party-max-pages", cfg.TweedeKamerMaxPages, "maximum party OData pages to process, 0 means all")
 	partyBatchSize := flags.Int("party-batch-size", cfg.TweedeKamerBatchSize, "party records per OData page")
 	partyLogoConcurrency := flags.Int("party-logo-concurrency", 4, "number of party logos to download in parallel")
+	memberMaxPages := flags.Int("member-max-pages", cfg.TweedeKamerMaxPages, "maximum member OData pages to process, 0 means all")
+	memberBatchSize := flags.Int("member-batch-size", cfg.TweedeKamerBatchSize, "member records per OData page")
 	motionVoteLimit := flags.Int("motion-vote-limit", 100, "number of known motions to sync votes for")
 	motionVoteConcurrency := flags.Int("motion-vote-concurrency", 4, "number of motions to sync votes for in parallel")
 	motionVoteResyncAfter := flags.Duration("motion-vote-resync-after", 0, "also resync motions whose votes were synced before this duration, e.g. 168h; 0 means only unsynced")
@@ -396,11 +889,12 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	motionDocumentConcurrency := flags.Int("motion-document-concurrency", 4, "number of motions to sync documents for in parallel")
 	motionDocumentResyncAfter := flags.Duration("motion-document-resync-after", 0, "also resync motions whose documents were synced before this duration, e.g. 168h; 0 means only unsynced")
 	motionDocumentResyncGrace := flags.Duration("motion-document-resync-grace", cfg.SyncMotionDocumentResyncGrace, "retry motions that still have no bullet points, until this long after they were proposed; 0 disables")
-	skipParties := flags.Bool("skip-parties", false, "skip party ingestion")
-	skipMotions := flags.Bool("skip-motions", false, "skip motion ingestion")
-	skipMotionVotes := flags.Bool("skip-motion-votes", false, "skip motion vote ingestion")
-	skipMotionDocuments := flags.Bool("skip-motion-documents", false, "skip motion document ingestion")
-	skipCategorize := flags.Bool("skip-categorize", false, "skip motion categorization")
+	useSyncFeed := flags.Bool("syncfeed", cfg.SyncUseSyncFeed, "follow the SyncFeed instead of polling parties, motions, amendments and bills")
+	syncFeedMaxPages := flags.Int("syncfeed-max-pages", cfg.TweedeKamerMaxPages, "maximum feed pages to process per category, 0 means all")
+	transportValue := flags.String("transport", string(cfg.SourceTransport), "live, record to write responses to --fixture-dir, or replay to serve them from it")
+	fixtureDir := flags.String("fixture-dir", cfg.SourceFixtureDir, "directory of recorded responses for --transport=record or replay")
+	only := flags.String("only", "", "comma-separated pipelines to run, without their dependencies; default all")
+	skip := flags.String("skip", "", "comma-separated pipelines to leave out")
 	if err := flags.Parse(args[1:]); err != nil {
 		return err
 	}
@@ -413,6 +907,18 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	if *motionBatchSize <= 0 {
 		return fmt.Errorf("--motion-batch-size must be greater than 0")
 	}
+	if *amendmentMaxPages < 0 {
+		return fmt.Errorf("--amendment-max-pages must be 0 or greater")
+	}
+	if *amendmentBatchSize <= 0 {
+		return fmt.Errorf("--amendment-batch-size must be greater than 0")
+	}
+	if *billMaxPages < 0 {
+		return fmt.Errorf("--bill-max-pages must be 0 or greater")
+	}
+	if *billBatchSize <= 0 {
+		return fmt.Errorf("--bill-batch-size must be greater than 0")
+	}
 	if *partyMaxPages < 0 {
 		return fmt.Errorf("--party-max-pages must be 0 or greater")
 	}
@@ -422,6 +928,12 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	if *partyLogoConcurrency <= 0 {
 		return fmt.Errorf("--party-logo-concurrency must be greater than 0")
 	}
+	if *memberMaxPages < 0 {
+		return fmt.Errorf("--member-max-pages must be 0 or greater")
+	}
+	if *memberBatchSize <= 0 {
+		return fmt.Errorf("--member-batch-size must be greater than 0")
+	}
 	if *motionVoteLimit <= 0 {
 		return fmt.Errorf("--motion-vote-limit must be greater than 0")
 	}
@@ -434,6 +946,18 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	if *motionVoteResyncGrace < 0 {
 		return fmt.Errorf("--motion-vote-resync-grace must be 0 or greater")
 	}
+	sourceTransport, err := transport.ParseMode(*transportValue)
+	if err != nil {
+		return fmt.Errorf("--transport: %w", err)
+	}
+	if sourceTransport != transport.ModeLive && *fixtureDir == "" {
+		return fmt.Errorf("--transport=%s needs --fixture-dir", sourceTransport)
+	}
+	cfg.SourceTransport = sourceTransport
+	cfg.SourceFixtureDir = *fixtureDir
+	if *syncFeedMaxPages < 0 {
+		return fmt.Errorf("--syncfeed-max-pages must be 0 or greater")
+	}
 	if *motionDocumentLimit <= 0 {
 		return fmt.Errorf("--motion-document-limit must be greater than 0")
 	}
@@ -446,16 +970,18 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 	if *motionDocumentResyncGrace < 0 {
 		return fmt.Errorf("--motion-document-resync-grace must be 0 or greater")
 	}
-	if *skipParties && *skipMotions && *skipMotionVotes && *skipMotionDocuments && *skipCategorize {
-		return fmt.Errorf("sync has nothing to do when --skip-parties, --skip-motions, --skip-motion-votes, --skip-motion-documents, and --skip-categorize are set")
-	}
-
 	return syncTweedeKamer(ctx, cfg, database, tweedeKamerSyncSettings{
 		PartyMaxPages:             *partyMaxPages,
 		PartyBatchSize:            *partyBatchSize,
 		PartyLogoConcurrency:      *partyLogoConcurrency,
+		MemberMaxPages:            *memberMaxPages,
+		MemberBatchSize:           *memberBatchSize,
 		MotionMaxPages:            *motionMaxPages,
 		MotionBatchSize:           *motionBatchSize,
+		AmendmentMaxPages:         *amendmentMaxPages,
+		AmendmentBatchSize:        *amendmentBatchSize,
+		BillMaxPages:              *billMaxPages,
+		BillBatchSize:             *billBatchSize,
 		MotionVoteLimit:           *motionVoteLimit,
 		MotionVoteConcurrency:     *motionVoteConcurrency,
 		MotionVoteResyncAfter:     *motionVoteResyncAfter,
@@ -464,11 +990,10 @@ func runSync(ctx context.Context, cfg config.Config, database *db.DB, args []str
 		MotionDocumentConcurrency: *motionDocumentConcurrency,
 		MotionDocumentResyncAfter: *motionDocumentResyncAfter,
 		MotionDocumentResyncGrace: *motionDocumentResyncGrace,
-		SkipParties:               *skipParties,
-		SkipMotions:               *skipMotions,
-		SkipMotionVotes:           *skipMotionVotes,
-		SkipMotionDocuments:       *skipMotionDocuments,
-		SkipCategorize:            *skipCategorize,
+		UseSyncFeed:               *useSyncFeed,
+		SyncFeedMaxPages:          *syncFeedMaxPages,
+		Only:                      splitList(*only),
+		Skip:                      splitList(*skip),
 	})
 }
 
@@ -476,8 +1001,14 @@ type tweedeKamerSyncSettings struct {
 	PartyMaxPages             int
 	PartyBatchSize            int
 	PartyLogoConcurrency      int
+	MemberMaxPages            int
+	MemberBatchSize           int
 	MotionMaxPages            int
 	MotionBatchSize           int
+	AmendmentMaxPages         int
+	AmendmentBatchSize        int
+	BillMaxPages              int
+	BillBatchSize             int
 	MotionVoteLimit           int
 	MotionVoteConcurrency     int
 	MotionVoteResyncAfter     time.Duration
@@ -486,11 +1017,11 @@ type tweedeKamerSyncSettings struct {
 	MotionDocumentConcurrency int
 	MotionDocumentResyncAfter time.Duration
 	MotionDocumentResyncGrace time.Duration
-	SkipParties               bool
-	SkipMotions               bool
-	SkipMotionVotes           bool
-	SkipMotionDocuments       bool
-	SkipCategorize            bool
+	UseSyncFeed               bool
+	SyncFeedMaxPages          int
+	// Only and Skip select pipelines by name; see ingest.Registry.Select.
+	Only []string
+	Skip []string
 }
 
 func defaultSyncSettings(cfg config.Config) tweedeKamerSyncSettings {
@@ -498,136 +1029,369 @@ func defaultSyncSettings(cfg config.Config) tweedeKamerSyncSettings {
 		PartyMaxPages:             cfg.TweedeKamerMaxPages,
 		PartyBatchSize:            cfg.TweedeKamerBatchSize,
 		PartyLogoConcurrency:      4,
+		MemberMaxPages:            cfg.TweedeKamerMaxPages,
+		MemberBatchSize:           cfg.TweedeKamerBatchSize,
 		MotionMaxPages:            cfg.TweedeKamerMaxPages,
 		MotionBatchSize:           cfg.TweedeKamerBatchSize,
+		AmendmentMaxPages:         cfg.TweedeKamerMaxPages,
+		AmendmentBatchSize:        cfg.TweedeKamerBatchSize,
+		BillMaxPages:              cfg.TweedeKamerMaxPages,
+		BillBatchSize:             cfg.TweedeKamerBatchSize,
 		MotionVoteLimit:           cfg.SyncMotionVoteLimit,
 		MotionVoteConcurrency:     4,
 		MotionVoteResyncGrace:     cfg.SyncMotionVoteResyncGrace,
 		MotionDocumentLimit:       cfg.SyncMotionDocumentLimit,
 		MotionDocumentConcurrency: 4,
 		MotionDocumentResyncGrace: cfg.SyncMotionDocumentResyncGrace,
+		UseSyncFeed:               cfg.SyncUseSyncFeed,
+		SyncFeedMaxPages:          cfg.TweedeKamerMaxPages,
 	}
 }
 
 func syncTweedeKamer(ctx context.Context, cfg config.Config, database *db.DB, settings tweedeKamerSyncSettings) error {
-	client := tweedekamer.NewClient(cfg.TweedeKamerODataBaseURL)
-	if !settings.SkipParties {
-		fmt.Println("sync step=parties")
-		job := ingest.TweedeKamerPartyIngest{
-			Pool:          database.Pool,
-			Client:        client,
-			BatchSize:     settings.PartyBatchSize,
-			MaxPages:      settings.PartyMaxPages,
-			InitialSince:  cfg.TweedeKamerInitialSince,
-			CursorOverlap: cfg.CursorOverlap,
-		}
-		if err := job.Run(ctx); err != nil {
-			return err
-		}
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+	registry, err := tweedeKamerPipelines(cfg, database, clients, settings)
+	if err != nil {
+		return err
+	}
+	steps, err := registry.Select(settings.Only, settings.Skip)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("sync has nothing to do, --only and --skip leave no pipelines")
+	}
 
-		// Logos only decorate the party pages, and only parties missing one cost
-		// a request, so a failure here must not hold up the motions and votes
-		// that the site actually depends on.
-		fmt.Println("sync step=party-logos")
-		logos := ingest.TweedeKamerPartyLogoIngest{
-			Pool:        database.Pool,
-			Client:      client,
-			BatchSize:   settings.PartyBatchSize,
-			Concurrency: settings.PartyLogoConcurrency,
-		}
-		if err := logos.Run(ctx); err != nil {
-			if ctx.Err() != nil {
-				return err
-			}
-			fmt.Printf("sync step=party-logos failed, continuing: %v\n", err)
-		}
+	if err := ingest.RunSteps(ctx, steps); err != nil {
+		return err
 	}
 
-	if !settings.SkipMotions {
-		fmt.Println("sync step=motions")
-		job := ingest.TweedeKamerMotionIngest{
-			Pool:          database.Pool,
-			Client:        client,
-			BatchSize:     settings.MotionBatchSize,
-			MaxPages:      settings.MotionMaxPages,
-			InitialSince:  cfg.TweedeKamerInitialSince,
-			CursorOverlap: cfg.CursorOverlap,
-		}
-		if err := job.Run(ctx); err != nil {
-			return err
-		}
+	cache.Global().Invalidate()
+	fmt.Println("sync complete source=tweedekamer")
+	return nil
+}
+
+// tweedeKamerPipelines registers the steps of a sync. A new pipeline only needs
+// a Step here, with its settings and the steps it builds on.
+func tweedeKamerPipelines(cfg config.Config, database *db.DB, clients sourceClients, settings tweedeKamerSyncSettings) (*ingest.Registry, error) {
+	client := clients.TweedeKamer
+	registry := &ingest.Registry{}
+	steps := []ingest.Step{}
+
+	// The feed covers parties, motions, amendments and bills, so it replaces
+	// their polling steps, and the steps that build on them wait for it.
+	// Without it the polling steps share no tables and run side by side.
+	partySteps := []string{"parties"}
+	zaakSteps := []string{"motions", "amendments", "bills"}
+	if settings.UseSyncFeed {
+		partySteps = []string{"syncfeed"}
+		zaakSteps = []string{"syncfeed"}
+		steps = append(steps, ingest.Step{
+			Name:  "syncfeed",
+			Fatal: true,
+			Pipeline: ingest.TweedeKamerSyncFeedIngest{
+				Pool:     database.Pool,
+				Client:   client,
+				Feed:     clients.SyncFeed,
+				MaxPages: settings.SyncFeedMaxPages,
+			},
+		})
+	} else {
+		steps = append(steps, ingest.Step{
+			Name:  "parties",
+			Fatal: true,
+			Pipeline: ingest.TweedeKamerPartyIngest{
+				Pool:          database.Pool,
+				Client:        client,
+				BatchSize:     settings.PartyBatchSize,
+				MaxPages:      settings.PartyMaxPages,
+				InitialSince:  cfg.TweedeKamerInitialSince,
+				CursorOverlap: cfg.CursorOverlap,
+			},
+		})
 	}
 
-	if !settings.SkipMotionVotes {
-		fmt.Println("sync step=motion-votes")
-		job := ingest.TweedeKamerMotionVotesIngest{
-			Pool:        database.Pool,
-			Client:      client,
-			Limit:       settings.MotionVoteLimit,
-			Concurrency: settings.MotionVoteConcurrency,
-			ResyncAfter: settings.MotionVoteResyncAfter,
-			ResyncGrace: settings.MotionVoteResyncGrace,
-		}
-		if err := job.Run(ctx); err != nil {
-			return err
+	steps = append(steps,
+		// Logos only decorate the party pages, and only parties missing one
+		// cost a request, so a failure here must not hold up the motions and
+		// votes that the site actually depends on.
+		ingest.Step{
+			Name:      "party-logos",
+			DependsOn: partySteps,
+			Pipeline: ingest.TweedeKamerPartyLogoIngest{
+				Pool:        database.Pool,
+				Client:      client,
+				BatchSize:   settings.PartyBatchSize,
+				Concurrency: settings.PartyLogoConcurrency,
+			},
+		},
+		ingest.Step{
+			Name:      "members",
+			DependsOn: partySteps,
+			Fatal:     true,
+			Pipeline: ingest.TweedeKamerMemberIngest{
+				Pool:          database.Pool,
+				Client:        client,
+				BatchSize:     settings.MemberBatchSize,
+				MaxPages:      settings.MemberMaxPages,
+				InitialSince:  cfg.TweedeKamerInitialSince,
+				CursorOverlap: cfg.CursorOverlap,
+			},
+		},
+	)
+
+	// Amendementen and wetsvoorstellen land in motions too, so the vote step
+	// picks them up without a pipeline of its own.
+	if !settings.UseSyncFeed {
+		for _, zaak := range []struct {
+			name      string
+			kind      string
+			batchSize int
+			maxPages  int
+		}{
+			{"motions", "", settings.MotionBatchSize, settings.MotionMaxPages},
+			{"amendments", tweedekamer.ZaakSoortAmendement, settings.AmendmentBatchSize, settings.AmendmentMaxPages},
+			{"bills", tweedekamer.ZaakSoortWetsvoorstel, settings.BillBatchSize, settings.BillMaxPages},
+		} {
+			steps = append(steps, ingest.Step{
+				Name:  zaak.name,
+				Fatal: true,
+				Pipeline: ingest.TweedeKamerMotionIngest{
+					Pool:          database.Pool,
+					Client:        client,
+					Kind:          zaak.kind,
+					BatchSize:     zaak.batchSize,
+					MaxPages:      zaak.maxPages,
+					InitialSince:  cfg.TweedeKamerInitialSince,
+					CursorOverlap: cfg.CursorOverlap,
+				},
+			})
 		}
 	}
 
-	if !settings.SkipMotionDocuments {
-		fmt.Println("sync step=motion-documents")
-		job := ingest.TweedeKamerMotionDocumentsIngest{
-			Pool:        database.Pool,
-			Client:      client,
-			Documents:   officielebekendmakingen.NewClient(),
-			Limit:       settings.MotionDocumentLimit,
-			Concurrency: settings.MotionDocumentConcurrency,
-			ResyncAfter: settings.MotionDocumentResyncAfter,
-			ResyncGrace: settings.MotionDocumentResyncGrace,
-		}
-		if err := job.Run(ctx); err != nil {
-			return err
+	// The feed flags motions whose Besluiten or Stemmingen changed, so there
+	// is no need to keep re-polling unsettled ones.
+	resyncGrace := settings.MotionVoteResyncGrace
+	if settings.UseSyncFeed {
+		resyncGrace = 0
+	}
+	steps = append(steps,
+		ingest.Step{
+			Name:      "motion-votes",
+			DependsOn: zaakSteps,
+			Fatal:     true,
+			Pipeline: ingest.TweedeKamerMotionVotesIngest{
+				Pool:        database.Pool,
+				Client:      client,
+				Limit:       settings.MotionVoteLimit,
+				Concurrency: settings.MotionVoteConcurrency,
+				ResyncAfter: settings.MotionVoteResyncAfter,
+				ResyncGrace: resyncGrace,
+			},
+		},
+		ingest.Step{
+			Name:      "motion-documents",
+			DependsOn: zaakSteps,
+			Fatal:     true,
+			Pipeline: ingest.TweedeKamerMotionDocumentsIngest{
+				Pool:        database.Pool,
+				Client:      client,
+				Documents:   clients.Documents,
+				Limit:       settings.MotionDocumentLimit,
+				Concurrency: settings.MotionDocumentConcurrency,
+				ResyncAfter: settings.MotionDocumentResyncAfter,
+				ResyncGrace: settings.MotionDocumentResyncGrace,
+			},
+		},
+		ingest.Step{
+			Name:      "categorize",
+			DependsOn: zaakSteps,
+			Fatal:     true,
+			Pipeline: ingest.PipelineFunc(func(ctx context.Context) error {
+				stats, err := categorize.Run(ctx, database.Pool, categorize.Options{})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("categorize complete seen=%d matched=%d assignments=%d\n", stats.MotionsSeen, stats.MotionsMatched, stats.Assignments)
+				return nil
+			}),
+		},
+		ingest.Step{
+			Name: "data-quality",
+			// unknown-party needs the parties of this run to be stored.
+			DependsOn: append([]string{"motion-votes"}, partySteps...),
+			Pipeline: ingest.PipelineFunc(func(ctx context.Context) error {
+				stats, err := quality.Run(ctx, database.Pool, quality.Options{})
+				if err != nil {
+					return err
+				}
+				printDataQualityStats(stats)
+				return nil
+			}),
+		},
+	)
+
+	for _, step := range steps {
+		if err := registry.Register(step); err != nil {
+			return nil, err
 		}
 	}
+	return registry, nil
+}
 
-	if !settings.SkipCategorize {
-		fmt.Println("sync step=categorize")
-		stats, err := categorize.Run(ctx, database.Pool, categorize.Options{})
-		if err != nil {
-			return err
+// splitList parses a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		fmt.Printf("categorize complete seen=%d matched=%d assignments=%d\n", stats.MotionsSeen, stats.MotionsMatched, stats.Assignments)
 	}
-
-	cache.Global().Invalidate()
-	fmt.Println("sync complete source=tweedekamer")
-	return nil
+	return items
 }
 
 // runPeriodicSync keeps the data fresh from inside the serve process, since the
-// production deployment is a single container with no external cron. The first
-// run starts shortly after boot; pipeline advisory locks prevent overlap with
-// manual syncs.
+// production deployment is a single container with no external cron. Each
+// pipeline runs on its own schedule; the next planned run is stored, so a
+// restart does not reset it. A pipeline without a stored plan starts shortly
+// after boot. Pipeline advisory locks prevent overlap with manual syncs.
 func runPeriodicSync(ctx context.Context, cfg config.Config, database *db.DB) {
-	timer := time.NewTimer(time.Minute)
-	defer timer.Stop()
-
 	for {
+		wait, err := runDueSyncPipelines(ctx, cfg, database)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "periodic sync failed: %v\n", err)
+		}
+
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		case <-timer.C:
 		}
+	}
+}
 
-		fmt.Printf("periodic sync start interval=%s\n", cfg.SyncInterval)
-		if err := syncTweedeKamer(ctx, cfg, database, defaultSyncSettings(cfg)); err != nil && ctx.Err() == nil {
-			fmt.Fprintf(os.Stderr, "periodic sync failed: %v\n", err)
+// runDueSyncPipelines runs the pipelines whose planned run has come, together
+// so that they still wait for each other, and returns how long to sleep until
+// the next one is due.
+func runDueSyncPipelines(ctx context.Context, cfg config.Config, database *db.DB) (time.Duration, error) {
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return time.Minute, err
+	}
+	registry, err := tweedeKamerPipelines(cfg, database, clients, defaultSyncSettings(cfg))
+	if err != nil {
+		return time.Minute, err
+	}
+	schedules, err := pipelineSchedules(cfg, registry.Names())
+	if err != nil {
+		return time.Hour, err
+	}
+	plans, err := schedule.LoadPlans(ctx, database.Pool)
+	if err != nil {
+		return time.Minute, err
+	}
+
+	now := time.Now()
+	var due []string
+	// Wake up at least hourly, so a plan changed by another process is seen.
+	nextRunAt := now.Add(time.Hour)
+	for _, name := range registry.Names() {
+		pipelineSchedule, ok := schedules[name]
+		if !ok {
+			continue
+		}
+		plan, ok := plans[name]
+		runAt := pipelineSchedule.Fit(now.Add(time.Minute))
+		if ok {
+			runAt = pipelineSchedule.Resume(plan.NextRunAt, now)
+		}
+		if !ok || !runAt.Equal(plan.NextRunAt) {
+			if err := schedule.SaveNextRun(ctx, database.Pool, name, runAt); err != nil {
+				return time.Minute, err
+			}
 		}
+		if runAt.After(now) {
+			nextRunAt = minTime(nextRunAt, runAt)
+			continue
+		}
+		due = append(due, name)
+	}
+	if len(due) == 0 {
+		return time.Until(nextRunAt), nil
+	}
 
-		timer.Reset(cfg.SyncInterval)
+	steps, err := registry.Select(due, nil)
+	if err != nil {
+		return time.Minute, err
 	}
+	for i, step := range steps {
+		pipelineSchedule, pipeline := schedules[step.Name], step.Pipeline
+		steps[i].Pipeline = ingest.PipelineFunc(func(ctx context.Context) error {
+			startedAt := time.Now()
+			runErr := pipeline.Run(ctx)
+			if ctx.Err() != nil {
+				// Shutting down: keep the plan, so the run is retried after the
+				// restart.
+				return runErr
+			}
+			// A run that overran its interval is not followed straight away by
+			// the next one.
+			next := pipelineSchedule.Next(startedAt)
+			if finishedAt := time.Now(); !next.After(finishedAt) {
+				next = pipelineSchedule.Next(finishedAt)
+			}
+			if err := schedule.RecordRun(ctx, database.Pool, step.Name, startedAt, runErr, next); err != nil {
+				return errors.Join(runErr, err)
+			}
+			return runErr
+		})
+	}
+
+	fmt.Printf("periodic sync start pipelines=%s\n", strings.Join(due, ","))
+	err = ingest.RunSteps(ctx, steps)
+	cache.Global().Invalidate()
+	if err != nil {
+		// Steps skipped after the failure are still due and run on their own
+		// after a pause.
+		return time.Minute, err
+	}
+	return 0, nil
+}
+
+// pipelineSchedules maps every sync pipeline to its schedule: its entry in
+// SYNC_SCHEDULES, or else SYNC_INTERVAL. A pipeline with neither is not run by
+// the scheduler.
+func pipelineSchedules(cfg config.Config, names []string) (map[string]schedule.Schedule, error) {
+	for name := range cfg.SyncSchedules {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("SYNC_SCHEDULES names unknown pipeline %q, want one of %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	schedules := map[string]schedule.Schedule{}
+	for _, name := range names {
+		if pipelineSchedule, ok := cfg.SyncSchedules[name]; ok {
+			schedules[name] = pipelineSchedule
+		} else if cfg.SyncInterval > 0 {
+			schedules[name] = schedule.Every(cfg.SyncInterval, cfg.SyncTimezone)
+		}
+	}
+	return schedules, nil
 }
 
-func runStatus(ctx context.Context, database *db.DB, args []string) error {
+func minTime(first time.Time, second time.Time) time.Time {
+	if second.Before(first) {
+		return second
+	}
+	return first
+}
+
+func runStatus(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
 	if len(args) == 0 {
 		return usage()
 	}
@@ -639,23 +1403,95 @@ func runStatus(ctx context.Context, database *db.DB, args []string) error {
 		return runStatusSummary(ctx, database, args[1:])
 	case "vote-backfill":
 		return runStatusVoteBackfill(ctx, database, args[1:])
+	case "failures":
+		return runStatusFailures(ctx, database, args[1:])
+	case "schedule":
+		return runStatusSchedule(ctx, cfg, database, args[1:])
+	case "data-quality":
+		return runStatusDataQuality(ctx, database, args[1:])
 	default:
 		return usage()
 	}
 }
 
+// watchIngestionRuns redraws the progress of the running runs until the
+// context is cancelled.
+func watchIngestionRuns(ctx context.Context, database *db.DB, pipeline string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runs, err := status.LoadRunProgress(ctx, database.Pool)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now()
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("running runs at %s\n", now.Format(time.RFC3339))
+		shown := 0
+		for _, run := range runs {
+			if pipeline != "" && run.Pipeline != pipeline {
+				continue
+			}
+			shown++
+			fmt.Println(formatRunProgress(run, now))
+		}
+		if shown == 0 {
+			fmt.Println("none")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func formatRunProgress(run status.RunProgress, now time.Time) string {
+	line := fmt.Sprintf("#%d %s/%s elapsed=%s", run.RunID, run.SourceKey, run.Pipeline, now.Sub(run.StartedAt).Round(time.Second))
+	if run.UpdatedAt == nil {
+		return line + " no progress reported yet"
+	}
+	if run.Pages > 0 {
+		line += fmt.Sprintf(" pages=%d", run.Pages)
+	}
+	if run.Total > 0 {
+		line += fmt.Sprintf(" %s %d/%d %.0f%%", run.Bar(30), run.Items, run.Total, run.Fraction()*100)
+	} else {
+		line += fmt.Sprintf(" items=%d", run.Items)
+	}
+	line += fmt.Sprintf(" rate=%.1f/s", run.Rate())
+	if eta := run.ETA(); !eta.IsZero() {
+		line += fmt.Sprintf(" eta=%s", max(0, eta.Sub(now)).Round(time.Second))
+	}
+	return line
+}
+
 func runStatusIngestionRuns(ctx context.Context, database *db.DB, args []string) error {
 	flags := flag.NewFlagSet("status ingestion-runs", flag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
 	limit := flags.Int("limit", 10, "number of runs to show")
 	pipeline := flags.String("pipeline", "", "filter by pipeline")
 	failedOnly := flags.Bool("failed", false, "show failed runs only")
+	watch := flags.Bool("watch", false, "show the progress of running runs, refreshed until interrupted")
+	interval := flags.Duration("interval", 2*time.Second, "refresh interval for --watch")
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
 	if *limit <= 0 {
 		return fmt.Errorf("--limit must be greater than 0")
 	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+	if *watch {
+		return watchIngestionRuns(ctx, database, *pipeline, *interval)
+	}
 
 	rows, err := database.Pool.Query(ctx, `
 		SELECT id,
@@ -666,6 +1502,8 @@ func runStatusIngestionRuns(ctx context.Context, database *db.DB, args []string)
 		       stop_reason,
 		       records_seen,
 		       records_changed,
+		       retries,
+		       throttled,
 		       error_message,
 		       started_at,
 		       finished_at
@@ -685,11 +1523,11 @@ func runStatusIngestionRuns(ctx context.Context, database *db.DB, args []string)
 		var sourceKey, pipeline, status string
 		var cursorSaved bool
 		var stopReason *string
-		var recordsSeen, recordsChanged int
+		var recordsSeen, recordsChanged, retries, throttled int
 		var errorMessage *string
 		var startedAt time.Time
 		var finishedAt *time.Time
-		if err := rows.Scan(&id, &sourceKey, &pipeline, &status, &cursorSaved, &stopReason, &recordsSeen, &recordsChanged, &errorMessage, &startedAt, &finishedAt); err != nil {
+		if err := rows.Scan(&id, &sourceKey, &pipeline, &status, &cursorSaved, &stopReason, &recordsSeen, &recordsChanged, &retries, &throttled, &errorMessage, &startedAt, &finishedAt); err != nil {
 			return err
 		}
 
@@ -705,13 +1543,15 @@ func runStatusIngestionRuns(ctx context.Context, database *db.DB, args []string)
 		if stopReason != nil && *stopReason != "" {
 			stopText = " stop=" + *stopReason
 		}
-		fmt.Printf("#%d %s/%s %s seen=%d changed=%d cursor_saved=%t%s started=%s finished=%s%s\n",
+		fmt.Printf("#%d %s/%s %s seen=%d changed=%d retries=%d throttled=%d cursor_saved=%t%s started=%s finished=%s%s\n",
 			id,
 			sourceKey,
 			pipeline,
 			status,
 			recordsSeen,
 			recordsChanged,
+			retries,
+			throttled,
 			cursorSaved,
 			stopText,
 			startedAt.Format(time.RFC3339),
@@ -779,6 +1619,206 @@ func runStatusVoteBackfill(ctx context.Context, database *db.DB, args []string)
 	return nil
 }
 
+// runStatusSchedule lists the sync pipelines by their next planned run in the
+// built-in scheduler, as serve would pick them up now.
+func runStatusSchedule(ctx context.Context, cfg config.Config, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("status schedule", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+
+	clients, err := newSourceClients(cfg)
+	if err != nil {
+		return err
+	}
+	registry, err := tweedeKamerPipelines(cfg, database, clients, defaultSyncSettings(cfg))
+	if err != nil {
+		return err
+	}
+	schedules, err := pipelineSchedules(cfg, registry.Names())
+	if err != nil {
+		return err
+	}
+	plans, err := schedule.LoadPlans(ctx, database.Pool)
+	if err != nil {
+		return err
+	}
+
+	type upcoming struct {
+		name  string
+		runAt *time.Time
+	}
+	now := time.Now()
+	pipelines := []upcoming{}
+	for _, name := range registry.Names() {
+		pipeline := upcoming{name: name}
+		if plan, ok := plans[name]; ok {
+			if pipelineSchedule, ok := schedules[name]; ok {
+				runAt := pipelineSchedule.Resume(plan.NextRunAt, now)
+				pipeline.runAt = &runAt
+			}
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		if pipelines[i].runAt == nil || pipelines[j].runAt == nil {
+			return pipelines[j].runAt == nil && pipelines[i].runAt != nil
+		}
+		return pipelines[i].runAt.Before(*pipelines[j].runAt)
+	})
+
+	fmt.Printf("timezone=%s interval=%s\n", cfg.SyncTimezone, cfg.SyncInterval)
+	for _, pipeline := range pipelines {
+		pipelineSchedule, scheduled := schedules[pipeline.name]
+		if !scheduled {
+			fmt.Printf("%s schedule=none\n", pipeline.name)
+			continue
+		}
+		next := "next=on-serve-start"
+		if pipeline.runAt != nil {
+			next = fmt.Sprintf("next=%s in=%s", pipeline.runAt.In(cfg.SyncTimezone).Format(time.RFC3339), max(0, pipeline.runAt.Sub(now)).Round(time.Minute))
+		}
+		last := ""
+		if plan, ok := plans[pipeline.name]; ok && plan.LastStartedAt != nil {
+			last = " last_started=" + plan.LastStartedAt.In(cfg.SyncTimezone).Format(time.RFC3339)
+			if plan.LastError != nil {
+				last += " last_error=" + *plan.LastError
+			}
+		}
+		fmt.Printf("%s schedule=%q %s%s\n", pipeline.name, pipelineSchedule.String(), next, last)
+	}
+	return nil
+}
+
+// runStatusDataQuality lists the open findings per rule and the findings
+// themselves; maintenance data-quality and the sync refresh them.
+func runStatusDataQuality(ctx context.Context, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("status data-quality", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	limit := flags.Int("limit", 20, "number of findings to show")
+	rule := flags.String("rule", "", "filter by rule, e.g. seat-total")
+	severityValue := flags.String("severity", "", "filter by severity: error, warning or info")
+	motionKey := flags.String("motion", "", "filter by motion key")
+	resolved := flags.Bool("resolved", false, "list resolved findings instead of open ones")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("--limit must be greater than 0")
+	}
+	var severity quality.Severity
+	if *severityValue != "" {
+		parsed, err := quality.ParseSeverity(*severityValue)
+		if err != nil {
+			return err
+		}
+		severity = parsed
+	}
+
+	summaries, err := quality.LoadRuleSummaries(ctx, database.Pool)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		counts := []string{}
+		for _, severity := range quality.Severities {
+			if count := summary.Open[string(severity)]; count > 0 {
+				counts = append(counts, fmt.Sprintf("%s=%d", severity, count))
+			}
+		}
+		fmt.Printf("rule=%s open=%d %s last_found=%s\n", summary.Rule, summary.Total(), strings.Join(counts, " "), formatOptionalTime(summary.LastFoundAt))
+	}
+
+	findings, err := quality.LoadFindings(ctx, database.Pool, quality.FindingFilter{
+		Rule:      *rule,
+		Severity:  severity,
+		MotionKey: *motionKey,
+		Resolved:  *resolved,
+		Limit:     *limit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		fmt.Println()
+	}
+	for _, finding := range findings {
+		fmt.Printf("%s %s %s motion=%s first_seen=%s %s\n",
+			finding.Severity,
+			finding.Rule,
+			finding.SubjectKey,
+			finding.MotionKey,
+			finding.FirstSeenAt.Format(time.RFC3339),
+			finding.Detail,
+		)
+	}
+	return nil
+}
+
+func runStatusFailures(ctx context.Context, database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("status failures", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	limit := flags.Int("limit", 20, "number of failures to show")
+	pipeline := flags.String("pipeline", "", "filter by pipeline, e.g. motion_votes.raw")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return usage()
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("--limit must be greater than 0")
+	}
+
+	counts, err := status.CountIngestionFailures(ctx, database.Pool)
+	if err != nil {
+		return err
+	}
+	failures, err := status.LoadIngestionFailures(ctx, database.Pool, *pipeline, *limit)
+	if err != nil {
+		return err
+	}
+
+	pipelines := make([]string, 0, len(counts))
+	for name := range counts {
+		pipelines = append(pipelines, name)
+	}
+	sort.Strings(pipelines)
+	total := 0
+	parts := []string{}
+	for _, name := range pipelines {
+		total += counts[name]
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	fmt.Printf("failures=%d %s\n", total, strings.Join(parts, " "))
+
+	now := time.Now()
+	for _, failure := range failures {
+		due := "due"
+		if failure.NextRetryAt.After(now) {
+			due = "retry_at=" + failure.NextRetryAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%s/%s %s attempts=%d first_failed=%s last_failed=%s %s error=%s\n",
+			failure.SourceKey,
+			failure.Pipeline,
+			failure.ItemKey,
+			failure.Attempts,
+			failure.FirstFailedAt.Format(time.RFC3339),
+			failure.LastFailedAt.Format(time.RFC3339),
+			due,
+			failure.LastError,
+		)
+	}
+	return nil
+}
+
 func formatOptionalTime(value *time.Time) string {
 	if value == nil {
 		return ""
@@ -791,15 +1831,28 @@ func usage() error {
   partijgedrag migrate
   partijgedrag ingest tweedekamer parties [--max-pages=N] [--batch-size=N] [--since=RFC3339] [--reset-cursor]
   partijgedrag ingest tweedekamer party-logos [--batch-size=N] [--concurrency=N] [--resync-after=720h]
+  partijgedrag ingest tweedekamer members [--max-pages=N] [--batch-size=N] [--since=RFC3339] [--reset-cursor]
   partijgedrag ingest tweedekamer motions [--max-pages=N] [--batch-size=N] [--since=RFC3339] [--reset-cursor]
+  partijgedrag ingest tweedekamer amendments [--max-pages=N] [--batch-size=N] [--since=RFC3339] [--reset-cursor]
+  partijgedrag ingest tweedekamer bills [--max-pages=N] [--batch-size=N] [--since=RFC3339] [--reset-cursor]
   partijgedrag ingest tweedekamer motion-votes [--limit=N] [--concurrency=N] [--resync-after=168h]
   partijgedrag ingest tweedekamer motion-documents [--limit=N] [--concurrency=N] [--resync-after=168h]
-  partijgedrag sync tweedekamer [--party-max-pages=N] [--party-batch-size=N] [--party-logo-concurrency=N] [--motion-max-pages=N] [--motion-batch-size=N] [--motion-vote-limit=N] [--motion-vote-concurrency=N] [--motion-vote-resync-after=168h] [--motion-document-limit=N] [--motion-document-concurrency=N] [--motion-document-resync-after=168h] [--skip-parties] [--skip-motions] [--skip-motion-votes] [--skip-motion-documents] [--skip-categorize]
+  partijgedrag ingest tweedekamer syncfeed [--category=Zaak,Besluit] [--max-pages=N] [--skiptoken=N] [--reset-cursor]
+  partijgedrag sync tweedekamer [--party-max-pages=N] [--party-batch-size=N] [--party-logo-concurrency=N] [--member-max-pages=N] [--member-batch-size=N] [--motion-max-pages=N] [--motion-batch-size=N] [--amendment-max-pages=N] [--amendment-batch-size=N] [--bill-max-pages=N] [--bill-batch-size=N] [--motion-vote-limit=N] [--motion-vote-concurrency=N] [--motion-vote-resync-after=168h] [--motion-document-limit=N] [--motion-document-concurrency=N] [--motion-document-resync-after=168h] [--syncfeed] [--syncfeed-max-pages=N] [--transport=live|record|replay] [--fixture-dir=DIR] [--only=PIPELINE,...] [--skip=PIPELINE,...]
   partijgedrag maintenance fail-stale-runs [--older-than=1h] [--limit=N] [--apply]
   partijgedrag maintenance categorize [--batch-size=N] [--max-motions=N] [--recategorize]
-  partijgedrag status ingestion-runs [--limit=N] [--pipeline=NAME] [--failed]
+  partijgedrag maintenance reproject [--collection=Zaak] [--batch-size=N] [--limit=N] [--apply]
+  partijgedrag maintenance retry-failures [--pipeline=motion-votes|motion-documents] [--limit=N] [--concurrency=N]
+  partijgedrag maintenance data-quality [--rule=seat-total,...] [--stale-after=336h]
+  partijgedrag maintenance ideal-points [--period=KEY,...] [--scope=party,member] [--kind=KIND] [--min-votes=N]
+  partijgedrag maintenance reconcile tweedekamer [--collection=Zaak,Besluit] [--since=YYYY-MM-DD] [--until=YYYY-MM-DD] [--full] [--limit=N] [--apply]
+  partijgedrag status ingestion-runs [--limit=N] [--pipeline=NAME] [--failed] [--watch] [--interval=2s]
   partijgedrag status summary
   partijgedrag status vote-backfill [--resync-after=168h]
+  partijgedrag status failures [--limit=N] [--pipeline=NAME]
+  partijgedrag status schedule
+  partijgedrag status data-quality [--rule=NAME] [--severity=error|warning|info] [--motion=MOTION_KEY] [--resolved] [--limit=N]
   partijgedrag inspect motion MOTION_KEY
+  partijgedrag inspect history MOTION_KEY [--all]
   partijgedrag serve`)
 }
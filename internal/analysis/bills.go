@@ -0,0 +1,363 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+type BillListOptions struct {
+	Jurisdiction string
+	Search       string
+	Limit        int
+	Offset       int
+}
+
+// BillSummary is a wetsvoorstel with the outcome of its final vote. The
+// amendementen and moties counted here share its Kamerstukdossier.
+type BillSummary struct {
+	BillKey         string
+	Number          *string
+	Title           *string
+	Subject         *string
+	ProposedAt      *time.Time
+	DossierNumber   *string
+	DossierAddition *string
+	FinalDecision   *string
+	Amendments      int
+	Motions         int
+}
+
+// Bill is the dossier view of a wetsvoorstel: how every party voted on the bill
+// itself and on each amendement and motie filed in the same dossier.
+type Bill struct {
+	BillSummary
+	Parties []BillParty
+	// Items starts with the bill itself, followed by amendementen and then
+	// moties, each oldest first.
+	Items []BillItem
+}
+
+type BillParty struct {
+	PartySourceID string
+	PartyName     string
+}
+
+type BillItem struct {
+	MotionKey  string
+	Kind       string
+	Number     *string
+	Subject    *string
+	ProposedAt *time.Time
+	Decision   *string
+	// Positions is keyed by party source id. Parties that did not vote on the
+	// item are missing.
+	Positions map[string]politics.Position
+}
+
+// billVoteRow is one party's Voor/Tegen tally on one item of the dossier.
+type billVoteRow struct {
+	MotionKey     string
+	PartySourceID string
+	PartyName     string
+	VotesFor      int
+	VotesAgainst  int
+}
+
+func LoadBills(ctx context.Context, pool *pgxpool.Pool, options BillListOptions) ([]BillSummary, int, error) {
+	jurisdiction := options.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := options.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	search := strings.TrimSpace(options.Search)
+
+	cacheKey := fmt.Sprintf("analysis:bills:%s:%s:%d:%d", jurisdiction, search, limit, offset)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		page := cached.(billPage)
+		return copyBillSummaries(page.Bills), page.Total, nil
+	}
+
+	rows, err := pool.Query(ctx, billSummarySQL+`
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND m.kind = $2
+		  AND (
+		    $3::text = ''
+		    OR m.title ILIKE '%' || $3 || '%'
+		    OR m.subject ILIKE '%' || $3 || '%'
+		    OR m.dossier_number = $3
+		  )
+		ORDER BY m.proposed_at DESC NULLS LAST, m.motion_key
+		LIMIT $4 OFFSET $5
+	`, jurisdiction, MotionKindBill, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	bills := []BillSummary{}
+	total := 0
+	for rows.Next() {
+		var bill BillSummary
+		if err := scanBillSummary(rows.Scan, &bill, &total); err != nil {
+			return nil, 0, err
+		}
+		bills = append(bills, bill)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	cache.Global().Set(cacheKey, billPage{Bills: copyBillSummaries(bills), Total: total})
+	return bills, total, nil
+}
+
+// LoadBill returns the dossier view of one wetsvoorstel. It reports
+// pgx.ErrNoRows, see IsNotFound, when billKey is not a wetsvoorstel.
+func LoadBill(ctx context.Context, pool *pgxpool.Pool, billKey string) (Bill, error) {
+	cacheKey := "analysis:bill:" + billKey
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyBill(cached.(Bill)), nil
+	}
+
+	bill := Bill{}
+	var total int
+	err := scanBillSummary(pool.QueryRow(ctx, billSummarySQL+`
+		WHERE m.motion_key = $1
+		  AND m.kind = $2
+	`, billKey, MotionKindBill).Scan, &bill.BillSummary, &total)
+	if err != nil {
+		return Bill{}, err
+	}
+
+	items, err := loadBillItems(ctx, pool, bill.BillSummary)
+	if err != nil {
+		return Bill{}, err
+	}
+
+	// The bill's own row only counts its final vote, and stays empty until
+	// there is one: earlier Besluiten on a wetsvoorstel are procedural
+	// (uitstel, aanhouden) and would blur it. Amendementen and moties count
+	// all their votes.
+	rows, err := pool.Query(ctx, `
+		SELECT v.motion_key,
+		       v.party_source_id,
+		       COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id) AS party_name,
+		       COUNT(*) FILTER (WHERE v.vote_type = 'Voor')::int AS votes_for,
+		       COUNT(*) FILTER (WHERE v.vote_type = 'Tegen')::int AS votes_against
+		FROM votes v
+		LEFT JOIN parties p ON p.source_key = v.source_key
+		                   AND p.source_id = v.party_source_id
+		LEFT JOIN bills b ON b.bill_key = v.motion_key
+		WHERE v.motion_key = ANY($1::text[])
+		  AND v.source_deleted = false
+		  AND v.mistake = false
+		  AND v.party_source_id IS NOT NULL
+		  AND v.vote_type IN ('Voor', 'Tegen')
+		  AND (v.motion_key <> $2 OR v.decision_key = b.final_decision_key)
+		GROUP BY v.motion_key,
+		         v.party_source_id,
+		         COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id)
+	`, billItemKeys(items), bill.BillKey)
+	if err != nil {
+		return Bill{}, err
+	}
+	defer rows.Close()
+
+	votes := []billVoteRow{}
+	for rows.Next() {
+		var row billVoteRow
+		if err := rows.Scan(&row.MotionKey, &row.PartySourceID, &row.PartyName, &row.VotesFor, &row.VotesAgainst); err != nil {
+			return Bill{}, err
+		}
+		votes = append(votes, row)
+	}
+	if err := rows.Err(); err != nil {
+		return Bill{}, err
+	}
+
+	bill.Parties, bill.Items = tallyBillPositions(items, votes)
+
+	cache.Global().Set(cacheKey, copyBill(bill))
+	return bill, nil
+}
+
+// loadBillItems lists the bill followed by the amendementen and moties of its
+// dossier. A bill without a dossier only lists itself.
+func loadBillItems(ctx context.Context, pool *pgxpool.Pool, bill BillSummary) ([]BillItem, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT m.motion_key,
+		       m.kind,
+		       m.number,
+		       m.subject,
+		       m.proposed_at,
+		       d.decision_type
+		FROM motions m
+		LEFT JOIN LATERAL (
+			SELECT decision_type
+			FROM decisions
+			WHERE motion_key = m.motion_key
+			  AND source_deleted = false
+			  AND decision_type LIKE 'Stemmen - %'
+			ORDER BY source_updated_at DESC NULLS LAST, decision_order DESC NULLS LAST
+			LIMIT 1
+		) d ON true
+		WHERE m.source_deleted = false
+		  AND (
+		    m.motion_key = $1
+		    OR (
+		      $2::text IS NOT NULL
+		      AND m.dossier_number = $2
+		      AND m.dossier_addition IS NOT DISTINCT FROM $3
+		      AND m.kind IN ($4, $5)
+		    )
+		  )
+		ORDER BY m.motion_key <> $1,
+		         m.kind = $5,
+		         m.proposed_at NULLS LAST,
+		         m.number
+	`, bill.BillKey, bill.DossierNumber, bill.DossierAddition, MotionKindAmendment, MotionKindMotion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []BillItem{}
+	for rows.Next() {
+		var item BillItem
+		if err := rows.Scan(&item.MotionKey, &item.Kind, &item.Number, &item.Subject, &item.ProposedAt, &item.Decision); err != nil {
+			return nil, err
+		}
+		if item.MotionKey == bill.BillKey {
+			item.Decision = bill.FinalDecision
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// tallyBillPositions fills in each item's party positions and returns the
+// parties that voted on anything in the dossier, ordered by name. A tied party
+// is recorded as neutral rather than left out, so the table shows it voted.
+func tallyBillPositions(items []BillItem, votes []billVoteRow) ([]BillParty, []BillItem) {
+	byMotion := map[string]map[string]politics.Position{}
+	names := map[string]string{}
+	for _, vote := range votes {
+		positions, ok := byMotion[vote.MotionKey]
+		if !ok {
+			positions = map[string]politics.Position{}
+			byMotion[vote.MotionKey] = positions
+		}
+		positions[vote.PartySourceID] = politics.PartyPosition(vote.VotesFor, vote.VotesAgainst)
+		names[vote.PartySourceID] = vote.PartyName
+	}
+
+	out := make([]BillItem, len(items))
+	for index, item := range items {
+		item.Positions = byMotion[item.MotionKey]
+		if item.Positions == nil {
+			item.Positions = map[string]politics.Position{}
+		}
+		out[index] = item
+	}
+
+	parties := make([]BillParty, 0, len(names))
+	for sourceID, name := range names {
+		parties = append(parties, BillParty{PartySourceID: sourceID, PartyName: name})
+	}
+	sort.Slice(parties, func(i, j int) bool {
+		return strings.ToLower(parties[i].PartyName) < strings.ToLower(parties[j].PartyName)
+	})
+	return parties, out
+}
+
+const billSummarySQL = `
+	SELECT m.motion_key,
+	       m.number,
+	       m.title,
+	       m.subject,
+	       m.proposed_at,
+	       m.dossier_number,
+	       m.dossier_addition,
+	       b.final_decision_type,
+	       (SELECT COUNT(*)::int FROM motions a
+	         WHERE a.dossier_number = m.dossier_number
+	           AND a.dossier_addition IS NOT DISTINCT FROM m.dossier_addition
+	           AND a.kind = 'Amendement'
+	           AND a.source_deleted = false) AS amendments,
+	       (SELECT COUNT(*)::int FROM motions a
+	         WHERE a.dossier_number = m.dossier_number
+	           AND a.dossier_addition IS NOT DISTINCT FROM m.dossier_addition
+	           AND a.kind = 'Motie'
+	           AND a.source_deleted = false) AS motions,
+	       COUNT(*) OVER ()::int AS total
+	FROM motions m
+	LEFT JOIN bills b ON b.bill_key = m.motion_key
+`
+
+func scanBillSummary(scan func(...any) error, bill *BillSummary, total *int) error {
+	return scan(
+		&bill.BillKey,
+		&bill.Number,
+		&bill.Title,
+		&bill.Subject,
+		&bill.ProposedAt,
+		&bill.DossierNumber,
+		&bill.DossierAddition,
+		&bill.FinalDecision,
+		&bill.Amendments,
+		&bill.Motions,
+		total,
+	)
+}
+
+func billItemKeys(items []BillItem) []string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.MotionKey)
+	}
+	return keys
+}
+
+type billPage struct {
+	Bills []BillSummary
+	Total int
+}
+
+func copyBillSummaries(src []BillSummary) []BillSummary {
+	if src == nil {
+		return nil
+	}
+	dst := make([]BillSummary, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func copyBill(src Bill) Bill {
+	out := Bill{
+		BillSummary: src.BillSummary,
+		Parties:     make([]BillParty, len(src.Parties)),
+		Items:       make([]BillItem, len(src.Items)),
+	}
+	copy(out.Parties, src.Parties)
+	copy(out.Items, src.Items)
+	return out
+}
@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"testing"
+
+	"partijgedrag/internal/politics"
+)
+
+func TestTallyBillPositions(t *testing.T) {
+	items := []BillItem{
+		{MotionKey: "bill", Kind: MotionKindBill},
+		{MotionKey: "amendment", Kind: MotionKindAmendment},
+		{MotionKey: "motion", Kind: MotionKindMotion},
+	}
+	parties, out := tallyBillPositions(items, []billVoteRow{
+		{MotionKey: "bill", PartySourceID: "vvd", PartyName: "VVD", VotesFor: 1},
+		{MotionKey: "bill", PartySourceID: "sp", PartyName: "SP", VotesAgainst: 1},
+		{MotionKey: "amendment", PartySourceID: "sp", PartyName: "SP", VotesFor: 1},
+		// A hoofdelijke stemming split down the middle.
+		{MotionKey: "amendment", PartySourceID: "d66", PartyName: "D66", VotesFor: 4, VotesAgainst: 4},
+	})
+
+	if len(parties) != 3 || parties[0].PartyName != "D66" || parties[1].PartyName != "SP" || parties[2].PartyName != "VVD" {
+		t.Fatalf("unexpected parties %+v", parties)
+	}
+	if len(out) != 3 {
+		t.Fatalf("len(items) = %d, want 3", len(out))
+	}
+	if got := out[0].Positions["vvd"]; got != politics.PositionFor {
+		t.Fatalf("bill vvd = %q, want FOR", got)
+	}
+	if got := out[0].Positions["sp"]; got != politics.PositionAgainst {
+		t.Fatalf("bill sp = %q, want AGAINST", got)
+	}
+	if got := out[1].Positions["d66"]; got != politics.PositionNeutral {
+		t.Fatalf("amendment d66 = %q, want NEUTRAL", got)
+	}
+	if _, ok := out[1].Positions["vvd"]; ok {
+		t.Fatal("amendment vvd should be missing")
+	}
+	if out[2].Positions == nil || len(out[2].Positions) != 0 {
+		t.Fatalf("motion without votes = %+v, want empty map", out[2].Positions)
+	}
+	if items[0].Positions != nil {
+		t.Fatal("input items were modified")
+	}
+}
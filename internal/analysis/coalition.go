@@ -37,6 +37,7 @@ type CoalitionAnalysis struct {
 type CoalitionAnalysisOptions struct {
 	Period    CabinetPeriod
 	MinCommon int
+	Kind      string
 }
 
 type CoalitionMotion struct {
@@ -58,6 +59,7 @@ type CoalitionMotionOptions struct {
 	Period        CabinetPeriod
 	PartySourceID string
 	Relation      string
+	Kind          string
 	Limit         int
 	Offset        int
 }
@@ -67,8 +69,12 @@ func LoadCoalitionAnalysis(ctx context.Context, pool *pgxpool.Pool, options Coal
 	if minCommon <= 0 {
 		minCommon = 5
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return CoalitionAnalysis{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
 
-	cacheKey := fmt.Sprintf("analysis:coalition_analysis:%s:%d", options.Period.PeriodKey, minCommon)
+	cacheKey := fmt.Sprintf("analysis:coalition_analysis:%s:%d:%s", options.Period.PeriodKey, minCommon, kind)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		return cached.(CoalitionAnalysis), nil
 	}
@@ -76,13 +82,13 @@ func LoadCoalitionAnalysis(ctx context.Context, pool *pgxpool.Pool, options Coal
 	coalitionParties := normalizedPartyNames(options.Period.Parties)
 	analysis := CoalitionAnalysis{}
 
-	summary, err := loadCoalitionSummary(ctx, pool, options.Period, coalitionParties)
+	summary, err := loadCoalitionSummary(ctx, pool, options.Period, coalitionParties, kind)
 	if err != nil {
 		return CoalitionAnalysis{}, err
 	}
 	analysis.Summary = summary
 
-	parties, err := loadCoalitionPartyAlignment(ctx, pool, options.Period, coalitionParties, minCommon)
+	parties, err := loadCoalitionPartyAlignment(ctx, pool, options.Period, coalitionParties, kind, minCommon)
 	if err != nil {
 		return CoalitionAnalysis{}, err
 	}
@@ -109,8 +115,12 @@ func LoadCoalitionMotions(ctx context.Context, pool *pgxpool.Pool, options Coali
 	if !ok {
 		return nil, fmt.Errorf("invalid relation %q", options.Relation)
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid kind %q", options.Kind)
+	}
 
-	cacheKey := fmt.Sprintf("analysis:coalition_motions:%s:%s:%s:%d:%d", options.Period.PeriodKey, options.PartySourceID, relation, limit, offset)
+	cacheKey := fmt.Sprintf("analysis:coalition_motions:%s:%s:%s:%s:%d:%d", options.Period.PeriodKey, options.PartySourceID, relation, kind, limit, offset)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		return copyCoalitionMotions(cached.([]CoalitionMotion)), nil
 	}
@@ -139,18 +149,18 @@ func LoadCoalitionMotions(ctx context.Context, pool *pgxpool.Pool, options Coali
 		JOIN motions m ON m.motion_key = pp.motion_key
 		WHERE cbm.coalition_for <> cbm.coalition_against
 		  AND cbm.coalition_parties_seen >= 2
-		  AND pp.party_source_id = $5
+		  AND pp.party_source_id = $6
 		  AND (
-		    $6::text = 'all'
+		    $7::text = 'all'
 		    OR (
-		      $6::text = 'with'
+		      $7::text = 'with'
 		      AND (
 		        (cbm.coalition_for > cbm.coalition_against AND pp.position = 'FOR')
 		        OR (cbm.coalition_against > cbm.coalition_for AND pp.position = 'AGAINST')
 		      )
 		    )
 		    OR (
-		      $6::text = 'against'
+		      $7::text = 'against'
 		      AND (
 		        (cbm.coalition_for > cbm.coalition_against AND pp.position = 'AGAINST')
 		        OR (cbm.coalition_against > cbm.coalition_for AND pp.position = 'FOR')
@@ -158,8 +168,8 @@ func LoadCoalitionMotions(ctx context.Context, pool *pgxpool.Pool, options Coali
 		    )
 		  )
 		ORDER BY m.proposed_at DESC NULLS LAST, m.motion_key
-		LIMIT $7 OFFSET $8
-	`, options.Period.Jurisdiction, options.Period.StartedOn, options.Period.EndedOn, normalizedPartyNames(options.Period.Parties), options.PartySourceID, relation, limit, offset)
+		LIMIT $8 OFFSET $9
+	`, options.Period.Jurisdiction, options.Period.StartedOn, options.Period.EndedOn, normalizedPartyNames(options.Period.Parties), kind, options.PartySourceID, relation, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +213,7 @@ func copyCoalitionMotions(src []CoalitionMotion) []CoalitionMotion {
 	return dst
 }
 
-func loadCoalitionSummary(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod, coalitionParties []string) (CoalitionSummary, error) {
+func loadCoalitionSummary(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod, coalitionParties []string, kind string) (CoalitionSummary, error) {
 	var summary CoalitionSummary
 	err := pool.QueryRow(ctx, coalitionPositionSQL()+`
 		SELECT COUNT(*)::int AS motions_with_coalition_votes,
@@ -213,7 +223,7 @@ func loadCoalitionSummary(ctx context.Context, pool *pgxpool.Pool, period Cabine
 		       COUNT(*) FILTER (WHERE coalition_for > 0 AND coalition_against > 0)::int AS split
 		FROM coalition_by_motion
 		WHERE coalition_parties_seen >= 2
-	`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties).Scan(
+	`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties, kind).Scan(
 		&summary.MotionsWithCoalitionVotes,
 		&summary.ClearBlocPosition,
 		&summary.UnanimousFor,
@@ -223,7 +233,7 @@ func loadCoalitionSummary(ctx context.Context, pool *pgxpool.Pool, period Cabine
 	return summary, err
 }
 
-func loadCoalitionPartyAlignment(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod, coalitionParties []string, minCommon int) ([]CoalitionPartyAlignment, error) {
+func loadCoalitionPartyAlignment(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod, coalitionParties []string, kind string, minCommon int) ([]CoalitionPartyAlignment, error) {
 	rows, err := pool.Query(ctx, coalitionPositionSQL()+`
 		SELECT pp.party_source_id,
 		       pp.party_name,
@@ -248,9 +258,9 @@ func loadCoalitionPartyAlignment(ctx context.Context, pool *pgxpool.Pool, period
 		WHERE cbm.coalition_for <> cbm.coalition_against
 		  AND cbm.coalition_parties_seen >= 2
 		GROUP BY pp.party_source_id, pp.party_name
-		HAVING COUNT(*) >= $5
+		HAVING COUNT(*) >= $6
 		ORDER BY coalition_party DESC, alignment DESC, common_motions DESC, pp.party_name
-	`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties, minCommon)
+	`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties, kind, minCommon)
 	if err != nil {
 		return nil, err
 	}
@@ -275,32 +285,25 @@ func loadCoalitionPartyAlignment(ctx context.Context, pool *pgxpool.Pool, period
 	return parties, rows.Err()
 }
 
+// coalitionPositionSQL binds $1 jurisdiction, $2/$3 the period, $4 the
+// normalized coalition party names and $5 the motion kind.
 func coalitionPositionSQL() string {
 	return `
 		WITH party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown') AS party_name,
-			       CASE
-			         WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) THEN 'FOR'
-			         WHEN SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) THEN 'AGAINST'
-			         ELSE 'NEUTRAL'
-			       END AS position
-			FROM votes v
-			JOIN motions m ON m.motion_key = v.motion_key
-			LEFT JOIN parties p ON p.source_key = v.source_key
-			                   AND p.source_id = v.party_source_id
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       COALESCE(p.short_name, pp.party_name, pp.party_source_id) AS party_name,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN motions m ON m.motion_key = pp.motion_key
+			LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+			                   AND p.source_id = pp.party_source_id
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
 			  AND m.proposed_at >= $2
 			  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
-			  AND v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.vote_type IN ('Voor', 'Tegen')
-			GROUP BY v.motion_key,
-			         v.party_source_id,
-			         COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown')
-			HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+			  AND ($5::text = 'all' OR m.kind = $5)
+			  AND pp.position <> 'NEUTRAL'
 		),
 		coalition_by_motion AS (
 			SELECT motion_key,
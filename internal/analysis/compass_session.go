@@ -254,24 +254,15 @@ func loadCompassMotionPositions(ctx context.Context, pool *pgxpool.Pool, jurisdi
 			FROM unnest($2::text[]) WITH ORDINALITY AS request(motion_key, ordinality)
 		),
 		party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown') AS party_name,
-			       CASE
-			         WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) THEN 'FOR'
-			         ELSE 'AGAINST'
-			       END AS position
-			FROM votes v
-			JOIN requested r ON r.motion_key = v.motion_key
-			LEFT JOIN parties p ON p.source_key = v.source_key
-			                   AND p.source_id = v.party_source_id
-			WHERE v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.vote_type IN ('Voor', 'Tegen')
-			GROUP BY v.motion_key,
-			         v.party_source_id,
-			         COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown')
-			HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       COALESCE(p.short_name, pp.party_name, pp.party_source_id) AS party_name,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN requested r ON r.motion_key = pp.motion_key
+			LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+			                   AND p.source_id = pp.party_source_id
+			WHERE pp.position <> 'NEUTRAL'
 		)
 		SELECT m.motion_key,
 		       m.number,
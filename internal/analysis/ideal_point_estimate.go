@@ -0,0 +1,442 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// The ideal-point model is one-dimensional optimal classification (Poole,
+// 2000): every fractie or Kamerlid gets a position on a line and every motion
+// a cutting point, with Voor on one side and Tegen on the other. The fit
+// alternates between the cutting points that misclassify the fewest votes
+// and the positions that do the same, starting from the first principal
+// component. Only the order of the positions is identified, so they are
+// spread evenly over -1 to 1 by rank.
+
+const (
+	IdealPointScopeParty  = "party"
+	IdealPointScopeMember = "member"
+
+	// crossCuttingPRE is the proportional reduction in error below which a
+	// motion does not fit the left-right line.
+	crossCuttingPRE = 0.5
+	// idealPointIterations bounds the alternating fit; it usually settles
+	// within a handful of rounds.
+	idealPointIterations = 25
+	// idealPointEdge is how far beyond the outermost position a cutting
+	// point is put when everybody is predicted to vote the same.
+	idealPointEdge = 0.05
+)
+
+func NormalizeIdealPointScope(value string) (string, bool) {
+	switch value {
+	case "", IdealPointScopeParty:
+		return IdealPointScopeParty, true
+	case IdealPointScopeMember:
+		return IdealPointScopeMember, true
+	default:
+		return "", false
+	}
+}
+
+// idealPointVote is a Voor (true) or Tegen (false) by a fractie or Kamerlid
+// on a motion.
+type idealPointVote struct {
+	MotionKey string
+	SubjectID string
+	Voor      bool
+}
+
+// idealPointFit is the result of estimateIdealPoints, keyed like its input.
+type idealPointFit struct {
+	Subjects []idealPointSubjectFit
+	Motions  []idealPointMotionFit
+	Votes    int
+	Errors   int
+	// Classified is the share of votes on the side of the cutting point the
+	// model predicts.
+	Classified float64
+	// APRE is the aggregate proportional reduction in error: how many of the
+	// minority votes the model explains, over all motions.
+	APRE float64
+}
+
+type idealPointSubjectFit struct {
+	SubjectID  string
+	Coordinate float64
+	Votes      int
+	Errors     int
+}
+
+type idealPointMotionFit struct {
+	MotionKey    string
+	CuttingPoint float64
+	VoorAbove    bool
+	Voters       int
+	Minority     int
+	Errors       int
+	PRE          float64
+	CrossCutting bool
+}
+
+type idealPointBallot struct {
+	subject int
+	voor    bool
+}
+
+type idealPointCut struct {
+	point     float64
+	voorAbove bool
+}
+
+// estimateIdealPoints scales the votes of subjects with at least minVotes
+// votes on contested motions. Unanimous motions carry no information about
+// positions and are left out; fewer than three subjects give no fit.
+func estimateIdealPoints(votes []idealPointVote, minVotes int) idealPointFit {
+	fit := idealPointFit{Subjects: []idealPointSubjectFit{}, Motions: []idealPointMotionFit{}}
+
+	subjects, motionKeys, ballots := idealPointMatrix(votes, minVotes)
+	if len(subjects) < 3 || len(motionKeys) == 0 {
+		return fit
+	}
+
+	positions := rankPositions(firstComponent(len(subjects), ballots))
+	best := positions
+	bestErrors := -1
+	for iteration := 0; iteration < idealPointIterations; iteration++ {
+		cuts, errors := fitCuttingPoints(positions, ballots)
+		if bestErrors >= 0 && errors >= bestErrors {
+			break
+		}
+		best, bestErrors = positions, errors
+		positions = rankPositions(placeSubjects(positions, cuts, ballots))
+	}
+
+	cuts, _ := fitCuttingPoints(best, ballots)
+	subjectFits := make([]idealPointSubjectFit, len(subjects))
+	for index, subjectID := range subjects {
+		subjectFits[index] = idealPointSubjectFit{SubjectID: subjectID, Coordinate: best[index]}
+	}
+	minorities := 0
+	for motion, motionKey := range motionKeys {
+		cut := cuts[motion]
+		motionFit := idealPointMotionFit{
+			MotionKey:    motionKey,
+			CuttingPoint: cut.point,
+			VoorAbove:    cut.voorAbove,
+			Voters:       len(ballots[motion]),
+		}
+		voor := 0
+		for _, ballot := range ballots[motion] {
+			if ballot.voor {
+				voor++
+			}
+			subjectFits[ballot.subject].Votes++
+			if predictVoor(best[ballot.subject], cut) != ballot.voor {
+				motionFit.Errors++
+				subjectFits[ballot.subject].Errors++
+			}
+		}
+		motionFit.Minority = min(voor, motionFit.Voters-voor)
+		motionFit.PRE = float64(motionFit.Minority-motionFit.Errors) / float64(motionFit.Minority)
+		motionFit.CrossCutting = motionFit.PRE < crossCuttingPRE
+
+		fit.Votes += motionFit.Voters
+		fit.Errors += motionFit.Errors
+		minorities += motionFit.Minority
+		fit.Motions = append(fit.Motions, motionFit)
+	}
+	fit.Subjects = subjectFits
+	fit.Classified = 1 - float64(fit.Errors)/float64(fit.Votes)
+	fit.APRE = float64(minorities-fit.Errors) / float64(minorities)
+	return fit
+}
+
+// idealPointMatrix keeps the subjects with enough votes and the motions they
+// did not vote on unanimously. Dropping one can make the other fall below
+// the bar, so it repeats until nothing changes.
+func idealPointMatrix(votes []idealPointVote, minVotes int) ([]string, []string, [][]idealPointBallot) {
+	subjectKept := map[string]bool{}
+	motionKept := map[string]bool{}
+	for _, vote := range votes {
+		subjectKept[vote.SubjectID] = true
+		motionKept[vote.MotionKey] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		subjectVotes := map[string]int{}
+		motionVoor := map[string]int{}
+		motionTegen := map[string]int{}
+		for _, vote := range votes {
+			if !subjectKept[vote.SubjectID] || !motionKept[vote.MotionKey] {
+				continue
+			}
+			subjectVotes[vote.SubjectID]++
+			if vote.Voor {
+				motionVoor[vote.MotionKey]++
+			} else {
+				motionTegen[vote.MotionKey]++
+			}
+		}
+		for motionKey, kept := range motionKept {
+			if kept && (motionVoor[motionKey] == 0 || motionTegen[motionKey] == 0) {
+				motionKept[motionKey] = false
+				changed = true
+			}
+		}
+		for subjectID, kept := range subjectKept {
+			if kept && subjectVotes[subjectID] < minVotes {
+				subjectKept[subjectID] = false
+				changed = true
+			}
+		}
+	}
+
+	subjects := []string{}
+	for subjectID, kept := range subjectKept {
+		if kept {
+			subjects = append(subjects, subjectID)
+		}
+	}
+	sort.Strings(subjects)
+	subjectIndex := map[string]int{}
+	for index, subjectID := range subjects {
+		subjectIndex[subjectID] = index
+	}
+
+	motionKeys := []string{}
+	for motionKey, kept := range motionKept {
+		if kept {
+			motionKeys = append(motionKeys, motionKey)
+		}
+	}
+	sort.Strings(motionKeys)
+	motionIndex := map[string]int{}
+	for index, motionKey := range motionKeys {
+		motionIndex[motionKey] = index
+	}
+
+	ballots := make([][]idealPointBallot, len(motionKeys))
+	for _, vote := range votes {
+		subject, ok := subjectIndex[vote.SubjectID]
+		if !ok {
+			continue
+		}
+		motion, ok := motionIndex[vote.MotionKey]
+		if !ok {
+			continue
+		}
+		ballots[motion] = append(ballots[motion], idealPointBallot{subject: subject, voor: vote.Voor})
+	}
+	return subjects, motionKeys, ballots
+}
+
+// firstComponent scores the subjects on the first principal component of
+// their centred votes, like the party map, oriented so the subject furthest
+// from the centre is positive.
+func firstComponent(subjects int, ballots [][]idealPointBallot) []float64 {
+	gram := make([][]float64, subjects)
+	for subject := range gram {
+		gram[subject] = make([]float64, subjects)
+	}
+	centred := make([]float64, subjects)
+	for _, motion := range ballots {
+		sum := 0.0
+		for _, ballot := range motion {
+			if ballot.voor {
+				sum++
+			} else {
+				sum--
+			}
+		}
+		mean := sum / float64(len(motion))
+		for subject := range centred {
+			centred[subject] = 0
+		}
+		for _, ballot := range motion {
+			if ballot.voor {
+				centred[ballot.subject] = 1 - mean
+			} else {
+				centred[ballot.subject] = -1 - mean
+			}
+		}
+		for _, left := range motion {
+			for _, right := range motion {
+				gram[left.subject][right.subject] += centred[left.subject] * centred[right.subject]
+			}
+		}
+	}
+
+	_, vectors := symmetricEigen(gram)
+	scores := make([]float64, subjects)
+	furthest := 0
+	for subject := range scores {
+		scores[subject] = vectors[subject][0]
+		if math.Abs(scores[subject]) > math.Abs(scores[furthest]) {
+			furthest = subject
+		}
+	}
+	if scores[furthest] < 0 {
+		for subject := range scores {
+			scores[subject] = -scores[subject]
+		}
+	}
+	return scores
+}
+
+// rankPositions replaces scores by their rank spread over -1 to 1; tied
+// scores share their average rank.
+func rankPositions(scores []float64) []float64 {
+	order := make([]int, len(scores))
+	for index := range order {
+		order[index] = index
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] < scores[order[j]]
+	})
+
+	positions := make([]float64, len(scores))
+	if len(scores) < 2 {
+		return positions
+	}
+	scale := float64(len(scores) - 1)
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && scores[order[end]] == scores[order[start]] {
+			end++
+		}
+		rank := float64(start+end-1) / 2
+		for _, subject := range order[start:end] {
+			positions[subject] = 2*rank/scale - 1
+		}
+		start = end
+	}
+	return positions
+}
+
+func predictVoor(position float64, cut idealPointCut) bool {
+	return (position > cut.point) == cut.voorAbove
+}
+
+// fitCuttingPoints places every motion's cutting point between the two
+// neighbouring voters where it misclassifies the fewest votes, on either
+// polarity, and returns the total errors.
+func fitCuttingPoints(positions []float64, ballots [][]idealPointBallot) ([]idealPointCut, int) {
+	cuts := make([]idealPointCut, len(ballots))
+	total := 0
+	for motion, motionBallots := range ballots {
+		sorted := make([]idealPointBallot, len(motionBallots))
+		copy(sorted, motionBallots)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return positions[sorted[i].subject] < positions[sorted[j].subject]
+		})
+
+		tegenAbove := 0
+		for _, ballot := range sorted {
+			if !ballot.voor {
+				tegenAbove++
+			}
+		}
+		// With the cut below everyone, Voor above misclassifies every Tegen
+		// and Voor below every Voor.
+		voorBelow := 0
+		bestErrors := -1
+		for split := 0; split <= len(sorted); split++ {
+			if split > 0 {
+				if sorted[split-1].voor {
+					voorBelow++
+				} else {
+					tegenAbove--
+				}
+			}
+			if split > 0 && split < len(sorted) && positions[sorted[split-1].subject] == positions[sorted[split].subject] {
+				continue
+			}
+			tegenBelow := split - voorBelow
+			voorAbove := len(sorted) - split - tegenAbove
+			for _, candidate := range []struct {
+				errors    int
+				voorAbove bool
+			}{{voorBelow + tegenAbove, true}, {tegenBelow + voorAbove, false}} {
+				if bestErrors >= 0 && candidate.errors >= bestErrors {
+					continue
+				}
+				bestErrors = candidate.errors
+				cuts[motion] = idealPointCut{point: splitPoint(positions, sorted, split), voorAbove: candidate.voorAbove}
+			}
+		}
+		total += bestErrors
+	}
+	return cuts, total
+}
+
+func splitPoint(positions []float64, sorted []idealPointBallot, split int) float64 {
+	switch split {
+	case 0:
+		return positions[sorted[0].subject] - idealPointEdge
+	case len(sorted):
+		return positions[sorted[len(sorted)-1].subject] + idealPointEdge
+	default:
+		return (positions[sorted[split-1].subject] + positions[sorted[split].subject]) / 2
+	}
+}
+
+// placeSubjects moves every subject to the stretch between cutting points
+// where it misclassifies the fewest of its own votes, the stretch nearest
+// its current position on a tie.
+func placeSubjects(positions []float64, cuts []idealPointCut, ballots [][]idealPointBallot) []float64 {
+	type subjectVote struct {
+		cut  idealPointCut
+		voor bool
+	}
+	subjectVotes := make([][]subjectVote, len(positions))
+	for motion, motionBallots := range ballots {
+		for _, ballot := range motionBallots {
+			subjectVotes[ballot.subject] = append(subjectVotes[ballot.subject], subjectVote{cut: cuts[motion], voor: ballot.voor})
+		}
+	}
+
+	placed := make([]float64, len(positions))
+	for subject, votes := range subjectVotes {
+		if len(votes) == 0 {
+			placed[subject] = positions[subject]
+			continue
+		}
+		sort.Slice(votes, func(i, j int) bool {
+			return votes[i].cut.point < votes[j].cut.point
+		})
+
+		// Below every cutting point a subject is predicted Voor exactly
+		// where Voor lies below; passing a cutting point flips that motion.
+		errors := 0
+		for _, vote := range votes {
+			if vote.cut.voorAbove == vote.voor {
+				errors++
+			}
+		}
+		bestErrors := errors
+		bestPosition := votes[0].cut.point - idealPointEdge
+		for index := 0; index < len(votes); {
+			end := index
+			for end < len(votes) && votes[end].cut.point == votes[index].cut.point {
+				if votes[end].cut.voorAbove == votes[end].voor {
+					errors--
+				} else {
+					errors++
+				}
+				end++
+			}
+			position := votes[len(votes)-1].cut.point + idealPointEdge
+			if end < len(votes) {
+				position = (votes[index].cut.point + votes[end].cut.point) / 2
+			}
+			if errors < bestErrors || (errors == bestErrors && math.Abs(position-positions[subject]) < math.Abs(bestPosition-positions[subject])) {
+				bestErrors, bestPosition = errors, position
+			}
+			index = end
+		}
+		placed[subject] = bestPosition
+	}
+	return placed
+}
@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// spatialVotes has subjects a to f voting on one line: motion i is carried
+// by everyone from the (i+1)th subject up, so each cutting point falls
+// between two neighbours.
+func spatialVotes() []idealPointVote {
+	subjects := []string{"a", "b", "c", "d", "e", "f"}
+	votes := []idealPointVote{}
+	for round := 0; round < 2; round++ {
+		for cut := 1; cut < len(subjects); cut++ {
+			motion := fmt.Sprintf("m%d-%d", round, cut)
+			for index, subject := range subjects {
+				votes = append(votes, idealPointVote{MotionKey: motion, SubjectID: subject, Voor: index >= cut})
+			}
+		}
+	}
+	return votes
+}
+
+func idealPointCoordinates(fit idealPointFit) map[string]float64 {
+	coordinates := map[string]float64{}
+	for _, subject := range fit.Subjects {
+		coordinates[subject.SubjectID] = subject.Coordinate
+	}
+	return coordinates
+}
+
+func TestEstimateIdealPointsRecoversOrder(t *testing.T) {
+	fit := estimateIdealPoints(spatialVotes(), 1)
+
+	if fit.Errors != 0 || fit.Classified != 1 || fit.APRE != 1 {
+		t.Fatalf("errors = %d, classified = %v, apre = %v, want a perfect fit", fit.Errors, fit.Classified, fit.APRE)
+	}
+	coordinates := idealPointCoordinates(fit)
+	order := []string{"a", "b", "c", "d", "e", "f"}
+	if coordinates["a"] > coordinates["f"] {
+		order = []string{"f", "e", "d", "c", "b", "a"}
+	}
+	for index := 1; index < len(order); index++ {
+		if coordinates[order[index-1]] >= coordinates[order[index]] {
+			t.Fatalf("coordinates = %v, want a strictly monotone line", coordinates)
+		}
+	}
+	if coordinates[order[0]] != -1 || coordinates[order[len(order)-1]] != 1 {
+		t.Fatalf("coordinates = %v, want the ends at -1 and 1", coordinates)
+	}
+	for _, motion := range fit.Motions {
+		if motion.CrossCutting || motion.PRE != 1 {
+			t.Fatalf("motion %s = %+v, want it explained", motion.MotionKey, motion)
+		}
+	}
+}
+
+func TestEstimateIdealPointsFlagsCrossCuttingMotion(t *testing.T) {
+	votes := spatialVotes()
+	// Both ends against the middle cannot be split by one cutting point.
+	for _, subject := range []string{"a", "b", "c", "d", "e", "f"} {
+		votes = append(votes, idealPointVote{MotionKey: "x", SubjectID: subject, Voor: subject != "c" && subject != "d"})
+	}
+
+	fit := estimateIdealPoints(votes, 1)
+
+	crossCutting := []string{}
+	for _, motion := range fit.Motions {
+		if motion.CrossCutting {
+			crossCutting = append(crossCutting, motion.MotionKey)
+		}
+		if motion.MotionKey == "x" && (motion.Minority != 2 || motion.Errors != 2 || motion.PRE != 0) {
+			t.Fatalf("motion x = %+v, want minority 2, errors 2, pre 0", motion)
+		}
+	}
+	if !reflect.DeepEqual(crossCutting, []string{"x"}) {
+		t.Fatalf("cross-cutting = %v, want [x]", crossCutting)
+	}
+	if fit.Errors != 2 {
+		t.Fatalf("errors = %d, want 2", fit.Errors)
+	}
+}
+
+func TestEstimateIdealPointsDropsUnanimousMotionsAndSparseSubjects(t *testing.T) {
+	votes := spatialVotes()
+	for _, subject := range []string{"a", "b", "c", "d", "e", "f"} {
+		votes = append(votes, idealPointVote{MotionKey: "unanimous", SubjectID: subject, Voor: true})
+	}
+	// g votes on a single motion and falls below the minimum.
+	votes = append(votes, idealPointVote{MotionKey: "m0-1", SubjectID: "g", Voor: true})
+
+	fit := estimateIdealPoints(votes, 3)
+
+	if len(fit.Subjects) != 6 {
+		t.Fatalf("subjects = %+v, want a to f", fit.Subjects)
+	}
+	for _, motion := range fit.Motions {
+		if motion.MotionKey == "unanimous" {
+			t.Fatalf("unanimous motion was scaled")
+		}
+	}
+	if len(fit.Motions) != 10 {
+		t.Fatalf("motions = %d, want 10", len(fit.Motions))
+	}
+}
+
+func TestEstimateIdealPointsNeedsThreeSubjects(t *testing.T) {
+	fit := estimateIdealPoints([]idealPointVote{
+		{MotionKey: "m", SubjectID: "a", Voor: true},
+		{MotionKey: "m", SubjectID: "b", Voor: false},
+	}, 1)
+	if len(fit.Subjects) != 0 || len(fit.Motions) != 0 || fit.Votes != 0 {
+		t.Fatalf("fit = %+v, want empty", fit)
+	}
+}
+
+func TestRankPositionsSharesTies(t *testing.T) {
+	got := rankPositions([]float64{0.3, -2, 0.3, 5, 1})
+	want := []float64{-0.25, -1, -0.25, 1, 0.5}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("rankPositions = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeIdealPointScope(t *testing.T) {
+	for input, want := range map[string]string{"": "party", "party": "party", "member": "member"} {
+		if got, ok := NormalizeIdealPointScope(input); !ok || got != want {
+			t.Fatalf("NormalizeIdealPointScope(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+	if _, ok := NormalizeIdealPointScope("fractie"); ok {
+		t.Fatalf("NormalizeIdealPointScope(fractie) accepted")
+	}
+}
@@ -0,0 +1,343 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdealPointOptions struct {
+	Period CabinetPeriod
+	// Scope is IdealPointScopeParty or IdealPointScopeMember.
+	Scope string
+	// Kind is the motion kind to scale, as NormalizeMotionKind takes it.
+	Kind string
+	// MinVotes is the fewest votes on contested motions a fractie or
+	// Kamerlid needs to be scaled; it defaults to 20 for fracties and 5 for
+	// Kamerleden, who only vote individually in hoofdelijke stemmingen.
+	MinVotes int
+}
+
+// IdealPointRun summarizes one stored estimate.
+type IdealPointRun struct {
+	PeriodKey  string
+	Scope      string
+	Kind       string
+	Subjects   int
+	Motions    int
+	Votes      int
+	Errors     int
+	Classified float64
+	APRE       float64
+	ComputedAt time.Time
+}
+
+type IdealPoints struct {
+	Run    IdealPointRun
+	Points []IdealPoint
+	// CrossCutting are the motions the line explains worst, lowest
+	// proportional reduction in error first.
+	CrossCutting []IdealPointMotion
+}
+
+type IdealPoint struct {
+	SubjectID     string
+	SubjectName   string
+	PartySourceID *string
+	PartyName     *string
+	Coordinate    float64
+	Votes         int
+	Errors        int
+}
+
+type IdealPointMotion struct {
+	MotionKey    string
+	Number       *string
+	Subject      *string
+	ProposedAt   *time.Time
+	CuttingPoint float64
+	VoorAbove    bool
+	Voters       int
+	Minority     int
+	Errors       int
+	PRE          float64
+}
+
+type idealPointSubject struct {
+	Name          string
+	PartySourceID *string
+}
+
+// ComputeIdealPoints estimates the ideal points of one cabinet period, scope
+// and motion kind and replaces the stored estimate. It is slow enough to run
+// from `partijgedrag maintenance ideal-points` rather than on request.
+func ComputeIdealPoints(ctx context.Context, pool *pgxpool.Pool, options IdealPointOptions) (IdealPointRun, error) {
+	scope, ok := NormalizeIdealPointScope(options.Scope)
+	if !ok {
+		return IdealPointRun{}, fmt.Errorf("invalid scope %q", options.Scope)
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return IdealPointRun{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+	minVotes := options.MinVotes
+	if minVotes <= 0 {
+		minVotes = 20
+		if scope == IdealPointScopeMember {
+			minVotes = 5
+		}
+	}
+
+	votes, subjects, err := loadIdealPointVotes(ctx, pool, options.Period, scope, kind)
+	if err != nil {
+		return IdealPointRun{}, err
+	}
+	fit := estimateIdealPoints(votes, minVotes)
+
+	run := IdealPointRun{
+		PeriodKey:  options.Period.PeriodKey,
+		Scope:      scope,
+		Kind:       kind,
+		Subjects:   len(fit.Subjects),
+		Motions:    len(fit.Motions),
+		Votes:      fit.Votes,
+		Errors:     fit.Errors,
+		Classified: fit.Classified,
+		APRE:       fit.APRE,
+	}
+	return run, storeIdealPoints(ctx, pool, &run, fit, subjects)
+}
+
+func loadIdealPointVotes(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod, scope string, kind string) ([]idealPointVote, map[string]idealPointSubject, error) {
+	query := `
+		SELECT pp.motion_key,
+		       pp.party_source_id,
+		       pp.position = 'FOR',
+		       COALESCE(p.short_name, pp.party_name, pp.party_source_id),
+		       pp.party_source_id
+		FROM motion_party_positions pp
+		JOIN motions m ON m.motion_key = pp.motion_key
+		LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+		                   AND p.source_id = pp.party_source_id
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND m.proposed_at >= $2
+		  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+		  AND ($4::text = 'all' OR m.kind = $4)
+		  AND pp.position <> 'NEUTRAL'
+	`
+	if scope == IdealPointScopeMember {
+		// A Kamerlid's position on a motion is the side of most of their
+		// votes over its decisions; ties are left out like NEUTRAL fracties.
+		query = `
+			SELECT v.motion_key,
+			       v.person_source_id,
+			       count(*) FILTER (WHERE v.vote_type = 'Voor') > count(*) FILTER (WHERE v.vote_type = 'Tegen'),
+			       COALESCE(min(mb.full_name), min(v.actor_name), v.person_source_id),
+			       max(v.party_source_id)
+			FROM votes v
+			JOIN motions m ON m.motion_key = v.motion_key
+			JOIN decisions d ON d.decision_key = v.decision_key
+			LEFT JOIN members mb ON mb.source_key = v.source_key
+			                    AND mb.source_id = v.person_source_id
+			WHERE m.jurisdiction_key = $1
+			  AND m.source_deleted = false
+			  AND m.proposed_at >= $2
+			  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+			  AND ($4::text = 'all' OR m.kind = $4)
+			  AND d.source_deleted = false
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+			  AND v.person_source_id IS NOT NULL
+			  AND v.vote_type IN ('Voor', 'Tegen')
+			GROUP BY v.motion_key, v.person_source_id
+			HAVING count(*) FILTER (WHERE v.vote_type = 'Voor') <> count(*) FILTER (WHERE v.vote_type = 'Tegen')
+		`
+	}
+
+	rows, err := pool.Query(ctx, query, period.Jurisdiction, period.StartedOn, period.EndedOn, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	votes := []idealPointVote{}
+	subjects := map[string]idealPointSubject{}
+	for rows.Next() {
+		var vote idealPointVote
+		var subject idealPointSubject
+		if err := rows.Scan(&vote.MotionKey, &vote.SubjectID, &vote.Voor, &subject.Name, &subject.PartySourceID); err != nil {
+			return nil, nil, err
+		}
+		votes = append(votes, vote)
+		subjects[vote.SubjectID] = subject
+	}
+	return votes, subjects, rows.Err()
+}
+
+func storeIdealPoints(ctx context.Context, pool *pgxpool.Pool, run *IdealPointRun, fit idealPointFit, subjects map[string]idealPointSubject) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM ideal_point_runs
+		WHERE period_key = $1
+		  AND scope = $2
+		  AND kind = $3
+	`, run.PeriodKey, run.Scope, run.Kind); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO ideal_point_runs (period_key, scope, kind, subjects, motions, votes, errors, classified, apre)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING computed_at
+	`, run.PeriodKey, run.Scope, run.Kind, run.Subjects, run.Motions, run.Votes, run.Errors, run.Classified, run.APRE).Scan(&run.ComputedAt); err != nil {
+		return err
+	}
+
+	subjectIDs, names, parties := []string{}, []string{}, []*string{}
+	coordinates, subjectVotes, subjectErrors := []float64{}, []int{}, []int{}
+	for _, subject := range fit.Subjects {
+		subjectIDs = append(subjectIDs, subject.SubjectID)
+		names = append(names, subjects[subject.SubjectID].Name)
+		parties = append(parties, subjects[subject.SubjectID].PartySourceID)
+		coordinates = append(coordinates, subject.Coordinate)
+		subjectVotes = append(subjectVotes, subject.Votes)
+		subjectErrors = append(subjectErrors, subject.Errors)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ideal_points (period_key, scope, kind, subject_id, subject_name, party_source_id, coordinate, votes, errors)
+		SELECT $1, $2, $3, s.subject_id, s.subject_name, s.party_source_id, s.coordinate, s.votes, s.errors
+		FROM unnest($4::text[], $5::text[], $6::text[], $7::float8[], $8::int[], $9::int[])
+		     AS s(subject_id, subject_name, party_source_id, coordinate, votes, errors)
+	`, run.PeriodKey, run.Scope, run.Kind, subjectIDs, names, parties, coordinates, subjectVotes, subjectErrors); err != nil {
+		return err
+	}
+
+	motionKeys, cuttingPoints, voorAbove := []string{}, []float64{}, []bool{}
+	voters, minorities, motionErrors, pres, crossCutting := []int{}, []int{}, []int{}, []float64{}, []bool{}
+	for _, motion := range fit.Motions {
+		motionKeys = append(motionKeys, motion.MotionKey)
+		cuttingPoints = append(cuttingPoints, motion.CuttingPoint)
+		voorAbove = append(voorAbove, motion.VoorAbove)
+		voters = append(voters, motion.Voters)
+		minorities = append(minorities, motion.Minority)
+		motionErrors = append(motionErrors, motion.Errors)
+		pres = append(pres, motion.PRE)
+		crossCutting = append(crossCutting, motion.CrossCutting)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ideal_point_motions (period_key, scope, kind, motion_key, cutting_point, voor_above, voters, minority, errors, pre, cross_cutting)
+		SELECT $1, $2, $3, c.motion_key, c.cutting_point, c.voor_above, c.voters, c.minority, c.errors, c.pre, c.cross_cutting
+		FROM unnest($4::text[], $5::float8[], $6::bool[], $7::int[], $8::int[], $9::int[], $10::float8[], $11::bool[])
+		     AS c(motion_key, cutting_point, voor_above, voters, minority, errors, pre, cross_cutting)
+	`, run.PeriodKey, run.Scope, run.Kind, motionKeys, cuttingPoints, voorAbove, voters, minorities, motionErrors, pres, crossCutting); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// LoadIdealPoints reads the stored estimate of a cabinet period, scope and
+// motion kind.
+// It is not cached: a new estimate comes from a maintenance run, not a sync,
+// so the sync-driven cache would keep serving the old one. Without a stored
+// estimate the error satisfies IsNotFound.
+func LoadIdealPoints(ctx context.Context, pool *pgxpool.Pool, periodKey string, scope string, kind string, limit int) (IdealPoints, error) {
+	normalizedScope, ok := NormalizeIdealPointScope(scope)
+	if !ok {
+		return IdealPoints{}, fmt.Errorf("invalid scope %q", scope)
+	}
+	normalizedKind, ok := NormalizeMotionKind(kind)
+	if !ok {
+		return IdealPoints{}, fmt.Errorf("invalid kind %q", kind)
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	result := IdealPoints{Points: []IdealPoint{}, CrossCutting: []IdealPointMotion{}}
+	run := &result.Run
+	if err := pool.QueryRow(ctx, `
+		SELECT period_key, scope, kind, subjects, motions, votes, errors, classified, apre, computed_at
+		FROM ideal_point_runs
+		WHERE period_key = $1
+		  AND scope = $2
+		  AND kind = $3
+	`, periodKey, normalizedScope, normalizedKind).Scan(&run.PeriodKey, &run.Scope, &run.Kind, &run.Subjects, &run.Motions, &run.Votes, &run.Errors, &run.Classified, &run.APRE, &run.ComputedAt); err != nil {
+		return IdealPoints{}, err
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT ip.subject_id,
+		       COALESCE(ip.subject_name, ip.subject_id),
+		       ip.party_source_id,
+		       COALESCE(p.short_name, p.name),
+		       ip.coordinate,
+		       ip.votes,
+		       ip.errors
+		FROM ideal_points ip
+		LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+		                   AND p.source_id = ip.party_source_id
+		WHERE ip.period_key = $1
+		  AND ip.scope = $2
+		  AND ip.kind = $3
+		ORDER BY ip.coordinate, ip.subject_name, ip.subject_id
+	`, periodKey, normalizedScope, normalizedKind)
+	if err != nil {
+		return IdealPoints{}, err
+	}
+	for rows.Next() {
+		var point IdealPoint
+		if err := rows.Scan(&point.SubjectID, &point.SubjectName, &point.PartySourceID, &point.PartyName, &point.Coordinate, &point.Votes, &point.Errors); err != nil {
+			rows.Close()
+			return IdealPoints{}, err
+		}
+		result.Points = append(result.Points, point)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return IdealPoints{}, err
+	}
+
+	rows, err = pool.Query(ctx, `
+		SELECT c.motion_key,
+		       m.number,
+		       m.subject,
+		       m.proposed_at,
+		       c.cutting_point,
+		       c.voor_above,
+		       c.voters,
+		       c.minority,
+		       c.errors,
+		       c.pre
+		FROM ideal_point_motions c
+		JOIN motions m ON m.motion_key = c.motion_key
+		WHERE c.period_key = $1
+		  AND c.scope = $2
+		  AND c.kind = $3
+		  AND c.cross_cutting
+		ORDER BY c.pre, c.errors DESC, m.proposed_at DESC NULLS LAST, c.motion_key
+		LIMIT $4
+	`, periodKey, normalizedScope, normalizedKind, limit)
+	if err != nil {
+		return IdealPoints{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var motion IdealPointMotion
+		if err := rows.Scan(&motion.MotionKey, &motion.Number, &motion.Subject, &motion.ProposedAt, &motion.CuttingPoint, &motion.VoorAbove, &motion.Voters, &motion.Minority, &motion.Errors, &motion.PRE); err != nil {
+			return IdealPoints{}, err
+		}
+		result.CrossCutting = append(result.CrossCutting, motion)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// Motion kinds an analysis can be restricted to. The stored kind is the Zaak
+// Soort; MotionKindAll disables the filter.
+const (
+	MotionKindMotion    = "Motie"
+	MotionKindAmendment = "Amendement"
+	MotionKindBill      = "Wetsvoorstel"
+	MotionKindAll       = "all"
+)
+
+// NormalizeMotionKind maps a kind query value onto the stored kind. An empty
+// value means moties only, which is what every analysis reported before
+// amendementen were ingested.
+func NormalizeMotionKind(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "motie", "moties":
+		return MotionKindMotion, true
+	case "amendement", "amendementen":
+		return MotionKindAmendment, true
+	case "wetsvoorstel", "wetsvoorstellen":
+		return MotionKindBill, true
+	case "all":
+		return MotionKindAll, true
+	default:
+		return "", false
+	}
+}
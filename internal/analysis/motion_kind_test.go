@@ -0,0 +1,26 @@
+package analysis
+
+import "testing"
+
+func TestNormalizeMotionKind(t *testing.T) {
+	for input, want := range map[string]string{
+		"":             MotionKindMotion,
+		"motie":        MotionKindMotion,
+		" Amendement":  MotionKindAmendment,
+		"amendementen": MotionKindAmendment,
+		"Wetsvoorstel": MotionKindBill,
+		"ALL":          MotionKindAll,
+	} {
+		got, ok := NormalizeMotionKind(input)
+		if !ok {
+			t.Fatalf("NormalizeMotionKind(%q) returned !ok", input)
+		}
+		if got != want {
+			t.Fatalf("NormalizeMotionKind(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if got, ok := NormalizeMotionKind("besluit"); ok || got != "" {
+		t.Fatalf("NormalizeMotionKind invalid = %q, %v; want empty, false", got, ok)
+	}
+}
@@ -0,0 +1,337 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+type PartyCohesionOptions struct {
+	Jurisdiction string
+	// PartySourceID limits the result to one fractie. Empty covers every
+	// fractie that voted in the period.
+	PartySourceID string
+	DateFrom      *time.Time
+	DateTo        *time.Time
+	Category      string
+	Kind          string
+	// Limit caps Divided; it defaults to 10.
+	Limit int
+}
+
+// PartyCohesion reports how united each fractie voted, as the Rice index of
+// its seats per decision: |voor - tegen| / (voor + tegen). A fractie-wide vote
+// scores 1; only hoofdelijke stemmingen and votes the fractie split can score
+// lower. Ties, which politics.PartyPosition calls NEUTRAL, score 0.
+type PartyCohesion struct {
+	Parties []PartyCohesionStats
+	// Categories are over all fracties in the result, or the one asked for.
+	Categories []CategoryCohesionStats
+	// Divided are the decisions where a fractie was least united, the most
+	// divided first.
+	Divided []DividedPartyVote
+}
+
+// CohesionStats averages the Rice index over the decisions a fractie voted on.
+type CohesionStats struct {
+	Decisions  int
+	SplitVotes int
+	Rice       float64
+}
+
+type PartyCohesionStats struct {
+	PartySourceID string
+	PartyName     string
+	CohesionStats
+}
+
+type CategoryCohesionStats struct {
+	CategoryKey string
+	Name        string
+	Kind        string
+	CohesionStats
+}
+
+type DividedPartyVote struct {
+	MotionKey     string
+	DecisionKey   string
+	Number        *string
+	Subject       *string
+	ProposedAt    *time.Time
+	PartySourceID string
+	PartyName     string
+	SeatsFor      int
+	SeatsAgainst  int
+	Rice          float64
+}
+
+// partyDecisionRow is a fractie's seat-weighted Voor and Tegen on one
+// decision.
+type partyDecisionRow struct {
+	MotionKey     string
+	DecisionKey   string
+	Number        *string
+	Subject       *string
+	ProposedAt    *time.Time
+	PartySourceID string
+	PartyName     string
+	SeatsFor      int
+	SeatsAgainst  int
+}
+
+type cohesionCategory struct {
+	CategoryKey string
+	Name        string
+	Kind        string
+}
+
+func LoadPartyCohesion(ctx context.Context, pool *pgxpool.Pool, options PartyCohesionOptions) (PartyCohesion, error) {
+	jurisdiction := options.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PartyCohesion{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+
+	cacheKey := fmt.Sprintf("analysis:party_cohesion:%s:%s:%s:%s:%s:%s:%d", jurisdiction, options.PartySourceID, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), options.Category, kind, limit)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyPartyCohesion(cached.(PartyCohesion)), nil
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT v.motion_key,
+		       v.decision_key,
+		       m.number,
+		       m.subject,
+		       m.proposed_at,
+		       v.party_source_id,
+		       COALESCE(min(p.short_name), min(v.party_name), v.party_source_id) AS party_name,
+		       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Voor'), 0)::int AS seats_for,
+		       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int AS seats_against
+		FROM votes v
+		JOIN motions m ON m.motion_key = v.motion_key
+		JOIN decisions d ON d.decision_key = v.decision_key
+		LEFT JOIN parties p ON p.source_key = v.source_key
+		                   AND p.source_id = v.party_source_id
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND d.source_deleted = false
+		  AND v.source_deleted = false
+		  AND v.mistake = false
+		  AND v.party_source_id IS NOT NULL
+		  AND v.vote_type IN ('Voor', 'Tegen')
+		  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
+		  AND ($3::timestamptz IS NULL OR m.proposed_at <= $3)
+		  AND ($4::text = '' OR EXISTS (
+		        SELECT 1 FROM motion_categories mc
+		        WHERE mc.motion_key = m.motion_key
+		          AND mc.category_key = $4))
+		  AND ($5::text = 'all' OR m.kind = $5)
+		  AND ($6::text = '' OR v.party_source_id = $6)
+		GROUP BY v.motion_key, v.decision_key, m.number, m.subject, m.proposed_at, v.party_source_id
+		ORDER BY m.proposed_at DESC NULLS LAST, v.motion_key, v.decision_key
+	`, jurisdiction, options.DateFrom, options.DateTo, options.Category, kind, options.PartySourceID)
+	if err != nil {
+		return PartyCohesion{}, err
+	}
+	defer rows.Close()
+
+	decisions := []partyDecisionRow{}
+	for rows.Next() {
+		var row partyDecisionRow
+		if err := rows.Scan(
+			&row.MotionKey,
+			&row.DecisionKey,
+			&row.Number,
+			&row.Subject,
+			&row.ProposedAt,
+			&row.PartySourceID,
+			&row.PartyName,
+			&row.SeatsFor,
+			&row.SeatsAgainst,
+		); err != nil {
+			return PartyCohesion{}, err
+		}
+		decisions = append(decisions, row)
+	}
+	if err := rows.Err(); err != nil {
+		return PartyCohesion{}, err
+	}
+
+	categories, err := loadCohesionCategories(ctx, pool, decisions)
+	if err != nil {
+		return PartyCohesion{}, err
+	}
+
+	result := computePartyCohesion(decisions, categories)
+	if len(result.Divided) > limit {
+		result.Divided = result.Divided[:limit]
+	}
+
+	cache.Global().Set(cacheKey, result)
+	return copyPartyCohesion(result), nil
+}
+
+// loadCohesionCategories returns the categories of the motions voted on, by
+// motion key.
+func loadCohesionCategories(ctx context.Context, pool *pgxpool.Pool, decisions []partyDecisionRow) (map[string][]cohesionCategory, error) {
+	motionKeys := []string{}
+	seen := map[string]bool{}
+	for _, decision := range decisions {
+		if !seen[decision.MotionKey] {
+			seen[decision.MotionKey] = true
+			motionKeys = append(motionKeys, decision.MotionKey)
+		}
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT mc.motion_key, c.category_key, c.name, c.kind
+		FROM motion_categories mc
+		JOIN categories c ON c.category_key = mc.category_key
+		WHERE mc.motion_key = ANY($1::text[])
+	`, motionKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := map[string][]cohesionCategory{}
+	for rows.Next() {
+		var motionKey string
+		var category cohesionCategory
+		if err := rows.Scan(&motionKey, &category.CategoryKey, &category.Name, &category.Kind); err != nil {
+			return nil, err
+		}
+		categories[motionKey] = append(categories[motionKey], category)
+	}
+	return categories, rows.Err()
+}
+
+// computePartyCohesion averages the Rice index per fractie and per category.
+// Fracties and categories are ranked least united first; divided votes keep
+// only the decisions a fractie split on.
+func computePartyCohesion(decisions []partyDecisionRow, categories map[string][]cohesionCategory) PartyCohesion {
+	type riceSum struct {
+		stats CohesionStats
+		total float64
+	}
+	add := func(sum *riceSum, rice float64) {
+		sum.stats.Decisions++
+		if rice < 1 {
+			sum.stats.SplitVotes++
+		}
+		sum.total += rice
+	}
+
+	partySums := map[string]*riceSum{}
+	partyNames := map[string]string{}
+	categorySums := map[string]*riceSum{}
+	categoryInfo := map[string]cohesionCategory{}
+	result := PartyCohesion{Parties: []PartyCohesionStats{}, Categories: []CategoryCohesionStats{}, Divided: []DividedPartyVote{}}
+
+	for _, decision := range decisions {
+		rice := politics.RiceIndex(decision.SeatsFor, decision.SeatsAgainst)
+
+		sum, ok := partySums[decision.PartySourceID]
+		if !ok {
+			sum = &riceSum{}
+			partySums[decision.PartySourceID] = sum
+			partyNames[decision.PartySourceID] = decision.PartyName
+		}
+		add(sum, rice)
+
+		for _, category := range categories[decision.MotionKey] {
+			sum, ok := categorySums[category.CategoryKey]
+			if !ok {
+				sum = &riceSum{}
+				categorySums[category.CategoryKey] = sum
+				categoryInfo[category.CategoryKey] = category
+			}
+			add(sum, rice)
+		}
+
+		if rice < 1 {
+			result.Divided = append(result.Divided, DividedPartyVote{
+				MotionKey:     decision.MotionKey,
+				DecisionKey:   decision.DecisionKey,
+				Number:        decision.Number,
+				Subject:       decision.Subject,
+				ProposedAt:    decision.ProposedAt,
+				PartySourceID: decision.PartySourceID,
+				PartyName:     decision.PartyName,
+				SeatsFor:      decision.SeatsFor,
+				SeatsAgainst:  decision.SeatsAgainst,
+				Rice:          rice,
+			})
+		}
+	}
+
+	average := func(sum *riceSum) CohesionStats {
+		stats := sum.stats
+		stats.Rice = sum.total / float64(stats.Decisions)
+		return stats
+	}
+	for partySourceID, sum := range partySums {
+		result.Parties = append(result.Parties, PartyCohesionStats{
+			PartySourceID: partySourceID,
+			PartyName:     partyNames[partySourceID],
+			CohesionStats: average(sum),
+		})
+	}
+	for categoryKey, sum := range categorySums {
+		category := categoryInfo[categoryKey]
+		result.Categories = append(result.Categories, CategoryCohesionStats{
+			CategoryKey:   categoryKey,
+			Name:          category.Name,
+			Kind:          category.Kind,
+			CohesionStats: average(sum),
+		})
+	}
+
+	sort.Slice(result.Parties, func(i, j int) bool {
+		left, right := result.Parties[i], result.Parties[j]
+		if left.Rice != right.Rice {
+			return left.Rice < right.Rice
+		}
+		return left.PartyName < right.PartyName
+	})
+	sort.Slice(result.Categories, func(i, j int) bool {
+		left, right := result.Categories[i], result.Categories[j]
+		if left.Rice != right.Rice {
+			return left.Rice < right.Rice
+		}
+		return left.Name < right.Name
+	})
+	// The stable sort keeps the latest motion first among equally divided votes.
+	sort.SliceStable(result.Divided, func(i, j int) bool {
+		return result.Divided[i].Rice < result.Divided[j].Rice
+	})
+	return result
+}
+
+func copyPartyCohesion(src PartyCohesion) PartyCohesion {
+	out := PartyCohesion{
+		Parties:    make([]PartyCohesionStats, len(src.Parties)),
+		Categories: make([]CategoryCohesionStats, len(src.Categories)),
+		Divided:    make([]DividedPartyVote, len(src.Divided)),
+	}
+	copy(out.Parties, src.Parties)
+	copy(out.Categories, src.Categories)
+	copy(out.Divided, src.Divided)
+	return out
+}
@@ -0,0 +1,53 @@
+package analysis
+
+import "testing"
+
+func TestComputePartyCohesion(t *testing.T) {
+	decision := func(decisionKey string, party string, seatsFor int, seatsAgainst int) partyDecisionRow {
+		return partyDecisionRow{
+			MotionKey:     "motion-" + decisionKey,
+			DecisionKey:   decisionKey,
+			PartySourceID: party,
+			PartyName:     party,
+			SeatsFor:      seatsFor,
+			SeatsAgainst:  seatsAgainst,
+		}
+	}
+	categories := map[string][]cohesionCategory{
+		"motion-d1": {{CategoryKey: "zorg", Name: "Zorg", Kind: "topic"}},
+		"motion-d2": {{CategoryKey: "zorg", Name: "Zorg", Kind: "topic"}},
+	}
+
+	result := computePartyCohesion([]partyDecisionRow{
+		// A fractie-wide vote and a 15-5 split: VVD averages 0.75.
+		decision("d1", "vvd", 24, 0),
+		decision("d2", "vvd", 15, 5),
+		// D66 ties once: Rice 0.
+		decision("d1", "d66", 0, 9),
+		decision("d3", "d66", 4, 4),
+	}, categories)
+
+	if len(result.Parties) != 2 {
+		t.Fatalf("len(Parties) = %d, want 2", len(result.Parties))
+	}
+	if party := result.Parties[0]; party.PartySourceID != "d66" || party.Rice != 0.5 || party.SplitVotes != 1 || party.Decisions != 2 {
+		t.Fatalf("Parties[0] = %+v, want d66 with Rice 0.5 over 2 decisions, 1 split", party)
+	}
+	if party := result.Parties[1]; party.PartySourceID != "vvd" || party.Rice != 0.75 {
+		t.Fatalf("Parties[1] = %+v, want vvd with Rice 0.75", party)
+	}
+
+	if len(result.Categories) != 1 || result.Categories[0].Decisions != 3 || result.Categories[0].SplitVotes != 1 {
+		t.Fatalf("Categories = %+v, want zorg over 3 decisions with 1 split", result.Categories)
+	}
+
+	if len(result.Divided) != 2 {
+		t.Fatalf("len(Divided) = %d, want 2", len(result.Divided))
+	}
+	if divided := result.Divided[0]; divided.DecisionKey != "d3" || divided.Rice != 0 {
+		t.Fatalf("Divided[0] = %+v, want the d66 tie on d3", divided)
+	}
+	if divided := result.Divided[1]; divided.DecisionKey != "d2" || divided.Rice != 0.5 {
+		t.Fatalf("Divided[1] = %+v, want the vvd split on d2", divided)
+	}
+}
@@ -21,6 +21,7 @@ type PartyComparisonOptions struct {
 	Party2SourceID string
 	DateFrom       *time.Time
 	DateTo         *time.Time
+	Kind           string
 }
 
 type PartyComparison struct {
@@ -90,10 +91,15 @@ func LoadPartyComparison(ctx context.Context, pool *pgxpool.Pool, options PartyC
 	if jurisdiction == "" {
 		jurisdiction = "nl-tweede-kamer"
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PartyComparison{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+	options.Kind = kind
 
-	cacheKey := fmt.Sprintf("analysis:party_comparison:%s:%s:%s:%s:%s",
+	cacheKey := fmt.Sprintf("analysis:party_comparison:%s:%s:%s:%s:%s:%s",
 		jurisdiction, options.Party1SourceID, options.Party2SourceID,
-		formatOptTime(options.DateFrom), formatOptTime(options.DateTo))
+		formatOptTime(options.DateFrom), formatOptTime(options.DateTo), kind)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		comparison := cached.(PartyComparison)
 		comparison.Categories = copyComparisonCategories(comparison.Categories)
@@ -106,7 +112,7 @@ func LoadPartyComparison(ctx context.Context, pool *pgxpool.Pool, options PartyC
 		       COUNT(*) FILTER (WHERE agree)::int AS same_votes,
 		       COUNT(*) FILTER (WHERE NOT agree)::int AS different_votes
 		FROM pairs
-	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo).Scan(
+	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo, options.Kind).Scan(
 		&comparison.CommonMotions,
 		&comparison.SameVotes,
 		&comparison.DifferentVotes,
@@ -144,7 +150,7 @@ func loadComparisonCategories(ctx context.Context, pool *pgxpool.Pool, jurisdict
 		JOIN categories c ON c.category_key = mc.category_key
 		GROUP BY c.category_key, c.name, c.kind
 		ORDER BY different_votes DESC, common_motions DESC, c.name
-	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo)
+	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo, options.Kind)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +189,10 @@ func LoadComparisonMotions(ctx context.Context, pool *pgxpool.Pool, options Comp
 	if !ok {
 		return nil, 0, fmt.Errorf("invalid relation %q", options.Relation)
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid kind %q", options.Kind)
+	}
 	limit := options.Limit
 	if limit <= 0 {
 		limit = 25
@@ -195,9 +205,9 @@ func LoadComparisonMotions(ctx context.Context, pool *pgxpool.Pool, options Comp
 		offset = 0
 	}
 
-	cacheKey := fmt.Sprintf("analysis:comparison_motions:%s:%s:%s:%s:%s:%s:%s:%d:%d",
+	cacheKey := fmt.Sprintf("analysis:comparison_motions:%s:%s:%s:%s:%s:%s:%s:%s:%d:%d",
 		jurisdiction, options.Party1SourceID, options.Party2SourceID,
-		formatOptTime(options.DateFrom), formatOptTime(options.DateTo),
+		formatOptTime(options.DateFrom), formatOptTime(options.DateTo), kind,
 		relation, options.Category, limit, offset)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		page := cached.(comparisonMotionPage)
@@ -222,22 +232,22 @@ func LoadComparisonMotions(ctx context.Context, pool *pgxpool.Pool, options Comp
 		FROM pairs p
 		JOIN motions m ON m.motion_key = p.motion_key
 		WHERE (
-		    $6::text = 'all'
-		    OR ($6::text = 'agree' AND p.agree)
-		    OR ($6::text = 'disagree' AND NOT p.agree)
+		    $7::text = 'all'
+		    OR ($7::text = 'agree' AND p.agree)
+		    OR ($7::text = 'disagree' AND NOT p.agree)
 		  )
 		  AND (
-		    $7::text = ''
+		    $8::text = ''
 		    OR EXISTS (
 		      SELECT 1
 		      FROM motion_categories mc
 		      WHERE mc.motion_key = p.motion_key
-		        AND mc.category_key = $7
+		        AND mc.category_key = $8
 		    )
 		  )
 		ORDER BY m.proposed_at DESC NULLS LAST, m.motion_key
-		LIMIT $8 OFFSET $9
-	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo,
+		LIMIT $9 OFFSET $10
+	`, jurisdiction, options.Party1SourceID, options.Party2SourceID, options.DateFrom, options.DateTo, kind,
 		relation, options.Category, limit, offset)
 	if err != nil {
 		return nil, 0, err
@@ -322,28 +332,22 @@ func attachComparisonCategories(ctx context.Context, pool *pgxpool.Pool, motions
 // comparisonPairsSQL classifies both parties' positions per motion and pairs
 // them up. It mirrors LoadPartyLikeness: only motions where a party cast a
 // clear (non-tied) Voor/Tegen majority count, so the totals derived here match
-// the likeness matrix cell that links to this page.
+// the likeness matrix cell that links to this page. $6 is the motion kind.
 func comparisonPairsSQL() string {
 	return `
 		WITH party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       CASE
-			         WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) THEN 'FOR'
-			         ELSE 'AGAINST'
-			       END AS position
-			FROM votes v
-			JOIN motions m ON m.motion_key = v.motion_key
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN motions m ON m.motion_key = pp.motion_key
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
-			  AND v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.party_source_id IN ($2, $3)
-			  AND v.vote_type IN ('Voor', 'Tegen')
+			  AND pp.party_source_id IN ($2, $3)
+			  AND pp.position <> 'NEUTRAL'
 			  AND ($4::timestamptz IS NULL OR m.proposed_at >= $4)
 			  AND ($5::timestamptz IS NULL OR m.proposed_at <= $5)
-			GROUP BY v.motion_key, v.party_source_id
-			HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+			  AND ($6::text = 'all' OR m.kind = $6)
 		),
 		pairs AS (
 			SELECT p1.motion_key,
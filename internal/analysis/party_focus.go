@@ -16,6 +16,7 @@ type PartyFocusOptions struct {
 	DateFrom      *time.Time
 	DateTo        *time.Time
 	MinCommon     int
+	Kind          string
 }
 
 type PartyFocus struct {
@@ -46,8 +47,13 @@ func LoadPartyFocus(ctx context.Context, pool *pgxpool.Pool, options PartyFocusO
 	if jurisdiction == "" {
 		jurisdiction = "nl-tweede-kamer"
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PartyFocus{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+	options.Kind = kind
 
-	cacheKey := fmt.Sprintf("analysis:party_focus:%s:%s:%s:%s:%d", jurisdiction, options.PartySourceID, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), options.MinCommon)
+	cacheKey := fmt.Sprintf("analysis:party_focus:%s:%s:%s:%s:%d:%s", jurisdiction, options.PartySourceID, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), options.MinCommon, kind)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		return cached.(PartyFocus), nil
 	}
@@ -109,24 +115,17 @@ func loadPartyLikenessForParty(ctx context.Context, pool *pgxpool.Pool, jurisdic
 
 	rows, err := pool.Query(ctx, `
 		WITH party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       CASE WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END)
-			                 > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
-			            THEN 'FOR' ELSE 'AGAINST' END AS position
-			FROM votes v
-			JOIN motions m ON m.motion_key = v.motion_key
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN motions m ON m.motion_key = pp.motion_key
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
-			  AND v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.party_source_id IS NOT NULL
-			  AND v.vote_type IN ('Voor', 'Tegen')
+			  AND pp.position <> 'NEUTRAL'
 			  AND ($3::timestamptz IS NULL OR m.proposed_at >= $3)
 			  AND ($4::timestamptz IS NULL OR m.proposed_at <= $4)
-			GROUP BY v.motion_key, v.party_source_id
-			HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END)
-			    <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+			  AND ($6::text = 'all' OR m.kind = $6)
 		)
 		SELECT $2::text AS party1_source_id,
 		       COALESCE(party1.short_name, $2::text) AS party1_name,
@@ -148,7 +147,7 @@ func loadPartyLikenessForParty(ctx context.Context, pool *pgxpool.Pool, jurisdic
 		         COALESCE(party2.short_name, pp2.party_source_id)
 		HAVING COUNT(*) >= $5
 		ORDER BY similarity DESC, common_motions DESC, party2_name
-	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo, minCommon)
+	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo, minCommon, options.Kind)
 	if err != nil {
 		return nil, err
 	}
@@ -166,25 +165,20 @@ func loadPartyLikenessForParty(ctx context.Context, pool *pgxpool.Pool, jurisdic
 }
 
 // partyPositionsCTE classifies each motion the party cast a clear (non-tied)
-// Voor/Tegen majority on, within the jurisdiction and optional date range.
+// Voor/Tegen majority on, within the jurisdiction, optional date range ($3/$4)
+// and motion kind ($5).
 const partyPositionsCTE = `
-	SELECT v.motion_key,
-	       CASE
-	         WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) THEN 'FOR'
-	         ELSE 'AGAINST'
-	       END AS position
-	FROM votes v
-	JOIN motions m ON m.motion_key = v.motion_key
+	SELECT pp.motion_key,
+	       pp.position
+	FROM motion_party_positions pp
+	JOIN motions m ON m.motion_key = pp.motion_key
 	WHERE m.jurisdiction_key = $1
 	  AND m.source_deleted = false
-	  AND v.source_deleted = false
-	  AND v.mistake = false
-	  AND v.party_source_id = $2
-	  AND v.vote_type IN ('Voor', 'Tegen')
+	  AND pp.party_source_id = $2
+	  AND pp.position <> 'NEUTRAL'
 	  AND ($3::timestamptz IS NULL OR m.proposed_at >= $3)
 	  AND ($4::timestamptz IS NULL OR m.proposed_at <= $4)
-	GROUP BY v.motion_key
-	HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+	  AND ($5::text = 'all' OR m.kind = $5)
 `
 
 func loadPartyCategoryStats(ctx context.Context, pool *pgxpool.Pool, jurisdiction string, options PartyFocusOptions) (PartyVoteTotals, []PartyCategoryStats, error) {
@@ -195,7 +189,7 @@ func loadPartyCategoryStats(ctx context.Context, pool *pgxpool.Pool, jurisdictio
 		       COALESCE(SUM(CASE WHEN position = 'FOR' THEN 1 ELSE 0 END), 0)::int AS voted_for,
 		       COALESCE(SUM(CASE WHEN position = 'AGAINST' THEN 1 ELSE 0 END), 0)::int AS voted_against
 		FROM party_positions
-	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo).Scan(
+	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo, options.Kind).Scan(
 		&totals.MotionsVoted,
 		&totals.VotedFor,
 		&totals.VotedAgainst,
@@ -217,7 +211,7 @@ func loadPartyCategoryStats(ctx context.Context, pool *pgxpool.Pool, jurisdictio
 		JOIN categories c ON c.category_key = mc.category_key
 		GROUP BY c.category_key, c.name, c.kind
 		ORDER BY COUNT(*) DESC, c.name
-	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo)
+	`, jurisdiction, options.PartySourceID, options.DateFrom, options.DateTo, options.Kind)
 	if err != nil {
 		return PartyVoteTotals{}, nil, err
 	}
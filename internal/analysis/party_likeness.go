@@ -43,6 +43,8 @@ type PartyLikenessOptions struct {
 	DateFrom     *time.Time
 	DateTo       *time.Time
 	MinCommon    int
+	// Kind is passed through NormalizeMotionKind; empty means moties only.
+	Kind string
 }
 
 func LoadParties(ctx context.Context, pool *pgxpool.Pool, options PartyListOptions) ([]Party, error) {
@@ -142,40 +144,29 @@ func LoadPartyLikeness(ctx context.Context, pool *pgxpool.Pool, options PartyLik
 	if minCommon <= 0 {
 		minCommon = 10
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid kind %q", options.Kind)
+	}
 
-	cacheKey := fmt.Sprintf("analysis:party_likeness:%s:%s:%s:%d", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), minCommon)
+	cacheKey := fmt.Sprintf("analysis:party_likeness:%s:%s:%s:%d:%s", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), minCommon, kind)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		return copyPartyLikeness(cached.([]PartyLikeness)), nil
 	}
 
 	rows, err := pool.Query(ctx, `
-		WITH party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END)::int AS votes_for,
-			       SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)::int AS votes_against
-			FROM votes v
-			JOIN motions m ON m.motion_key = v.motion_key
+		WITH classified AS (
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN motions m ON m.motion_key = pp.motion_key
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
-			  AND v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.party_source_id IS NOT NULL
-			  AND v.vote_type IN ('Voor', 'Tegen')
+			  AND pp.position <> 'NEUTRAL'
 			  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
 			  AND ($3::timestamptz IS NULL OR m.proposed_at <= $3)
-			GROUP BY v.motion_key, v.party_source_id
-		),
-		classified AS (
-			SELECT motion_key,
-			       party_source_id,
-			       CASE
-			         WHEN votes_for > votes_against THEN 'FOR'
-			         WHEN votes_against > votes_for THEN 'AGAINST'
-			         ELSE 'NEUTRAL'
-			       END AS position
-			FROM party_positions
-			WHERE votes_for <> votes_against
+			  AND ($5::text = 'all' OR m.kind = $5)
 		),
 		pair_stats AS (
 			SELECT p1.party_source_id AS party1_source_id,
@@ -201,7 +192,7 @@ func LoadPartyLikeness(ctx context.Context, pool *pgxpool.Pool, options PartyLik
 		LEFT JOIN parties party2 ON party2.source_key = 'tweedekamer-odata-v2'
 		                         AND party2.source_id = ps.party2_source_id
 		ORDER BY similarity DESC, common_motions DESC, party1_name, party2_name
-	`, jurisdiction, options.DateFrom, options.DateTo, minCommon)
+	`, jurisdiction, options.DateFrom, options.DateTo, minCommon, kind)
 	if err != nil {
 		return nil, err
 	}
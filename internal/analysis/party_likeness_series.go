@@ -0,0 +1,256 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+)
+
+// PartyLikenessSeriesOptions splits LoadPartyLikeness into buckets of time so
+// two parties drifting apart show up. Party1SourceID and Party2SourceID pick
+// one pair; leaving both empty returns every pair.
+type PartyLikenessSeriesOptions struct {
+	Jurisdiction   string
+	Party1SourceID string
+	Party2SourceID string
+	DateFrom       *time.Time
+	DateTo         *time.Time
+	// MinCommon drops pairs with fewer common motions over the whole range;
+	// it defaults to 10, like the likeness matrix.
+	MinCommon int
+	Kind      string
+	// Interval is passed through NormalizeLikenessInterval.
+	Interval string
+}
+
+// PartyLikenessSeriesResult gives every series one point per bucket of
+// Buckets, in order, so all pairs share an x axis. A bucket the pair had no
+// common motions in has a zero point.
+type PartyLikenessSeriesResult struct {
+	Interval string
+	Buckets  []string
+	Series   []PartyLikenessSeries
+}
+
+type PartyLikenessSeries struct {
+	Party1SourceID string
+	Party1Name     string
+	Party2SourceID string
+	Party2Name     string
+	CommonMotions  int
+	Points         []PartyLikenessPoint
+}
+
+// PartyLikenessPoint keeps the motion count next to the similarity so a
+// chart can grey out buckets with too few motions to mean much.
+type PartyLikenessPoint struct {
+	Bucket        string
+	CommonMotions int
+	SameVotes     int
+	Similarity    float64
+}
+
+type likenessBucketRow struct {
+	Bucket         string
+	Party1SourceID string
+	Party1Name     string
+	Party2SourceID string
+	Party2Name     string
+	CommonMotions  int
+	SameVotes      int
+}
+
+// NormalizeLikenessInterval accepts month, quarter (the default) and year,
+// where a year is a parliamentary year (vergaderjaar) such as 2023-2024.
+func NormalizeLikenessInterval(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "quarter":
+		return "quarter", true
+	case "month":
+		return "month", true
+	case "year":
+		return "year", true
+	default:
+		return "", false
+	}
+}
+
+func LoadPartyLikenessSeries(ctx context.Context, pool *pgxpool.Pool, options PartyLikenessSeriesOptions) (PartyLikenessSeriesResult, error) {
+	jurisdiction := options.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	minCommon := options.MinCommon
+	if minCommon <= 0 {
+		minCommon = 10
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PartyLikenessSeriesResult{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+	interval, ok := NormalizeLikenessInterval(options.Interval)
+	if !ok {
+		return PartyLikenessSeriesResult{}, fmt.Errorf("invalid interval %q", options.Interval)
+	}
+	if (options.Party1SourceID == "") != (options.Party2SourceID == "") {
+		return PartyLikenessSeriesResult{}, fmt.Errorf("party1 and party2 must be given together")
+	}
+
+	cacheKey := fmt.Sprintf("analysis:party_likeness_series:%s:%s:%s:%s:%s:%d:%s:%s", jurisdiction, options.Party1SourceID, options.Party2SourceID, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), minCommon, kind, interval)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyPartyLikenessSeries(cached.(PartyLikenessSeriesResult)), nil
+	}
+
+	// Months and quarters follow the Dutch calendar, so a motion voted on
+	// late on the last evening of a month stays in that month.
+	rows, err := pool.Query(ctx, `
+		WITH classified AS (
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       pp.position,
+			       CASE $6::text
+			         WHEN 'month' THEN to_char(m.proposed_at AT TIME ZONE 'Europe/Amsterdam', 'YYYY-MM')
+			         WHEN 'quarter' THEN to_char(m.proposed_at AT TIME ZONE 'Europe/Amsterdam', 'YYYY-"Q"Q')
+			         ELSE m.parliamentary_year
+			       END AS bucket
+			FROM motion_party_positions pp
+			JOIN motions m ON m.motion_key = pp.motion_key
+			WHERE m.jurisdiction_key = $1
+			  AND m.source_deleted = false
+			  AND pp.position <> 'NEUTRAL'
+			  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
+			  AND ($3::timestamptz IS NULL OR m.proposed_at <= $3)
+			  AND ($5::text = 'all' OR m.kind = $5)
+			  AND ($7::text = '' OR pp.party_source_id IN ($7, $8))
+		),
+		bucket_stats AS (
+			SELECT p1.bucket,
+			       p1.party_source_id AS party1_source_id,
+			       p2.party_source_id AS party2_source_id,
+			       COUNT(*)::int AS common_motions,
+			       SUM(CASE WHEN p1.position = p2.position THEN 1 ELSE 0 END)::int AS same_votes,
+			       SUM(COUNT(*)) OVER (PARTITION BY p1.party_source_id, p2.party_source_id) AS pair_motions
+			FROM classified p1
+			JOIN classified p2 ON p1.motion_key = p2.motion_key
+			                  AND p1.party_source_id < p2.party_source_id
+			WHERE p1.bucket IS NOT NULL
+			GROUP BY p1.bucket, p1.party_source_id, p2.party_source_id
+		)
+		SELECT bs.bucket,
+		       bs.party1_source_id,
+		       COALESCE(party1.short_name, bs.party1_source_id) AS party1_name,
+		       bs.party2_source_id,
+		       COALESCE(party2.short_name, bs.party2_source_id) AS party2_name,
+		       bs.common_motions,
+		       bs.same_votes
+		FROM bucket_stats bs
+		LEFT JOIN parties party1 ON party1.source_key = 'tweedekamer-odata-v2'
+		                         AND party1.source_id = bs.party1_source_id
+		LEFT JOIN parties party2 ON party2.source_key = 'tweedekamer-odata-v2'
+		                         AND party2.source_id = bs.party2_source_id
+		WHERE bs.pair_motions >= $4
+	`, jurisdiction, options.DateFrom, options.DateTo, minCommon, kind, interval, options.Party1SourceID, options.Party2SourceID)
+	if err != nil {
+		return PartyLikenessSeriesResult{}, err
+	}
+	defer rows.Close()
+
+	bucketRows := []likenessBucketRow{}
+	for rows.Next() {
+		var row likenessBucketRow
+		if err := rows.Scan(&row.Bucket, &row.Party1SourceID, &row.Party1Name, &row.Party2SourceID, &row.Party2Name, &row.CommonMotions, &row.SameVotes); err != nil {
+			return PartyLikenessSeriesResult{}, err
+		}
+		// The query pairs parties in source id order; turn the pair asked
+		// for around so it reads the way it was requested.
+		if row.Party1SourceID == options.Party2SourceID && row.Party2SourceID == options.Party1SourceID {
+			row.Party1SourceID, row.Party2SourceID = row.Party2SourceID, row.Party1SourceID
+			row.Party1Name, row.Party2Name = row.Party2Name, row.Party1Name
+		}
+		bucketRows = append(bucketRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return PartyLikenessSeriesResult{}, err
+	}
+
+	result := buildLikenessSeries(bucketRows)
+	result.Interval = interval
+	cache.Global().Set(cacheKey, result)
+	return copyPartyLikenessSeries(result), nil
+}
+
+// buildLikenessSeries lays the bucket rows out on the buckets any pair voted
+// in. Bucket labels (2024-03, 2024-Q1, 2023-2024) sort in time order as
+// text. Series are ordered by party names.
+func buildLikenessSeries(rows []likenessBucketRow) PartyLikenessSeriesResult {
+	result := PartyLikenessSeriesResult{Buckets: []string{}, Series: []PartyLikenessSeries{}}
+
+	seenBuckets := map[string]bool{}
+	for _, row := range rows {
+		if !seenBuckets[row.Bucket] {
+			seenBuckets[row.Bucket] = true
+			result.Buckets = append(result.Buckets, row.Bucket)
+		}
+	}
+	sort.Strings(result.Buckets)
+	bucketIndex := map[string]int{}
+	for index, bucket := range result.Buckets {
+		bucketIndex[bucket] = index
+	}
+
+	seriesIndex := map[[2]string]int{}
+	for _, row := range rows {
+		key := [2]string{row.Party1SourceID, row.Party2SourceID}
+		index, ok := seriesIndex[key]
+		if !ok {
+			index = len(result.Series)
+			seriesIndex[key] = index
+			points := make([]PartyLikenessPoint, len(result.Buckets))
+			for bucket := range points {
+				points[bucket].Bucket = result.Buckets[bucket]
+			}
+			result.Series = append(result.Series, PartyLikenessSeries{
+				Party1SourceID: row.Party1SourceID,
+				Party1Name:     row.Party1Name,
+				Party2SourceID: row.Party2SourceID,
+				Party2Name:     row.Party2Name,
+				Points:         points,
+			})
+		}
+		series := &result.Series[index]
+		point := &series.Points[bucketIndex[row.Bucket]]
+		point.CommonMotions = row.CommonMotions
+		point.SameVotes = row.SameVotes
+		if row.CommonMotions > 0 {
+			point.Similarity = float64(row.SameVotes) / float64(row.CommonMotions) * 100
+		}
+		series.CommonMotions += row.CommonMotions
+	}
+
+	sort.Slice(result.Series, func(i, j int) bool {
+		left, right := result.Series[i], result.Series[j]
+		if left.Party1Name != right.Party1Name {
+			return left.Party1Name < right.Party1Name
+		}
+		return left.Party2Name < right.Party2Name
+	})
+	return result
+}
+
+func copyPartyLikenessSeries(src PartyLikenessSeriesResult) PartyLikenessSeriesResult {
+	out := src
+	out.Buckets = make([]string, len(src.Buckets))
+	copy(out.Buckets, src.Buckets)
+	out.Series = make([]PartyLikenessSeries, len(src.Series))
+	for index, series := range src.Series {
+		series.Points = append([]PartyLikenessPoint(nil), series.Points...)
+		out.Series[index] = series
+	}
+	return out
+}
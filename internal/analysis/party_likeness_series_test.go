@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildLikenessSeriesSharesBuckets(t *testing.T) {
+	result := buildLikenessSeries([]likenessBucketRow{
+		{Bucket: "2024-Q2", Party1SourceID: "vvd", Party1Name: "VVD", Party2SourceID: "pvv", Party2Name: "PVV", CommonMotions: 4, SameVotes: 1},
+		{Bucket: "2024-Q1", Party1SourceID: "vvd", Party1Name: "VVD", Party2SourceID: "pvv", Party2Name: "PVV", CommonMotions: 10, SameVotes: 8},
+		{Bucket: "2023-Q4", Party1SourceID: "d66", Party1Name: "D66", Party2SourceID: "gl", Party2Name: "GL-PvdA", CommonMotions: 20, SameVotes: 19},
+	})
+
+	if want := []string{"2023-Q4", "2024-Q1", "2024-Q2"}; !reflect.DeepEqual(result.Buckets, want) {
+		t.Fatalf("buckets = %v, want %v", result.Buckets, want)
+	}
+	if len(result.Series) != 2 || result.Series[0].Party1Name != "D66" || result.Series[1].Party1Name != "VVD" {
+		t.Fatalf("series = %+v, want D66 then VVD", result.Series)
+	}
+
+	vvd := result.Series[1]
+	if vvd.CommonMotions != 14 {
+		t.Fatalf("common motions = %d, want 14", vvd.CommonMotions)
+	}
+	want := []PartyLikenessPoint{
+		{Bucket: "2023-Q4"},
+		{Bucket: "2024-Q1", CommonMotions: 10, SameVotes: 8, Similarity: 80},
+		{Bucket: "2024-Q2", CommonMotions: 4, SameVotes: 1, Similarity: 25},
+	}
+	if !reflect.DeepEqual(vvd.Points, want) {
+		t.Fatalf("points = %+v, want %+v", vvd.Points, want)
+	}
+}
+
+func TestNormalizeLikenessInterval(t *testing.T) {
+	for input, want := range map[string]string{"": "quarter", "Month": "month", "quarter": "quarter", "year": "year"} {
+		if got, ok := NormalizeLikenessInterval(input); !ok || got != want {
+			t.Fatalf("NormalizeLikenessInterval(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+	if _, ok := NormalizeLikenessInterval("week"); ok {
+		t.Fatalf("NormalizeLikenessInterval(week) accepted")
+	}
+}
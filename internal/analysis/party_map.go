@@ -0,0 +1,440 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+)
+
+type PartyMapOptions struct {
+	Jurisdiction string
+	DateFrom     *time.Time
+	DateTo       *time.Time
+	Kind         string
+	// MinMotions drops fracties with fewer clear positions; it defaults to 20.
+	MinMotions int
+	// TopMotions is how many motions are listed per axis end; it defaults to 5.
+	TopMotions int
+}
+
+// PartyMap places the fracties on the first two principal components of their
+// positions: FOR is 1 and AGAINST -1, centred per motion, and a motion a
+// fractie took no clear position on counts as the average. Parties that vote
+// alike end up close together; the axes have no fixed meaning, which the
+// motions loading on them help to read.
+type PartyMap struct {
+	Parties []PartyMapPoint
+	// Axes holds the first and second component.
+	Axes []PartyMapAxis
+	// Motions is the number of motions in the matrix.
+	Motions int
+}
+
+type PartyMapPoint struct {
+	PartySourceID string
+	PartyName     string
+	X             float64
+	Y             float64
+	Motions       int
+}
+
+type PartyMapAxis struct {
+	// ExplainedVariance is the share of the variance the axis carries.
+	ExplainedVariance float64
+	// Positive and Negative list the motions loading most strongly towards
+	// either end of the axis.
+	Positive []PartyMapMotion
+	Negative []PartyMapMotion
+}
+
+type PartyMapMotion struct {
+	MotionKey string
+	Number    *string
+	Subject   *string
+	Loading   float64
+}
+
+// partyPositionRow is one fractie's clear position on a motion.
+type partyPositionRow struct {
+	MotionKey     string
+	PartySourceID string
+	PartyName     string
+	Position      string
+}
+
+func LoadPartyMap(ctx context.Context, pool *pgxpool.Pool, options PartyMapOptions) (PartyMap, error) {
+	jurisdiction := options.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	minMotions := options.MinMotions
+	if minMotions <= 0 {
+		minMotions = 20
+	}
+	topMotions := options.TopMotions
+	if topMotions <= 0 {
+		topMotions = 5
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PartyMap{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+
+	cacheKey := fmt.Sprintf("analysis:party_map:%s:%s:%s:%s:%d:%d", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), kind, minMotions, topMotions)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyPartyMap(cached.(PartyMap)), nil
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT pp.motion_key,
+		       pp.party_source_id,
+		       COALESCE(p.short_name, pp.party_name, pp.party_source_id) AS party_name,
+		       pp.position
+		FROM motion_party_positions pp
+		JOIN motions m ON m.motion_key = pp.motion_key
+		LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+		                   AND p.source_id = pp.party_source_id
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND pp.position <> 'NEUTRAL'
+		  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
+		  AND ($3::timestamptz IS NULL OR m.proposed_at <= $3)
+		  AND ($4::text = 'all' OR m.kind = $4)
+	`, jurisdiction, options.DateFrom, options.DateTo, kind)
+	if err != nil {
+		return PartyMap{}, err
+	}
+	defer rows.Close()
+
+	positions := []partyPositionRow{}
+	for rows.Next() {
+		var row partyPositionRow
+		if err := rows.Scan(&row.MotionKey, &row.PartySourceID, &row.PartyName, &row.Position); err != nil {
+			return PartyMap{}, err
+		}
+		positions = append(positions, row)
+	}
+	if err := rows.Err(); err != nil {
+		return PartyMap{}, err
+	}
+
+	result := projectPartyMap(positions, minMotions, topMotions)
+	if err := loadPartyMapMotions(ctx, pool, result.Axes); err != nil {
+		return PartyMap{}, err
+	}
+
+	cache.Global().Set(cacheKey, result)
+	return copyPartyMap(result), nil
+}
+
+// loadPartyMapMotions fills in the number and subject of the listed motions.
+func loadPartyMapMotions(ctx context.Context, pool *pgxpool.Pool, axes []PartyMapAxis) error {
+	motionKeys := []string{}
+	for _, axis := range axes {
+		for _, motion := range append(append([]PartyMapMotion{}, axis.Positive...), axis.Negative...) {
+			motionKeys = append(motionKeys, motion.MotionKey)
+		}
+	}
+	if len(motionKeys) == 0 {
+		return nil
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT motion_key, number, subject
+		FROM motions
+		WHERE motion_key = ANY($1::text[])
+	`, motionKeys)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type motionInfo struct {
+		number  *string
+		subject *string
+	}
+	info := map[string]motionInfo{}
+	for rows.Next() {
+		var motionKey string
+		var motion motionInfo
+		if err := rows.Scan(&motionKey, &motion.number, &motion.subject); err != nil {
+			return err
+		}
+		info[motionKey] = motion
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, axis := range axes {
+		for _, motions := range [][]PartyMapMotion{axis.Positive, axis.Negative} {
+			for index := range motions {
+				motion := info[motions[index].MotionKey]
+				motions[index].Number = motion.number
+				motions[index].Subject = motion.subject
+			}
+		}
+	}
+	return nil
+}
+
+// projectPartyMap runs the principal component analysis. Fracties with fewer
+// than minMotions positions are left out, as are motions fewer than two of the
+// remaining fracties took a position on.
+func projectPartyMap(positions []partyPositionRow, minMotions int, topMotions int) PartyMap {
+	result := PartyMap{Parties: []PartyMapPoint{}, Axes: []PartyMapAxis{}}
+
+	partyMotions := map[string]int{}
+	partyNames := map[string]string{}
+	for _, row := range positions {
+		partyMotions[row.PartySourceID]++
+		partyNames[row.PartySourceID] = row.PartyName
+	}
+	parties := []string{}
+	for partySourceID, count := range partyMotions {
+		if count >= minMotions {
+			parties = append(parties, partySourceID)
+		}
+	}
+	sort.Strings(parties)
+	partyIndex := map[string]int{}
+	for index, partySourceID := range parties {
+		partyIndex[partySourceID] = index
+	}
+
+	// Columns of the matrix, one per motion, with the votes of the kept
+	// fracties; NaN marks no position.
+	columns := map[string][]float64{}
+	for _, row := range positions {
+		index, ok := partyIndex[row.PartySourceID]
+		if !ok {
+			continue
+		}
+		column, ok := columns[row.MotionKey]
+		if !ok {
+			column = make([]float64, len(parties))
+			for party := range column {
+				column[party] = math.NaN()
+			}
+			columns[row.MotionKey] = column
+		}
+		if row.Position == "FOR" {
+			column[index] = 1
+		} else {
+			column[index] = -1
+		}
+	}
+
+	motionKeys := []string{}
+	for motionKey, column := range columns {
+		voted := 0
+		for _, value := range column {
+			if !math.IsNaN(value) {
+				voted++
+			}
+		}
+		if voted >= 2 {
+			motionKeys = append(motionKeys, motionKey)
+		}
+	}
+	sort.Strings(motionKeys)
+	result.Motions = len(motionKeys)
+	if len(parties) < 3 || len(motionKeys) == 0 {
+		return result
+	}
+
+	// Centre every motion on the mean of the fracties that took a position;
+	// the others get the mean, which is zero after centring.
+	matrix := make([][]float64, len(parties))
+	for party := range matrix {
+		matrix[party] = make([]float64, len(motionKeys))
+	}
+	for motion, motionKey := range motionKeys {
+		column := columns[motionKey]
+		sum, voted := 0.0, 0
+		for _, value := range column {
+			if !math.IsNaN(value) {
+				sum += value
+				voted++
+			}
+		}
+		mean := sum / float64(voted)
+		for party, value := range column {
+			if !math.IsNaN(value) {
+				matrix[party][motion] = value - mean
+			}
+		}
+	}
+
+	// The party × party Gram matrix shares its non-zero eigenvalues with the
+	// motion covariance and is small enough to decompose directly.
+	gram := make([][]float64, len(parties))
+	for left := range parties {
+		gram[left] = make([]float64, len(parties))
+		for right := range parties {
+			for motion := range motionKeys {
+				gram[left][right] += matrix[left][motion] * matrix[right][motion]
+			}
+		}
+	}
+	values, vectors := symmetricEigen(gram)
+
+	totalVariance := 0.0
+	for _, value := range values {
+		totalVariance += max(value, 0)
+	}
+	scores := make([][2]float64, len(parties))
+	for axis := 0; axis < 2 && axis < len(values); axis++ {
+		value := max(values[axis], 0)
+		scale := math.Sqrt(value)
+
+		// Orient the axis so that the fractie furthest from the centre is
+		// on the positive side; the sign of a component is arbitrary.
+		furthest := 0
+		for party := range parties {
+			if math.Abs(vectors[party][axis]) > math.Abs(vectors[furthest][axis]) {
+				furthest = party
+			}
+		}
+		sign := 1.0
+		if vectors[furthest][axis] < 0 {
+			sign = -1
+		}
+		for party := range parties {
+			scores[party][axis] = sign * vectors[party][axis] * scale
+		}
+
+		loadings := make([]PartyMapMotion, len(motionKeys))
+		for motion, motionKey := range motionKeys {
+			loading := 0.0
+			for party := range parties {
+				loading += matrix[party][motion] * sign * vectors[party][axis]
+			}
+			if scale > 0 {
+				loading /= scale
+			}
+			loadings[motion] = PartyMapMotion{MotionKey: motionKey, Loading: loading}
+		}
+		sort.SliceStable(loadings, func(i, j int) bool { return loadings[i].Loading > loadings[j].Loading })
+
+		mapAxis := PartyMapAxis{Positive: []PartyMapMotion{}, Negative: []PartyMapMotion{}}
+		if totalVariance > 0 {
+			mapAxis.ExplainedVariance = value / totalVariance
+		}
+		for index := 0; index < topMotions && index < len(loadings) && loadings[index].Loading > 0; index++ {
+			mapAxis.Positive = append(mapAxis.Positive, loadings[index])
+		}
+		for index := len(loadings) - 1; index >= 0 && len(loadings)-1-index < topMotions && loadings[index].Loading < 0; index-- {
+			mapAxis.Negative = append(mapAxis.Negative, loadings[index])
+		}
+		result.Axes = append(result.Axes, mapAxis)
+	}
+
+	for index, partySourceID := range parties {
+		result.Parties = append(result.Parties, PartyMapPoint{
+			PartySourceID: partySourceID,
+			PartyName:     partyNames[partySourceID],
+			X:             scores[index][0],
+			Y:             scores[index][1],
+			Motions:       partyMotions[partySourceID],
+		})
+	}
+	sort.Slice(result.Parties, func(i, j int) bool { return result.Parties[i].PartyName < result.Parties[j].PartyName })
+	return result
+}
+
+// symmetricEigen decomposes a symmetric matrix with the cyclic Jacobi method.
+// It returns the eigenvalues in descending order and the eigenvectors as the
+// matching columns of the second result.
+func symmetricEigen(matrix [][]float64) ([]float64, [][]float64) {
+	size := len(matrix)
+	a := make([][]float64, size)
+	vectors := make([][]float64, size)
+	for row := range matrix {
+		a[row] = append([]float64{}, matrix[row]...)
+		vectors[row] = make([]float64, size)
+		vectors[row][row] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		offDiagonal := 0.0
+		for row := 0; row < size; row++ {
+			for column := row + 1; column < size; column++ {
+				offDiagonal += a[row][column] * a[row][column]
+			}
+		}
+		if offDiagonal < 1e-20 {
+			break
+		}
+
+		for p := 0; p < size; p++ {
+			for q := p + 1; q < size; q++ {
+				if math.Abs(a[p][q]) < 1e-300 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < size; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < size; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < size; k++ {
+					vkp, vkq := vectors[k][p], vectors[k][q]
+					vectors[k][p] = c*vkp - s*vkq
+					vectors[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	order := make([]int, size)
+	for index := range order {
+		order[index] = index
+	}
+	sort.SliceStable(order, func(i, j int) bool { return a[order[i]][order[i]] > a[order[j]][order[j]] })
+
+	values := make([]float64, size)
+	sorted := make([][]float64, size)
+	for row := range sorted {
+		sorted[row] = make([]float64, size)
+	}
+	for column, index := range order {
+		values[column] = a[index][index]
+		for row := 0; row < size; row++ {
+			sorted[row][column] = vectors[row][index]
+		}
+	}
+	return values, sorted
+}
+
+func copyPartyMap(src PartyMap) PartyMap {
+	out := PartyMap{
+		Parties: make([]PartyMapPoint, len(src.Parties)),
+		Axes:    make([]PartyMapAxis, len(src.Axes)),
+		Motions: src.Motions,
+	}
+	copy(out.Parties, src.Parties)
+	for index, axis := range src.Axes {
+		out.Axes[index] = PartyMapAxis{
+			ExplainedVariance: axis.ExplainedVariance,
+			Positive:          append([]PartyMapMotion{}, axis.Positive...),
+			Negative:          append([]PartyMapMotion{}, axis.Negative...),
+		}
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSymmetricEigen(t *testing.T) {
+	values, vectors := symmetricEigen([][]float64{{2, 1}, {1, 2}})
+	if math.Abs(values[0]-3) > 1e-9 || math.Abs(values[1]-1) > 1e-9 {
+		t.Fatalf("values = %v, want [3 1]", values)
+	}
+	// The leading eigenvector of [[2 1] [1 2]] is (1, 1) / sqrt(2), up to sign.
+	if math.Abs(math.Abs(vectors[0][0])-math.Sqrt(0.5)) > 1e-9 || math.Abs(vectors[0][0]-vectors[1][0]) > 1e-9 {
+		t.Fatalf("leading vector = (%v, %v), want (1, 1) / sqrt(2)", vectors[0][0], vectors[1][0])
+	}
+}
+
+func TestProjectPartyMapSeparatesBlocs(t *testing.T) {
+	positions := []partyPositionRow{}
+	add := func(motion string, party string, position string) {
+		positions = append(positions, partyPositionRow{MotionKey: motion, PartySourceID: party, PartyName: party, Position: position})
+	}
+	// Left and right disagree on every motion; the centre sides with the
+	// left on the even motions and with the right on the odd ones.
+	for index := 0; index < 20; index++ {
+		motion := fmt.Sprintf("m%02d", index)
+		add(motion, "gl", "FOR")
+		add(motion, "sp", "FOR")
+		add(motion, "vvd", "AGAINST")
+		add(motion, "pvv", "AGAINST")
+		if index%2 == 0 {
+			add(motion, "d66", "FOR")
+		} else {
+			add(motion, "d66", "AGAINST")
+		}
+	}
+	// A party below the minimum is left out.
+	add("m00", "bbb", "FOR")
+
+	result := projectPartyMap(positions, 10, 3)
+
+	if len(result.Parties) != 5 || result.Motions != 20 {
+		t.Fatalf("got %d parties over %d motions, want 5 over 20", len(result.Parties), result.Motions)
+	}
+	x := map[string]float64{}
+	for _, party := range result.Parties {
+		x[party.PartySourceID] = party.X
+	}
+	if math.Abs(x["gl"]-x["sp"]) > 1e-9 || math.Abs(x["vvd"]-x["pvv"]) > 1e-9 {
+		t.Fatalf("parties that vote alike should coincide: %v", x)
+	}
+	if x["gl"]*x["vvd"] >= 0 {
+		t.Fatalf("left and right should be on opposite sides of the first axis: %v", x)
+	}
+	if math.Abs(x["d66"]) >= math.Abs(x["gl"]) {
+		t.Fatalf("the centre should lie between the blocs: %v", x)
+	}
+
+	if len(result.Axes) != 2 {
+		t.Fatalf("len(Axes) = %d, want 2", len(result.Axes))
+	}
+	if variance := result.Axes[0].ExplainedVariance; variance < 0.5 || variance > 1 {
+		t.Fatalf("first axis explains %v, want the larger part", variance)
+	}
+	if len(result.Axes[0].Positive)+len(result.Axes[0].Negative) == 0 {
+		t.Fatal("first axis lists no motions")
+	}
+}
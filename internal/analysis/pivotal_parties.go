@@ -0,0 +1,272 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+type PivotalPartyOptions struct {
+	Period CabinetPeriod
+	Kind   string
+	// Limit caps CoalitionDefeats; it defaults to 10.
+	Limit int
+}
+
+// PivotalParties counts, per fractie, the votes it decided: those where
+// moving its seats on the winning side over would have flipped the outcome,
+// by the seat-weighted tally of the decision. A motion voted on twice counts
+// twice.
+type PivotalParties struct {
+	Decisions int
+	Adopted   int
+	// CoalitionDefeated counts the decisions that went against the seat
+	// majority of the coalition parties.
+	CoalitionDefeated int
+	Parties           []PivotalPartyStats
+	// CoalitionDefeats are the latest decisions the coalition lost, with the
+	// fracties that decided them.
+	CoalitionDefeats []PivotalDecision
+}
+
+type PivotalPartyStats struct {
+	PartySourceID  string
+	PartyName      string
+	CoalitionParty bool
+	Decisions      int
+	// Winning counts decisions where most of the fractie's seats were on the
+	// winning side.
+	Winning int
+	Pivotal int
+	// SolePivotal counts decisions no other fractie could have flipped on
+	// its own: the kingmaker votes.
+	SolePivotal int
+	// AgainstCoalition counts pivotal votes on decisions the coalition lost.
+	AgainstCoalition int
+}
+
+type PivotalDecision struct {
+	MotionKey      string
+	DecisionKey    string
+	Number         *string
+	Subject        *string
+	ProposedAt     *time.Time
+	SeatsFor       int
+	SeatsAgainst   int
+	Adopted        bool
+	PivotalParties []string
+}
+
+// pivotalVoteRow is one fractie's seats on one decision.
+type pivotalVoteRow struct {
+	DecisionKey    string
+	MotionKey      string
+	Number         *string
+	Subject        *string
+	ProposedAt     *time.Time
+	PartySourceID  string
+	PartyName      string
+	CoalitionParty bool
+	SeatsFor       int
+	SeatsAgainst   int
+}
+
+func LoadPivotalParties(ctx context.Context, pool *pgxpool.Pool, options PivotalPartyOptions) (PivotalParties, error) {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return PivotalParties{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+
+	cacheKey := fmt.Sprintf("analysis:pivotal_parties:%s:%s:%d", options.Period.PeriodKey, kind, limit)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyPivotalParties(cached.(PivotalParties)), nil
+	}
+
+	// Rows come ordered by decision, latest first, which computePivotalParties
+	// relies on to group them.
+	rows, err := pool.Query(ctx, `
+		SELECT v.decision_key,
+		       v.motion_key,
+		       m.number,
+		       m.subject,
+		       m.proposed_at,
+		       v.party_source_id,
+		       COALESCE(min(p.short_name), min(v.party_name), v.party_source_id) AS party_name,
+		       upper(COALESCE(min(p.short_name), min(v.party_name), v.party_source_id)) = ANY($4::text[]) AS coalition_party,
+		       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Voor'), 0)::int AS seats_for,
+		       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int AS seats_against
+		FROM votes v
+		JOIN motions m ON m.motion_key = v.motion_key
+		JOIN decisions d ON d.decision_key = v.decision_key
+		LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+		                   AND p.source_id = v.party_source_id
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND m.proposed_at >= $2
+		  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+		  AND ($5::text = 'all' OR m.kind = $5)
+		  AND d.source_deleted = false
+		  AND v.source_deleted = false
+		  AND v.mistake = false
+		  AND v.party_source_id IS NOT NULL
+		  AND v.vote_type IN ('Voor', 'Tegen')
+		GROUP BY v.decision_key, v.motion_key, m.number, m.subject, m.proposed_at, v.party_source_id
+		ORDER BY m.proposed_at DESC NULLS LAST, v.motion_key, v.decision_key
+	`, options.Period.Jurisdiction, options.Period.StartedOn, options.Period.EndedOn, normalizedPartyNames(options.Period.Parties), kind)
+	if err != nil {
+		return PivotalParties{}, err
+	}
+	defer rows.Close()
+
+	votes := []pivotalVoteRow{}
+	for rows.Next() {
+		var row pivotalVoteRow
+		if err := rows.Scan(
+			&row.DecisionKey,
+			&row.MotionKey,
+			&row.Number,
+			&row.Subject,
+			&row.ProposedAt,
+			&row.PartySourceID,
+			&row.PartyName,
+			&row.CoalitionParty,
+			&row.SeatsFor,
+			&row.SeatsAgainst,
+		); err != nil {
+			return PivotalParties{}, err
+		}
+		votes = append(votes, row)
+	}
+	if err := rows.Err(); err != nil {
+		return PivotalParties{}, err
+	}
+
+	result := computePivotalParties(votes)
+	if len(result.CoalitionDefeats) > limit {
+		result.CoalitionDefeats = result.CoalitionDefeats[:limit]
+	}
+
+	cache.Global().Set(cacheKey, result)
+	return copyPivotalParties(result), nil
+}
+
+// computePivotalParties expects the rows of one decision to be adjacent and
+// keeps their order for CoalitionDefeats. Fracties are ranked by pivotal
+// votes.
+func computePivotalParties(votes []pivotalVoteRow) PivotalParties {
+	result := PivotalParties{Parties: []PivotalPartyStats{}, CoalitionDefeats: []PivotalDecision{}}
+	stats := map[string]*PivotalPartyStats{}
+
+	for start := 0; start < len(votes); {
+		end := start + 1
+		for end < len(votes) && votes[end].DecisionKey == votes[start].DecisionKey {
+			end++
+		}
+		decisionVotes := votes[start:end]
+		start = end
+
+		seatsFor, seatsAgainst := 0, 0
+		coalitionFor, coalitionAgainst := 0, 0
+		for _, vote := range decisionVotes {
+			seatsFor += vote.SeatsFor
+			seatsAgainst += vote.SeatsAgainst
+			if vote.CoalitionParty {
+				coalitionFor += vote.SeatsFor
+				coalitionAgainst += vote.SeatsAgainst
+			}
+		}
+		adopted := politics.Carried(seatsFor, seatsAgainst)
+		coalitionPosition := politics.PartyPosition(coalitionFor, coalitionAgainst)
+		coalitionDefeated := (coalitionPosition == politics.PositionFor && !adopted) ||
+			(coalitionPosition == politics.PositionAgainst && adopted)
+
+		result.Decisions++
+		if adopted {
+			result.Adopted++
+		}
+		if coalitionDefeated {
+			result.CoalitionDefeated++
+		}
+
+		pivotal := []*PivotalPartyStats{}
+		pivotalNames := []string{}
+		for _, vote := range decisionVotes {
+			party, ok := stats[vote.PartySourceID]
+			if !ok {
+				party = &PivotalPartyStats{PartySourceID: vote.PartySourceID, PartyName: vote.PartyName, CoalitionParty: vote.CoalitionParty}
+				stats[vote.PartySourceID] = party
+			}
+			party.Decisions++
+			if (adopted && vote.SeatsFor > vote.SeatsAgainst) || (!adopted && vote.SeatsAgainst > vote.SeatsFor) {
+				party.Winning++
+			}
+			if politics.Pivotal(seatsFor, seatsAgainst, vote.SeatsFor, vote.SeatsAgainst) {
+				party.Pivotal++
+				if coalitionDefeated {
+					party.AgainstCoalition++
+				}
+				pivotal = append(pivotal, party)
+				pivotalNames = append(pivotalNames, vote.PartyName)
+			}
+		}
+		if len(pivotal) == 1 {
+			pivotal[0].SolePivotal++
+		}
+
+		if coalitionDefeated {
+			sort.Strings(pivotalNames)
+			first := decisionVotes[0]
+			result.CoalitionDefeats = append(result.CoalitionDefeats, PivotalDecision{
+				MotionKey:      first.MotionKey,
+				DecisionKey:    first.DecisionKey,
+				Number:         first.Number,
+				Subject:        first.Subject,
+				ProposedAt:     first.ProposedAt,
+				SeatsFor:       seatsFor,
+				SeatsAgainst:   seatsAgainst,
+				Adopted:        adopted,
+				PivotalParties: pivotalNames,
+			})
+		}
+	}
+
+	for _, party := range stats {
+		result.Parties = append(result.Parties, *party)
+	}
+	sort.Slice(result.Parties, func(i, j int) bool {
+		left, right := result.Parties[i], result.Parties[j]
+		if left.Pivotal != right.Pivotal {
+			return left.Pivotal > right.Pivotal
+		}
+		if left.AgainstCoalition != right.AgainstCoalition {
+			return left.AgainstCoalition > right.AgainstCoalition
+		}
+		return left.PartyName < right.PartyName
+	})
+	return result
+}
+
+func copyPivotalParties(src PivotalParties) PivotalParties {
+	out := src
+	out.Parties = make([]PivotalPartyStats, len(src.Parties))
+	copy(out.Parties, src.Parties)
+	out.CoalitionDefeats = make([]PivotalDecision, len(src.CoalitionDefeats))
+	for index, decision := range src.CoalitionDefeats {
+		decision.PivotalParties = append([]string(nil), decision.PivotalParties...)
+		out.CoalitionDefeats[index] = decision
+	}
+	return out
+}
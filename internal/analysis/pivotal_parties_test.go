@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputePivotalParties(t *testing.T) {
+	vote := func(decisionKey string, party string, coalition bool, seatsFor int, seatsAgainst int) pivotalVoteRow {
+		return pivotalVoteRow{
+			DecisionKey:    decisionKey,
+			MotionKey:      "motion-" + decisionKey,
+			PartySourceID:  party,
+			PartyName:      party,
+			CoalitionParty: coalition,
+			SeatsFor:       seatsFor,
+			SeatsAgainst:   seatsAgainst,
+		}
+	}
+
+	result := computePivotalParties([]pivotalVoteRow{
+		// The coalition (a, b) votes for and loses 70-75; each opposition
+		// fractie on the winning side could have flipped it.
+		vote("d1", "a", true, 40, 0),
+		vote("d1", "b", true, 30, 0),
+		vote("d1", "c", false, 0, 35),
+		vote("d1", "d", false, 0, 30),
+		vote("d1", "e", false, 0, 10),
+		// Adopted 85-60: only a and c carry more than half the margin.
+		vote("d2", "a", true, 40, 0),
+		vote("d2", "b", true, 0, 30),
+		vote("d2", "c", false, 35, 0),
+		vote("d2", "d", false, 0, 30),
+		vote("d2", "e", false, 10, 0),
+		// Adopted 50-0 with a as the only fractie that could flip it.
+		vote("d3", "a", true, 40, 0),
+		vote("d3", "e", false, 10, 0),
+	})
+
+	if result.Decisions != 3 || result.Adopted != 2 || result.CoalitionDefeated != 1 {
+		t.Fatalf("decisions = %d, adopted = %d, defeated = %d, want 3, 2, 1", result.Decisions, result.Adopted, result.CoalitionDefeated)
+	}
+	want := []PivotalPartyStats{
+		{PartySourceID: "c", PartyName: "c", Decisions: 2, Winning: 2, Pivotal: 2, AgainstCoalition: 1},
+		{PartySourceID: "a", PartyName: "a", CoalitionParty: true, Decisions: 3, Winning: 2, Pivotal: 2, SolePivotal: 1},
+		{PartySourceID: "d", PartyName: "d", Decisions: 2, Winning: 1, Pivotal: 1, AgainstCoalition: 1},
+		{PartySourceID: "e", PartyName: "e", Decisions: 3, Winning: 3, Pivotal: 1, AgainstCoalition: 1},
+		{PartySourceID: "b", PartyName: "b", CoalitionParty: true, Decisions: 2},
+	}
+	if !reflect.DeepEqual(result.Parties, want) {
+		t.Fatalf("parties = %+v\nwant %+v", result.Parties, want)
+	}
+	if len(result.CoalitionDefeats) != 1 {
+		t.Fatalf("coalition defeats = %+v, want d1", result.CoalitionDefeats)
+	}
+	defeat := result.CoalitionDefeats[0]
+	if defeat.DecisionKey != "d1" || defeat.Adopted || defeat.SeatsFor != 70 || defeat.SeatsAgainst != 75 || !reflect.DeepEqual(defeat.PivotalParties, []string{"c", "d", "e"}) {
+		t.Fatalf("defeat = %+v, want d1 rejected 70-75 decided by c, d and e", defeat)
+	}
+}
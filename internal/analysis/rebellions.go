@@ -0,0 +1,263 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+type RebellionOptions struct {
+	Jurisdiction string
+	DateFrom     *time.Time
+	DateTo       *time.Time
+	Category     string
+	Kind         string
+	Limit        int
+}
+
+// Rebellions gives the rate at which members voted against their own fractie
+// on hoofdelijke stemmingen, and lists those votes. Only those votes carry a
+// person; a fractie-wide vote cannot deviate from itself.
+type Rebellions struct {
+	Members []MemberRebellionStats
+	Votes   []Rebellion
+}
+
+type MemberRebellionStats struct {
+	PersonSourceID string
+	MemberName     string
+	PartySourceID  string
+	PartyName      string
+	VotesCast      int
+	Rebellions     int
+	RebellionRate  float64
+}
+
+type Rebellion struct {
+	MotionKey      string
+	DecisionKey    string
+	Number         *string
+	Subject        *string
+	ProposedAt     *time.Time
+	PersonSourceID string
+	MemberName     string
+	PartySourceID  string
+	PartyName      string
+	MemberVote     politics.Position
+	PartyPosition  politics.Position
+}
+
+// memberVoteRow is one member's Voor/Tegen on a hoofdelijke decision.
+type memberVoteRow struct {
+	MotionKey      string
+	DecisionKey    string
+	Number         *string
+	Subject        *string
+	ProposedAt     *time.Time
+	PersonSourceID string
+	MemberName     string
+	PartySourceID  string
+	PartyName      string
+	VoteType       string
+}
+
+func LoadRebellions(ctx context.Context, pool *pgxpool.Pool, options RebellionOptions) (Rebellions, error) {
+	jurisdiction := options.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return Rebellions{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+
+	cacheKey := fmt.Sprintf("analysis:rebellions:%s:%s:%s:%s:%s:%d", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), options.Category, kind, limit)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyRebellions(cached.(Rebellions)), nil
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT v.motion_key,
+		       v.decision_key,
+		       m.number,
+		       m.subject,
+		       m.proposed_at,
+		       v.person_source_id,
+		       COALESCE(mb.full_name, v.actor_name, v.person_source_id) AS member_name,
+		       v.party_source_id,
+		       COALESCE(p.short_name, v.party_name, v.party_source_id) AS party_name,
+		       v.vote_type
+		FROM votes v
+		JOIN motions m ON m.motion_key = v.motion_key
+		LEFT JOIN members mb ON mb.source_key = v.source_key
+		                    AND mb.source_id = v.person_source_id
+		LEFT JOIN parties p ON p.source_key = v.source_key
+		                   AND p.source_id = v.party_source_id
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND v.source_deleted = false
+		  AND v.mistake = false
+		  AND v.person_source_id IS NOT NULL
+		  AND v.party_source_id IS NOT NULL
+		  AND v.vote_type IN ('Voor', 'Tegen')
+		  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
+		  AND ($3::timestamptz IS NULL OR m.proposed_at <= $3)
+		  AND ($4::text = '' OR EXISTS (
+		        SELECT 1 FROM motion_categories mc
+		        WHERE mc.motion_key = m.motion_key
+		          AND mc.category_key = $4))
+		  AND ($5::text = 'all' OR m.kind = $5)
+		ORDER BY m.proposed_at DESC NULLS LAST, v.motion_key, v.decision_key
+	`, jurisdiction, options.DateFrom, options.DateTo, options.Category, kind)
+	if err != nil {
+		return Rebellions{}, err
+	}
+	defer rows.Close()
+
+	votes := []memberVoteRow{}
+	for rows.Next() {
+		var row memberVoteRow
+		if err := rows.Scan(
+			&row.MotionKey,
+			&row.DecisionKey,
+			&row.Number,
+			&row.Subject,
+			&row.ProposedAt,
+			&row.PersonSourceID,
+			&row.MemberName,
+			&row.PartySourceID,
+			&row.PartyName,
+			&row.VoteType,
+		); err != nil {
+			return Rebellions{}, err
+		}
+		votes = append(votes, row)
+	}
+	if err := rows.Err(); err != nil {
+		return Rebellions{}, err
+	}
+
+	result := computeRebellions(votes)
+	if len(result.Votes) > limit {
+		result.Votes = result.Votes[:limit]
+	}
+
+	cache.Global().Set(cacheKey, result)
+	return copyRebellions(result), nil
+}
+
+// computeRebellions derives each fractie's position per decision from its
+// members' votes and flags the members on the other side. A tied fractie has
+// no position to rebel against, so its members count neither way. Votes keep
+// the input order. Members has every member with a counted vote, the loyal
+// ones too, once per fractie they voted in, ranked by rebellions, then by rate.
+func computeRebellions(votes []memberVoteRow) Rebellions {
+	type decisionParty struct {
+		decisionKey   string
+		partySourceID string
+	}
+	type memberParty struct {
+		personSourceID string
+		partySourceID  string
+	}
+	tallies := map[decisionParty][2]int{}
+	for _, vote := range votes {
+		key := decisionParty{vote.DecisionKey, vote.PartySourceID}
+		tally := tallies[key]
+		if vote.VoteType == "Voor" {
+			tally[0]++
+		} else {
+			tally[1]++
+		}
+		tallies[key] = tally
+	}
+
+	members := map[memberParty]*MemberRebellionStats{}
+	result := Rebellions{Members: []MemberRebellionStats{}, Votes: []Rebellion{}}
+	for _, vote := range votes {
+		tally := tallies[decisionParty{vote.DecisionKey, vote.PartySourceID}]
+		partyPosition := politics.PartyPosition(tally[0], tally[1])
+		if partyPosition == politics.PositionNeutral {
+			continue
+		}
+
+		memberKey := memberParty{vote.PersonSourceID, vote.PartySourceID}
+		stats, ok := members[memberKey]
+		if !ok {
+			stats = &MemberRebellionStats{
+				PersonSourceID: vote.PersonSourceID,
+				MemberName:     vote.MemberName,
+				PartySourceID:  vote.PartySourceID,
+				PartyName:      vote.PartyName,
+			}
+			members[memberKey] = stats
+		}
+		stats.VotesCast++
+
+		memberVote := politics.PositionFor
+		if vote.VoteType != "Voor" {
+			memberVote = politics.PositionAgainst
+		}
+		if memberVote == partyPosition {
+			continue
+		}
+
+		stats.Rebellions++
+		result.Votes = append(result.Votes, Rebellion{
+			MotionKey:      vote.MotionKey,
+			DecisionKey:    vote.DecisionKey,
+			Number:         vote.Number,
+			Subject:        vote.Subject,
+			ProposedAt:     vote.ProposedAt,
+			PersonSourceID: vote.PersonSourceID,
+			MemberName:     vote.MemberName,
+			PartySourceID:  vote.PartySourceID,
+			PartyName:      vote.PartyName,
+			MemberVote:     memberVote,
+			PartyPosition:  partyPosition,
+		})
+	}
+
+	for _, stats := range members {
+		stats.RebellionRate = (float64(stats.Rebellions) / float64(stats.VotesCast)) * 100
+		result.Members = append(result.Members, *stats)
+	}
+	sort.Slice(result.Members, func(i, j int) bool {
+		left, right := result.Members[i], result.Members[j]
+		if left.Rebellions != right.Rebellions {
+			return left.Rebellions > right.Rebellions
+		}
+		if left.RebellionRate != right.RebellionRate {
+			return left.RebellionRate > right.RebellionRate
+		}
+		if left.MemberName != right.MemberName {
+			return left.MemberName < right.MemberName
+		}
+		return left.PartyName < right.PartyName
+	})
+
+	return result
+}
+
+func copyRebellions(src Rebellions) Rebellions {
+	out := Rebellions{
+		Members: make([]MemberRebellionStats, len(src.Members)),
+		Votes:   make([]Rebellion, len(src.Votes)),
+	}
+	copy(out.Members, src.Members)
+	copy(out.Votes, src.Votes)
+	return out
+}
@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"testing"
+
+	"partijgedrag/internal/politics"
+)
+
+func TestComputeRebellions(t *testing.T) {
+	vote := func(decision string, person string, party string, voteType string) memberVoteRow {
+		return memberVoteRow{
+			MotionKey:      "motion-" + decision,
+			DecisionKey:    decision,
+			PersonSourceID: person,
+			MemberName:     person,
+			PartySourceID:  party,
+			PartyName:      party,
+			VoteType:       voteType,
+		}
+	}
+
+	result := computeRebellions([]memberVoteRow{
+		// VVD votes Voor 2-1: anna rebels.
+		vote("d1", "anna", "vvd", "Tegen"),
+		vote("d1", "bram", "vvd", "Voor"),
+		vote("d1", "cees", "vvd", "Voor"),
+		// D66 ties 1-1: no position, so nobody rebels.
+		vote("d1", "dirk", "d66", "Voor"),
+		vote("d1", "eva", "d66", "Tegen"),
+		// VVD unanimous Tegen.
+		vote("d2", "anna", "vvd", "Tegen"),
+		vote("d2", "bram", "vvd", "Tegen"),
+		vote("d2", "cees", "vvd", "Tegen"),
+	})
+
+	if len(result.Votes) != 1 {
+		t.Fatalf("len(Votes) = %d, want 1", len(result.Votes))
+	}
+	rebellion := result.Votes[0]
+	if rebellion.PersonSourceID != "anna" || rebellion.MemberVote != politics.PositionAgainst || rebellion.PartyPosition != politics.PositionFor {
+		t.Fatalf("unexpected rebellion %+v", rebellion)
+	}
+
+	// Every member with a counted vote has a rate, the loyal ones too; eva
+	// and dirk only voted in a tied fractie.
+	if len(result.Members) != 3 {
+		t.Fatalf("len(Members) = %d, want 3", len(result.Members))
+	}
+	stats := result.Members[0]
+	if stats.PersonSourceID != "anna" || stats.VotesCast != 2 || stats.Rebellions != 1 || stats.RebellionRate != 50 {
+		t.Fatalf("unexpected member stats %+v", stats)
+	}
+	for _, loyal := range result.Members[1:] {
+		if loyal.VotesCast != 2 || loyal.Rebellions != 0 || loyal.RebellionRate != 0 {
+			t.Fatalf("unexpected loyal member stats %+v", loyal)
+		}
+	}
+}
+
+func TestComputeRebellionsSplitsMembersByFractie(t *testing.T) {
+	vote := func(decision string, person string, party string, voteType string) memberVoteRow {
+		return memberVoteRow{DecisionKey: decision, PersonSourceID: person, MemberName: person, PartySourceID: party, PartyName: party, VoteType: voteType}
+	}
+
+	// anna leaves the PVV for her own fractie and rebels only in the PVV.
+	result := computeRebellions([]memberVoteRow{
+		vote("d1", "anna", "pvv", "Tegen"),
+		vote("d1", "bram", "pvv", "Voor"),
+		vote("d1", "cees", "pvv", "Voor"),
+		vote("d2", "anna", "groep-anna", "Tegen"),
+	})
+
+	byParty := map[string]MemberRebellionStats{}
+	for _, stats := range result.Members {
+		if stats.PersonSourceID == "anna" {
+			byParty[stats.PartySourceID] = stats
+		}
+	}
+	if len(byParty) != 2 || byParty["pvv"].Rebellions != 1 || byParty["pvv"].VotesCast != 1 || byParty["groep-anna"].Rebellions != 0 || byParty["groep-anna"].VotesCast != 1 {
+		t.Fatalf("anna = %+v, want one row per fractie", byParty)
+	}
+}
@@ -0,0 +1,265 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+type SubmitterEffectivenessOptions struct {
+	Period CabinetPeriod
+	// PartySourceID limits the result to one submitting party. Empty lists
+	// every party that filed a motion in the period.
+	PartySourceID string
+	Kind          string
+}
+
+// SubmitterEffectiveness reports what became of the motions each party filed
+// in a cabinet period. A motion counts for the party of its indiener;
+// medeindieners are not counted, so every motion is counted once.
+type SubmitterEffectiveness struct {
+	Coalition  SubmitterOutcomes
+	Opposition SubmitterOutcomes
+	Parties    []PartySubmitterStats
+}
+
+// SubmitterOutcomes counts motions by their latest outcome. Motions with an
+// unknown outcome have no decision yet; they count towards Filed only. AdoptionRate is the share
+// of adopted motions among those that were adopted or rejected.
+type SubmitterOutcomes struct {
+	Filed        int
+	Adopted      int
+	Rejected     int
+	Withdrawn    int
+	Held         int
+	Unknown      int
+	AdoptionRate float64
+}
+
+type PartySubmitterStats struct {
+	PartySourceID  string
+	PartyName      string
+	CoalitionParty bool
+	SubmitterOutcomes
+	Categories []SubmitterCategoryStats
+}
+
+type SubmitterCategoryStats struct {
+	CategoryKey string
+	Name        string
+	Kind        string
+	SubmitterOutcomes
+}
+
+// submittedMotionRow is one motion filed by a party, repeated once per
+// category the motion is in. CategoryKey is nil for uncategorised motions.
+type submittedMotionRow struct {
+	MotionKey     string
+	PartySourceID string
+	PartyName     string
+	Outcome       *string
+	CategoryKey   *string
+	CategoryName  *string
+	CategoryKind  *string
+}
+
+func LoadSubmitterEffectiveness(ctx context.Context, pool *pgxpool.Pool, options SubmitterEffectivenessOptions) (SubmitterEffectiveness, error) {
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return SubmitterEffectiveness{}, fmt.Errorf("invalid kind %q", options.Kind)
+	}
+
+	cacheKey := fmt.Sprintf("analysis:submitter_effectiveness:%s:%s:%s", options.Period.PeriodKey, options.PartySourceID, kind)
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copySubmitterEffectiveness(cached.(SubmitterEffectiveness)), nil
+	}
+
+	// The outcome comes from the tally of the latest Besluit with a known
+	// outcome, the same one the motion pages show; agenda and scheduling
+	// Besluiten are skipped.
+	rows, err := pool.Query(ctx, `
+		SELECT m.motion_key,
+		       COALESCE(s.party_source_id, s.party_name) AS party_source_id,
+		       COALESCE(p.short_name, s.party_name, s.party_source_id) AS party_name,
+		       t.outcome,
+		       c.category_key,
+		       c.name,
+		       c.kind
+		FROM motion_submitters s
+		JOIN motions m ON m.motion_key = s.motion_key
+		LEFT JOIN parties p ON p.source_key = s.source_key
+		                   AND p.source_id = s.party_source_id
+		LEFT JOIN LATERAL (
+			SELECT dt.outcome
+			FROM decision_tallies dt
+			JOIN decisions d ON d.decision_key = dt.decision_key
+			WHERE d.motion_key = m.motion_key
+			  AND d.source_deleted = false
+			  AND dt.outcome <> 'unknown'
+			ORDER BY d.source_updated_at DESC NULLS LAST, d.decision_order DESC NULLS LAST
+			LIMIT 1
+		) t ON true
+		LEFT JOIN motion_categories mc ON mc.motion_key = m.motion_key
+		LEFT JOIN categories c ON c.category_key = mc.category_key
+		WHERE m.jurisdiction_key = $1
+		  AND m.source_deleted = false
+		  AND m.proposed_at >= $2
+		  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+		  AND ($4::text = 'all' OR m.kind = $4)
+		  AND s.relation = 'Indiener'
+		  AND s.source_deleted = false
+		  AND COALESCE(s.party_source_id, s.party_name) IS NOT NULL
+		  AND ($5::text = '' OR s.party_source_id = $5)
+		ORDER BY m.motion_key
+	`, options.Period.Jurisdiction, options.Period.StartedOn, options.Period.EndedOn, kind, options.PartySourceID)
+	if err != nil {
+		return SubmitterEffectiveness{}, err
+	}
+	defer rows.Close()
+
+	motions := []submittedMotionRow{}
+	for rows.Next() {
+		var row submittedMotionRow
+		if err := rows.Scan(&row.MotionKey, &row.PartySourceID, &row.PartyName, &row.Outcome, &row.CategoryKey, &row.CategoryName, &row.CategoryKind); err != nil {
+			return SubmitterEffectiveness{}, err
+		}
+		motions = append(motions, row)
+	}
+	if err := rows.Err(); err != nil {
+		return SubmitterEffectiveness{}, err
+	}
+
+	result := tallySubmitterEffectiveness(motions, normalizedPartyNames(options.Period.Parties))
+	cache.Global().Set(cacheKey, copySubmitterEffectiveness(result))
+	return result, nil
+}
+
+func (outcomes *SubmitterOutcomes) add(outcome politics.Outcome) {
+	outcomes.Filed++
+	switch outcome {
+	case politics.OutcomeAdopted:
+		outcomes.Adopted++
+	case politics.OutcomeRejected:
+		outcomes.Rejected++
+	case politics.OutcomeWithdrawn:
+		outcomes.Withdrawn++
+	case politics.OutcomeHeld:
+		outcomes.Held++
+	default:
+		outcomes.Unknown++
+	}
+}
+
+func (outcomes *SubmitterOutcomes) finish() {
+	if voted := outcomes.Adopted + outcomes.Rejected; voted > 0 {
+		outcomes.AdoptionRate = (float64(outcomes.Adopted) / float64(voted)) * 100
+	}
+}
+
+// tallySubmitterEffectiveness counts each motion once per party and once per
+// category. coalitionParties are upper-cased short names, see
+// normalizedPartyNames. Parties are ordered by motions filed, categories by
+// motions filed within the party.
+func tallySubmitterEffectiveness(motions []submittedMotionRow, coalitionParties []string) SubmitterEffectiveness {
+	coalition := map[string]bool{}
+	for _, name := range coalitionParties {
+		coalition[name] = true
+	}
+
+	type partyTally struct {
+		stats      *PartySubmitterStats
+		motions    map[string]bool
+		categories map[string]*SubmitterCategoryStats
+	}
+	parties := map[string]*partyTally{}
+	result := SubmitterEffectiveness{Parties: []PartySubmitterStats{}}
+	for _, row := range motions {
+		tally, ok := parties[row.PartySourceID]
+		if !ok {
+			tally = &partyTally{
+				stats: &PartySubmitterStats{
+					PartySourceID:  row.PartySourceID,
+					PartyName:      row.PartyName,
+					CoalitionParty: coalition[strings.ToUpper(strings.TrimSpace(row.PartyName))],
+					Categories:     []SubmitterCategoryStats{},
+				},
+				motions:    map[string]bool{},
+				categories: map[string]*SubmitterCategoryStats{},
+			}
+			parties[row.PartySourceID] = tally
+		}
+
+		outcome := politics.OutcomeUnknown
+		if row.Outcome != nil {
+			outcome = politics.Outcome(*row.Outcome)
+		}
+		if !tally.motions[row.MotionKey] {
+			tally.motions[row.MotionKey] = true
+			tally.stats.add(outcome)
+			if tally.stats.CoalitionParty {
+				result.Coalition.add(outcome)
+			} else {
+				result.Opposition.add(outcome)
+			}
+		}
+
+		if row.CategoryKey == nil {
+			continue
+		}
+		category, ok := tally.categories[*row.CategoryKey]
+		if !ok {
+			category = &SubmitterCategoryStats{CategoryKey: *row.CategoryKey}
+			if row.CategoryName != nil {
+				category.Name = *row.CategoryName
+			}
+			if row.CategoryKind != nil {
+				category.Kind = *row.CategoryKind
+			}
+			tally.categories[*row.CategoryKey] = category
+		}
+		category.add(outcome)
+	}
+
+	for _, tally := range parties {
+		tally.stats.finish()
+		for _, category := range tally.categories {
+			category.finish()
+			tally.stats.Categories = append(tally.stats.Categories, *category)
+		}
+		sort.Slice(tally.stats.Categories, func(i, j int) bool {
+			left, right := tally.stats.Categories[i], tally.stats.Categories[j]
+			if left.Filed != right.Filed {
+				return left.Filed > right.Filed
+			}
+			return left.Name < right.Name
+		})
+		result.Parties = append(result.Parties, *tally.stats)
+	}
+	sort.Slice(result.Parties, func(i, j int) bool {
+		left, right := result.Parties[i], result.Parties[j]
+		if left.Filed != right.Filed {
+			return left.Filed > right.Filed
+		}
+		return left.PartyName < right.PartyName
+	})
+	result.Coalition.finish()
+	result.Opposition.finish()
+
+	return result
+}
+
+func copySubmitterEffectiveness(src SubmitterEffectiveness) SubmitterEffectiveness {
+	out := src
+	out.Parties = make([]PartySubmitterStats, len(src.Parties))
+	for index, party := range src.Parties {
+		party.Categories = append([]SubmitterCategoryStats(nil), party.Categories...)
+		out.Parties[index] = party
+	}
+	return out
+}
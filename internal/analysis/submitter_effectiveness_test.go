@@ -0,0 +1,44 @@
+package analysis
+
+import "testing"
+
+func TestTallySubmitterEffectiveness(t *testing.T) {
+	text := func(value string) *string { return &value }
+	zorg := text("zorg")
+
+	result := tallySubmitterEffectiveness([]submittedMotionRow{
+		// m1 is in two categories but counts once for the party.
+		{MotionKey: "m1", PartySourceID: "vvd", PartyName: "VVD", Outcome: text("adopted"), CategoryKey: zorg, CategoryName: text("Zorg")},
+		{MotionKey: "m1", PartySourceID: "vvd", PartyName: "VVD", Outcome: text("adopted"), CategoryKey: text("wonen"), CategoryName: text("Wonen")},
+		{MotionKey: "m2", PartySourceID: "sp", PartyName: "SP", Outcome: text("rejected"), CategoryKey: zorg, CategoryName: text("Zorg")},
+		{MotionKey: "m3", PartySourceID: "sp", PartyName: "SP", Outcome: text("adopted")},
+		{MotionKey: "m4", PartySourceID: "sp", PartyName: "SP", Outcome: text("withdrawn")},
+		{MotionKey: "m5", PartySourceID: "sp", PartyName: "SP"},
+	}, normalizedPartyNames([]string{"VVD"}))
+
+	if len(result.Parties) != 2 {
+		t.Fatalf("len(Parties) = %d, want 2", len(result.Parties))
+	}
+	sp := result.Parties[0]
+	if sp.PartySourceID != "sp" || sp.CoalitionParty {
+		t.Fatalf("unexpected first party %+v", sp)
+	}
+	if sp.Filed != 4 || sp.Adopted != 1 || sp.Rejected != 1 || sp.Withdrawn != 1 || sp.Unknown != 1 || sp.AdoptionRate != 50 {
+		t.Fatalf("unexpected SP outcomes %+v", sp.SubmitterOutcomes)
+	}
+	if len(sp.Categories) != 1 || sp.Categories[0].Filed != 1 || sp.Categories[0].Rejected != 1 {
+		t.Fatalf("unexpected SP categories %+v", sp.Categories)
+	}
+
+	vvd := result.Parties[1]
+	if !vvd.CoalitionParty || vvd.Filed != 1 || vvd.Adopted != 1 || len(vvd.Categories) != 2 {
+		t.Fatalf("unexpected VVD stats %+v", vvd)
+	}
+
+	if result.Coalition.Filed != 1 || result.Coalition.AdoptionRate != 100 {
+		t.Fatalf("unexpected coalition outcomes %+v", result.Coalition)
+	}
+	if result.Opposition.Filed != 4 || result.Opposition.Adopted != 1 {
+		t.Fatalf("unexpected opposition outcomes %+v", result.Opposition)
+	}
+}
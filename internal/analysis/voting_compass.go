@@ -39,6 +39,10 @@ type VotingCompassOptions struct {
 	// PartySourceIDs keeps only motions where the selected parties (two or more)
 	// did not all vote the same way.
 	PartySourceIDs []string
+	// Kind is passed through NormalizeMotionKind. Only moties carry the
+	// "verzoekt" bullet points the compass needs, so other kinds yield nothing
+	// until their texts are extracted too.
+	Kind string
 }
 
 func LoadVotingCompassMotions(ctx context.Context, pool *pgxpool.Pool, options VotingCompassOptions) ([]VotingCompassMotion, error) {
@@ -69,8 +73,12 @@ func LoadVotingCompassMotions(ctx context.Context, pool *pgxpool.Pool, options V
 	if partySourceIDs == nil {
 		partySourceIDs = []string{}
 	}
+	kind, ok := NormalizeMotionKind(options.Kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid kind %q", options.Kind)
+	}
 
-	cacheKey := fmt.Sprintf("analysis:voting_compass_motions:%s:%s:%s:%d:%d:%v:%v:%v", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), limit, minParties, excludeKeys, categoryKeys, partySourceIDs)
+	cacheKey := fmt.Sprintf("analysis:voting_compass_motions:%s:%s:%s:%d:%d:%v:%v:%v:%s", jurisdiction, formatOptTime(options.DateFrom), formatOptTime(options.DateTo), limit, minParties, excludeKeys, categoryKeys, partySourceIDs, kind)
 	if cached, ok := cache.Global().Get(cacheKey); ok {
 		return copyVotingCompassMotions(cached.([]VotingCompassMotion)), nil
 	}
@@ -96,6 +104,7 @@ func LoadVotingCompassMotions(ctx context.Context, pool *pgxpool.Pool, options V
 			  )
 			  AND ($2::timestamptz IS NULL OR m.proposed_at >= $2)
 			  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+			  AND ($9::text = 'all' OR m.kind = $9)
 			  AND (cardinality($6::text[]) = 0 OR m.motion_key <> ALL($6))
 			  AND (cardinality($7::text[]) = 0 OR EXISTS (
 			      SELECT 1 FROM motion_categories mc
@@ -103,37 +112,25 @@ func LoadVotingCompassMotions(ctx context.Context, pool *pgxpool.Pool, options V
 			        AND mc.category_key = ANY($7)
 			  ))
 			  AND (cardinality($8::text[]) < 2 OR (
-			      SELECT COUNT(DISTINCT v.vote_type)
-			      FROM votes v
-			      WHERE v.motion_key = m.motion_key
-			        AND v.party_source_id = ANY($8)
-			        AND v.vote_type IN ('Voor', 'Tegen')
-			        AND v.source_deleted = false
-			        AND v.mistake = false
+			      SELECT COUNT(DISTINCT pp.position)
+			      FROM motion_party_positions pp
+			      WHERE pp.motion_key = m.motion_key
+			        AND pp.party_source_id = ANY($8)
+			        AND pp.position <> 'NEUTRAL'
 			  ) > 1)
 			ORDER BY m.proposed_at DESC NULLS LAST, m.motion_key
 			LIMIT $5 * 5
 		),
 		party_positions AS (
-			SELECT v.motion_key,
-			       v.party_source_id,
-			       COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown') AS party_name,
-			       CASE
-			         WHEN SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) THEN 'FOR'
-			         WHEN SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END) > SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) THEN 'AGAINST'
-			         ELSE 'NEUTRAL'
-			       END AS position
-			FROM votes v
-			JOIN candidates c ON c.motion_key = v.motion_key
-			LEFT JOIN parties p ON p.source_key = v.source_key
-			                   AND p.source_id = v.party_source_id
-			WHERE v.source_deleted = false
-			  AND v.mistake = false
-			  AND v.vote_type IN ('Voor', 'Tegen')
-			GROUP BY v.motion_key,
-			         v.party_source_id,
-			         COALESCE(p.short_name, v.party_name, v.actor_name, v.party_source_id, 'unknown')
-			HAVING SUM(CASE WHEN v.vote_type = 'Voor' THEN 1 ELSE 0 END) <> SUM(CASE WHEN v.vote_type = 'Tegen' THEN 1 ELSE 0 END)
+			SELECT pp.motion_key,
+			       pp.party_source_id,
+			       COALESCE(p.short_name, pp.party_name, pp.party_source_id) AS party_name,
+			       pp.position
+			FROM motion_party_positions pp
+			JOIN candidates c ON c.motion_key = pp.motion_key
+			LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+			                   AND p.source_id = pp.party_source_id
+			WHERE pp.position <> 'NEUTRAL'
 		),
 		eligible_motions AS (
 			SELECT c.motion_key,
@@ -163,7 +160,7 @@ func LoadVotingCompassMotions(ctx context.Context, pool *pgxpool.Pool, options V
 		FROM eligible_motions em
 		JOIN party_positions pp ON pp.motion_key = em.motion_key
 		ORDER BY em.proposed_at DESC NULLS LAST, em.motion_key, pp.party_name
-	`, jurisdiction, options.DateFrom, options.DateTo, minParties, limit, excludeKeys, categoryKeys, partySourceIDs)
+	`, jurisdiction, options.DateFrom, options.DateTo, minParties, limit, excludeKeys, categoryKeys, partySourceIDs, kind)
 	if err != nil {
 		return nil, err
 	}
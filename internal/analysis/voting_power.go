@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/cache"
+	"partijgedrag/internal/politics"
+)
+
+// Where the seats of a VotingPower came from.
+const (
+	SeatSourceVotes        = "votes"
+	SeatSourceParties      = "parties"
+	SeatSourceHypothetical = "hypothetical"
+)
+
+type PartySeats struct {
+	PartySourceID  string
+	PartyName      string
+	Seats          int
+	CoalitionParty bool
+}
+
+// VotingPower gives every fractie's a priori power to decide a vote by
+// simple majority, next to its seat share. Shares and indices are fractions
+// that sum to 1 over the parties.
+type VotingPower struct {
+	Source     string
+	TotalSeats int
+	Quota      int
+	Parties    []PartyPower
+	// Coalition adds up the coalition parties; both indices are additive.
+	Coalition PartyPower
+}
+
+type PartyPower struct {
+	PartySeats
+	SeatShare     float64
+	ShapleyShubik float64
+	Banzhaf       float64
+}
+
+// LoadVotingPower takes the seat distribution of a cabinet period from its
+// last faction vote that covers the whole house, falling back to the latest
+// one when none does, and to parties.seats for a period without votes.
+func LoadVotingPower(ctx context.Context, pool *pgxpool.Pool, period CabinetPeriod) (VotingPower, error) {
+	cacheKey := "analysis:voting_power:" + period.PeriodKey
+	if cached, ok := cache.Global().Get(cacheKey); ok {
+		return copyVotingPower(cached.(VotingPower)), nil
+	}
+
+	coalitionParties := normalizedPartyNames(period.Parties)
+	seats, err := loadPartySeats(ctx, pool, `
+		WITH faction_decisions AS (
+			SELECT v.decision_key,
+			       max(m.proposed_at) AS proposed_at,
+			       sum(v.party_size)::int AS seats
+			FROM votes v
+			JOIN motions m ON m.motion_key = v.motion_key
+			JOIN decisions d ON d.decision_key = v.decision_key
+			WHERE m.jurisdiction_key = $1
+			  AND m.source_deleted = false
+			  AND m.proposed_at >= $2
+			  AND ($3::timestamptz IS NULL OR m.proposed_at < $3)
+			  AND d.source_deleted = false
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+			  AND v.person_source_id IS NULL
+			  AND v.party_source_id IS NOT NULL
+			  AND v.party_size IS NOT NULL
+			GROUP BY v.decision_key
+		),
+		latest AS (
+			SELECT decision_key
+			FROM faction_decisions
+			ORDER BY seats = $5 DESC, proposed_at DESC NULLS LAST, decision_key DESC
+			LIMIT 1
+		)
+		SELECT v.party_source_id,
+		       COALESCE(min(p.short_name), min(v.party_name), v.party_source_id) AS party_name,
+		       max(v.party_size)::int AS seats,
+		       upper(COALESCE(min(p.short_name), min(v.party_name), v.party_source_id)) = ANY($4::text[]) AS coalition_party
+		FROM votes v
+		JOIN latest l ON l.decision_key = v.decision_key
+		LEFT JOIN parties p ON p.source_key = 'tweedekamer-odata-v2'
+		                   AND p.source_id = v.party_source_id
+		WHERE v.source_deleted = false
+		  AND v.mistake = false
+		  AND v.person_source_id IS NULL
+		  AND v.party_source_id IS NOT NULL
+		  AND v.party_size IS NOT NULL
+		GROUP BY v.party_source_id
+	`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties, politics.HouseSeats)
+	if err != nil {
+		return VotingPower{}, err
+	}
+	source := SeatSourceVotes
+
+	if len(seats) == 0 {
+		seats, err = loadPartySeats(ctx, pool, `
+			SELECT source_id,
+			       COALESCE(short_name, name, source_id),
+			       seats,
+			       upper(COALESCE(short_name, name, source_id)) = ANY($4::text[])
+			FROM parties
+			WHERE jurisdiction_key = $1
+			  AND source_deleted = false
+			  AND seats > 0
+			  AND (active_to IS NULL OR active_to >= $2)
+			  AND ($3::timestamptz IS NULL OR active_from IS NULL OR active_from < $3)
+		`, period.Jurisdiction, period.StartedOn, period.EndedOn, coalitionParties)
+		if err != nil {
+			return VotingPower{}, err
+		}
+		source = SeatSourceParties
+	}
+
+	result := ComputeVotingPower(source, seats)
+	cache.Global().Set(cacheKey, result)
+	return copyVotingPower(result), nil
+}
+
+func loadPartySeats(ctx context.Context, pool *pgxpool.Pool, query string, args ...any) ([]PartySeats, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := []PartySeats{}
+	for rows.Next() {
+		var party PartySeats
+		if err := rows.Scan(&party.PartySourceID, &party.PartyName, &party.Seats, &party.CoalitionParty); err != nil {
+			return nil, err
+		}
+		seats = append(seats, party)
+	}
+	return seats, rows.Err()
+}
+
+// ComputeVotingPower computes the power indices of a seat distribution, also
+// a hypothetical one, for a simple majority of the house: 76 of 150, also
+// when the distribution misses a vacant seat or two. Parties are ordered by
+// seats.
+func ComputeVotingPower(source string, seats []PartySeats) VotingPower {
+	result := VotingPower{Source: source, Parties: []PartyPower{}}
+	parties := make([]PartySeats, 0, len(seats))
+	for _, party := range seats {
+		if party.Seats > 0 {
+			parties = append(parties, party)
+			result.TotalSeats += party.Seats
+		}
+	}
+	sort.SliceStable(parties, func(i, j int) bool {
+		if parties[i].Seats != parties[j].Seats {
+			return parties[i].Seats > parties[j].Seats
+		}
+		return parties[i].PartyName < parties[j].PartyName
+	})
+	if result.TotalSeats == 0 {
+		return result
+	}
+	result.Quota = politics.MajorityQuota(politics.HouseSeats)
+
+	seatCounts := make([]int, len(parties))
+	for index, party := range parties {
+		seatCounts[index] = party.Seats
+	}
+	shapleyShubik, banzhaf := politics.PowerIndices(seatCounts, result.Quota)
+
+	result.Coalition.PartyName = "Coalitie"
+	result.Coalition.CoalitionParty = true
+	for index, party := range parties {
+		power := PartyPower{
+			PartySeats:    party,
+			SeatShare:     float64(party.Seats) / float64(result.TotalSeats),
+			ShapleyShubik: shapleyShubik[index],
+			Banzhaf:       banzhaf[index],
+		}
+		result.Parties = append(result.Parties, power)
+		if party.CoalitionParty {
+			result.Coalition.Seats += power.Seats
+			result.Coalition.SeatShare += power.SeatShare
+			result.Coalition.ShapleyShubik += power.ShapleyShubik
+			result.Coalition.Banzhaf += power.Banzhaf
+		}
+	}
+	return result
+}
+
+// maxHypotheticalParties bounds the parties of a hypothetical distribution;
+// PowerIndices grows with their square.
+const maxHypotheticalParties = 40
+
+// ParseSeatDistribution reads a hypothetical distribution such as
+// "PVV:37,GL-PvdA:25,VVD:24" and marks the parties named in coalition. The
+// seats must fill the house, at most maxHypotheticalParties parties.
+func ParseSeatDistribution(value string, coalition []string) ([]PartySeats, error) {
+	coalitionParties := normalizedPartyNames(coalition)
+	seats := []PartySeats{}
+	seen := map[string]bool{}
+	total := 0
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, count, ok := strings.Cut(item, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid seats %q", item)
+		}
+		partySeats, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || partySeats < 0 || partySeats > politics.HouseSeats {
+			return nil, fmt.Errorf("invalid seats %q", item)
+		}
+		if seen[strings.ToUpper(name)] {
+			return nil, fmt.Errorf("duplicate party %q", name)
+		}
+		seen[strings.ToUpper(name)] = true
+		if len(seats) == maxHypotheticalParties {
+			return nil, fmt.Errorf("more than %d parties", maxHypotheticalParties)
+		}
+		total += partySeats
+		seats = append(seats, PartySeats{
+			PartyName:      name,
+			Seats:          partySeats,
+			CoalitionParty: slices.Contains(coalitionParties, strings.ToUpper(name)),
+		})
+	}
+	if total != politics.HouseSeats {
+		return nil, fmt.Errorf("seats add up to %d, not %d", total, politics.HouseSeats)
+	}
+	return seats, nil
+}
+
+func copyVotingPower(src VotingPower) VotingPower {
+	out := src
+	out.Parties = make([]PartyPower, len(src.Parties))
+	copy(out.Parties, src.Parties)
+	return out
+}
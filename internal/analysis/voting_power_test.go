@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestComputeVotingPower(t *testing.T) {
+	power := ComputeVotingPower(SeatSourceHypothetical, []PartySeats{
+		{PartyName: "B", Seats: 50, CoalitionParty: true},
+		{PartyName: "A", Seats: 50, CoalitionParty: true},
+		{PartyName: "C", Seats: 50},
+		{PartyName: "D", Seats: 0},
+	})
+
+	if power.TotalSeats != 150 || power.Quota != 76 {
+		t.Fatalf("total = %d, quota = %d, want 150 and 76", power.TotalSeats, power.Quota)
+	}
+	if len(power.Parties) != 3 || power.Parties[0].PartyName != "A" || power.Parties[2].PartyName != "C" {
+		t.Fatalf("parties = %+v, want A, B, C without the seatless D", power.Parties)
+	}
+	for _, party := range power.Parties {
+		if math.Abs(party.ShapleyShubik-1.0/3) > 1e-12 || math.Abs(party.Banzhaf-1.0/3) > 1e-12 || math.Abs(party.SeatShare-1.0/3) > 1e-12 {
+			t.Fatalf("party = %+v, want a third of everything", party)
+		}
+	}
+	if power.Coalition.Seats != 100 || math.Abs(power.Coalition.ShapleyShubik-2.0/3) > 1e-12 {
+		t.Fatalf("coalition = %+v, want 100 seats and two thirds of the power", power.Coalition)
+	}
+}
+
+func TestComputeVotingPowerKeepsHouseQuota(t *testing.T) {
+	// With two seats vacant, A alone still falls one short of 76.
+	power := ComputeVotingPower(SeatSourceVotes, []PartySeats{
+		{PartyName: "A", Seats: 75},
+		{PartyName: "B", Seats: 73},
+	})
+
+	if power.TotalSeats != 148 || power.Quota != 76 {
+		t.Fatalf("total = %d, quota = %d, want 148 and 76", power.TotalSeats, power.Quota)
+	}
+	for _, party := range power.Parties {
+		if math.Abs(party.ShapleyShubik-0.5) > 1e-12 || math.Abs(party.Banzhaf-0.5) > 1e-12 {
+			t.Fatalf("party = %+v, want half of the power", party)
+		}
+	}
+}
+
+func TestParseSeatDistribution(t *testing.T) {
+	seats, err := ParseSeatDistribution(" PVV:37, GL-PvdA:25,ChristenUnie:3, Rest:85 ", []string{"pvv", "CU"})
+	if err != nil {
+		t.Fatalf("ParseSeatDistribution() returned error: %v", err)
+	}
+	want := []PartySeats{
+		{PartyName: "PVV", Seats: 37, CoalitionParty: true},
+		{PartyName: "GL-PvdA", Seats: 25},
+		{PartyName: "ChristenUnie", Seats: 3, CoalitionParty: true},
+		{PartyName: "Rest", Seats: 85},
+	}
+	if len(seats) != len(want) {
+		t.Fatalf("seats = %+v, want %+v", seats, want)
+	}
+	for index := range want {
+		if seats[index] != want[index] {
+			t.Fatalf("seats = %+v, want %+v", seats, want)
+		}
+	}
+
+	tooMany := "A:150"
+	for index := 0; index < maxHypotheticalParties; index++ {
+		tooMany += fmt.Sprintf(",P%d:0", index)
+	}
+	for _, value := range []string{"", "PVV", "PVV:x", "PVV:-1", "PVV:151", "PVV:150,pvv:0", ":3", "PVV:75", "PVV:150,VVD:1", tooMany} {
+		if _, err := ParseSeatDistribution(value, nil); err == nil {
+			t.Fatalf("ParseSeatDistribution(%q) accepted", value)
+		}
+	}
+}
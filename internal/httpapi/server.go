@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"partijgedrag/internal/cache"
 	"partijgedrag/internal/categorize"
 	"partijgedrag/internal/politics"
+	"partijgedrag/internal/quality"
 	"partijgedrag/internal/status"
 	"partijgedrag/internal/web"
 )
@@ -38,10 +40,20 @@ func (server Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/coalition-analysis", c.Middleware(cache.PolicyDynamic, server.getCoalitionAnalysis))
 	mux.HandleFunc("GET /api/coalition-analysis/motions", c.Middleware(cache.PolicyDynamic, server.listCoalitionMotions))
 	mux.HandleFunc("GET /api/ingestion-runs", c.Middleware(cache.PolicyDynamic, server.listIngestionRuns))
+	mux.HandleFunc("GET /api/data-quality/rules", c.Middleware(cache.PolicyDynamic, server.listDataQualityRules))
+	mux.HandleFunc("GET /api/data-quality/findings", c.Middleware(cache.PolicyDynamic, server.listDataQualityFindings))
 	mux.HandleFunc("GET /api/categories", c.Middleware(cache.PolicyDynamic, server.listCategories))
 	mux.HandleFunc("GET /api/parties", c.Middleware(cache.PolicyDynamic, server.listParties))
 	mux.HandleFunc("GET /api/party-likeness", c.Middleware(cache.PolicyDynamic, server.listPartyLikeness))
+	mux.HandleFunc("GET /api/party-likeness/series", c.Middleware(cache.PolicyDynamic, server.getPartyLikenessSeries))
 	mux.HandleFunc("GET /api/party-focus", c.Middleware(cache.PolicyDynamic, server.getPartyFocus))
+	mux.HandleFunc("GET /api/party-map", c.Middleware(cache.PolicyDynamic, server.getPartyMap))
+	mux.HandleFunc("GET /api/ideal-points", c.Middleware(cache.PolicyDynamic, server.getIdealPoints))
+	mux.HandleFunc("GET /api/party-cohesion", c.Middleware(cache.PolicyDynamic, server.getPartyCohesion))
+	mux.HandleFunc("GET /api/pivotal-parties", c.Middleware(cache.PolicyDynamic, server.getPivotalParties))
+	mux.HandleFunc("GET /api/rebellions", c.Middleware(cache.PolicyDynamic, server.listRebellions))
+	mux.HandleFunc("GET /api/submitter-effectiveness", c.Middleware(cache.PolicyDynamic, server.getSubmitterEffectiveness))
+	mux.HandleFunc("GET /api/voting-power", c.Middleware(cache.PolicyDynamic, server.getVotingPower))
 	mux.HandleFunc("GET /api/voting-compass/motions", c.Middleware(cache.PolicyDynamic, server.listVotingCompassMotions))
 	mux.HandleFunc("POST /api/compass-sessions", server.createCompassSession)
 	mux.HandleFunc("GET /api/compass-sessions/{sessionKey}", c.Middleware(cache.PolicyImmutable, server.getCompassSession))
@@ -65,6 +77,45 @@ func (server Server) summary(response http.ResponseWriter, request *http.Request
 	writeJSON(response, http.StatusOK, summary)
 }
 
+func (server Server) listDataQualityRules(response http.ResponseWriter, request *http.Request) {
+	summaries, err := quality.LoadRuleSummaries(request.Context(), server.Pool)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{"rules": summaries})
+}
+
+func (server Server) listDataQualityFindings(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter := quality.FindingFilter{
+		Rule:      query.Get("rule"),
+		MotionKey: query.Get("motionKey"),
+		Resolved:  query.Get("resolved") == "true",
+		Limit:     clamp(parseInt(query.Get("limit"), 50), 1, 500),
+	}
+	if value := query.Get("severity"); value != "" {
+		severity, err := quality.ParseSeverity(value)
+		if err != nil {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_severity"})
+			return
+		}
+		filter.Severity = severity
+	}
+
+	findings, err := quality.LoadFindings(request.Context(), server.Pool, filter)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"findings": findings,
+		"limit":    filter.Limit,
+	})
+}
+
 func (server Server) listIngestionRuns(response http.ResponseWriter, request *http.Request) {
 	query := request.URL.Query()
 	limit := clamp(parseInt(query.Get("limit"), 10), 1, 100)
@@ -248,6 +299,11 @@ func (server Server) getCoalitionAnalysis(response http.ResponseWriter, request
 		jurisdiction = "nl-tweede-kamer"
 	}
 	minCommon := clamp(parseInt(query.Get("minCommon"), 5), 1, 1000)
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
 
 	period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
 	if err != nil {
@@ -262,6 +318,7 @@ func (server Server) getCoalitionAnalysis(response http.ResponseWriter, request
 	coalition, err := analysis.LoadCoalitionAnalysis(request.Context(), server.Pool, analysis.CoalitionAnalysisOptions{
 		Period:    period,
 		MinCommon: minCommon,
+		Kind:      kind,
 	})
 	if err != nil {
 		writeError(response, err)
@@ -291,6 +348,7 @@ func (server Server) getCoalitionAnalysis(response http.ResponseWriter, request
 			"parties":      period.Parties,
 		},
 		"minCommon": minCommon,
+		"kind":      kind,
 		"summary": map[string]any{
 			"motionsWithCoalitionVotes": coalition.Summary.MotionsWithCoalitionVotes,
 			"clearBlocPosition":         coalition.Summary.ClearBlocPosition,
@@ -302,6 +360,221 @@ func (server Server) getCoalitionAnalysis(response http.ResponseWriter, request
 	})
 }
 
+func (server Server) getPivotalParties(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	limit := clamp(parseInt(query.Get("limit"), 10), 1, 100)
+
+	period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
+	if err != nil {
+		if analysis.IsNotFound(err) {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+			return
+		}
+		writeError(response, err)
+		return
+	}
+
+	pivotal, err := analysis.LoadPivotalParties(request.Context(), server.Pool, analysis.PivotalPartyOptions{
+		Period: period,
+		Kind:   kind,
+		Limit:  limit,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	parties := make([]map[string]any, 0, len(pivotal.Parties))
+	for _, party := range pivotal.Parties {
+		parties = append(parties, map[string]any{
+			"partySourceId":    party.PartySourceID,
+			"partyName":        party.PartyName,
+			"coalitionParty":   party.CoalitionParty,
+			"decisions":        party.Decisions,
+			"winning":          party.Winning,
+			"pivotal":          party.Pivotal,
+			"solePivotal":      party.SolePivotal,
+			"againstCoalition": party.AgainstCoalition,
+		})
+	}
+	defeats := make([]map[string]any, 0, len(pivotal.CoalitionDefeats))
+	for _, decision := range pivotal.CoalitionDefeats {
+		defeats = append(defeats, map[string]any{
+			"motionKey":      decision.MotionKey,
+			"decisionKey":    decision.DecisionKey,
+			"number":         decision.Number,
+			"subject":        decision.Subject,
+			"proposedAt":     decision.ProposedAt,
+			"seatsFor":       decision.SeatsFor,
+			"seatsAgainst":   decision.SeatsAgainst,
+			"adopted":        decision.Adopted,
+			"pivotalParties": decision.PivotalParties,
+		})
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"decisions":         pivotal.Decisions,
+		"adopted":           pivotal.Adopted,
+		"coalitionDefeated": pivotal.CoalitionDefeated,
+		"parties":           parties,
+		"coalitionDefeats":  defeats,
+		"kind":              kind,
+		"period":            period.PeriodKey,
+		"dateFrom":          dateString(&period.StartedOn),
+		"dateTo":            dateString(period.EndedOn),
+	})
+}
+
+// getVotingPower returns the power indices of a cabinet period, or of the
+// hypothetical distribution in seats (e.g. seats=PVV:37,VVD:24,...) with the
+// parties named in coalition marked.
+func (server Server) getVotingPower(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	var power analysis.VotingPower
+	periodKey := ""
+	if seatsValue := query.Get("seats"); seatsValue != "" {
+		seats, err := analysis.ParseSeatDistribution(seatsValue, strings.Split(query.Get("coalition"), ","))
+		if err != nil {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_seats"})
+			return
+		}
+		power = analysis.ComputeVotingPower(analysis.SeatSourceHypothetical, seats)
+	} else {
+		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
+		if err != nil {
+			if analysis.IsNotFound(err) {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+				return
+			}
+			writeError(response, err)
+			return
+		}
+		power, err = analysis.LoadVotingPower(request.Context(), server.Pool, period)
+		if err != nil {
+			writeError(response, err)
+			return
+		}
+		periodKey = period.PeriodKey
+	}
+
+	powerValue := func(party analysis.PartyPower) map[string]any {
+		return map[string]any{
+			"partySourceId":  party.PartySourceID,
+			"partyName":      party.PartyName,
+			"coalitionParty": party.CoalitionParty,
+			"seats":          party.Seats,
+			"seatShare":      party.SeatShare,
+			"shapleyShubik":  party.ShapleyShubik,
+			"banzhaf":        party.Banzhaf,
+		}
+	}
+	parties := make([]map[string]any, 0, len(power.Parties))
+	for _, party := range power.Parties {
+		parties = append(parties, powerValue(party))
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"source":     power.Source,
+		"totalSeats": power.TotalSeats,
+		"quota":      power.Quota,
+		"parties":    parties,
+		"coalition":  powerValue(power.Coalition),
+		"period":     periodKey,
+	})
+}
+
+func (server Server) getSubmitterEffectiveness(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+
+	period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
+	if err != nil {
+		if analysis.IsNotFound(err) {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+			return
+		}
+		writeError(response, err)
+		return
+	}
+
+	effectiveness, err := analysis.LoadSubmitterEffectiveness(request.Context(), server.Pool, analysis.SubmitterEffectivenessOptions{
+		Period:        period,
+		PartySourceID: query.Get("partySourceId"),
+		Kind:          kind,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	parties := make([]map[string]any, 0, len(effectiveness.Parties))
+	for _, party := range effectiveness.Parties {
+		categories := make([]map[string]any, 0, len(party.Categories))
+		for _, category := range party.Categories {
+			value := submitterOutcomesValue(category.SubmitterOutcomes)
+			value["categoryKey"] = category.CategoryKey
+			value["name"] = category.Name
+			value["kind"] = category.Kind
+			categories = append(categories, value)
+		}
+		value := submitterOutcomesValue(party.SubmitterOutcomes)
+		value["partySourceId"] = party.PartySourceID
+		value["partyName"] = party.PartyName
+		value["coalitionParty"] = party.CoalitionParty
+		value["categories"] = categories
+		parties = append(parties, value)
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"period": map[string]any{
+			"periodKey":    period.PeriodKey,
+			"jurisdiction": period.Jurisdiction,
+			"name":         period.Name,
+			"startedOn":    period.StartedOn.Format("2006-01-02"),
+			"endedOn":      dateString(period.EndedOn),
+			"parties":      period.Parties,
+		},
+		"kind":       kind,
+		"coalition":  submitterOutcomesValue(effectiveness.Coalition),
+		"opposition": submitterOutcomesValue(effectiveness.Opposition),
+		"parties":    parties,
+	})
+}
+
+func submitterOutcomesValue(outcomes analysis.SubmitterOutcomes) map[string]any {
+	return map[string]any{
+		"filed":        outcomes.Filed,
+		"adopted":      outcomes.Adopted,
+		"rejected":     outcomes.Rejected,
+		"withdrawn":    outcomes.Withdrawn,
+		"held":         outcomes.Held,
+		"unknown":      outcomes.Unknown,
+		"adoptionRate": outcomes.AdoptionRate,
+	}
+}
+
 func (server Server) listCoalitionMotions(response http.ResponseWriter, request *http.Request) {
 	query := request.URL.Query()
 	jurisdiction := query.Get("jurisdiction")
@@ -318,6 +591,11 @@ func (server Server) listCoalitionMotions(response http.ResponseWriter, request
 		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_relation"})
 		return
 	}
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
 
 	period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
 	if err != nil {
@@ -335,6 +613,7 @@ func (server Server) listCoalitionMotions(response http.ResponseWriter, request
 		Period:        period,
 		PartySourceID: partySourceID,
 		Relation:      relation,
+		Kind:          kind,
 		Limit:         limit,
 		Offset:        offset,
 	})
@@ -372,6 +651,7 @@ func (server Server) listCoalitionMotions(response http.ResponseWriter, request
 		},
 		"partySourceId": partySourceID,
 		"relation":      relation,
+		"kind":          kind,
 		"limit":         limit,
 		"offset":        offset,
 		"motions":       items,
@@ -396,6 +676,11 @@ func (server Server) listPartyLikeness(response http.ResponseWriter, request *ht
 		return
 	}
 	minCommon := clamp(parseInt(query.Get("minCommon"), 10), 1, 1000)
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
 	periodKey := query.Get("period")
 	if periodKey != "custom" {
 		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
@@ -417,6 +702,7 @@ func (server Server) listPartyLikeness(response http.ResponseWriter, request *ht
 		DateFrom:     dateFrom,
 		DateTo:       dateTo,
 		MinCommon:    minCommon,
+		Kind:         kind,
 	})
 	if err != nil {
 		writeError(response, err)
@@ -439,12 +725,113 @@ func (server Server) listPartyLikeness(response http.ResponseWriter, request *ht
 	writeJSON(response, http.StatusOK, map[string]any{
 		"partyLikeness": items,
 		"minCommon":     minCommon,
+		"kind":          kind,
 		"period":        periodKey,
 		"dateFrom":      dateString(dateFrom),
 		"dateTo":        dateString(dateTo),
 	})
 }
 
+// getPartyLikenessSeries returns the likeness of one pair, or of every pair
+// without party1 and party2, per month, quarter or parliamentary year.
+func (server Server) getPartyLikenessSeries(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	party1SourceID := strings.TrimSpace(query.Get("party1"))
+	party2SourceID := strings.TrimSpace(query.Get("party2"))
+	if (party1SourceID == "") != (party2SourceID == "") || (party1SourceID != "" && party1SourceID == party2SourceID) {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_party_pair"})
+		return
+	}
+	dateFrom, err := parseDate(query.Get("dateFrom"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_from"})
+		return
+	}
+	dateTo, err := parseDate(query.Get("dateTo"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_to"})
+		return
+	}
+	minCommon := clamp(parseInt(query.Get("minCommon"), 10), 1, 1000)
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	interval, ok := analysis.NormalizeLikenessInterval(query.Get("interval"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_interval"})
+		return
+	}
+	periodKey := query.Get("period")
+	if periodKey != "custom" {
+		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
+		if err != nil {
+			if analysis.IsNotFound(err) {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+				return
+			}
+			writeError(response, err)
+			return
+		}
+		periodKey = period.PeriodKey
+		dateFrom = &period.StartedOn
+		dateTo = period.EndedOn
+	}
+
+	result, err := analysis.LoadPartyLikenessSeries(request.Context(), server.Pool, analysis.PartyLikenessSeriesOptions{
+		Jurisdiction:   jurisdiction,
+		Party1SourceID: party1SourceID,
+		Party2SourceID: party2SourceID,
+		DateFrom:       dateFrom,
+		DateTo:         dateTo,
+		MinCommon:      minCommon,
+		Kind:           kind,
+		Interval:       interval,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	series := make([]map[string]any, 0, len(result.Series))
+	for _, row := range result.Series {
+		points := make([]map[string]any, 0, len(row.Points))
+		for _, point := range row.Points {
+			points = append(points, map[string]any{
+				"bucket":        point.Bucket,
+				"commonMotions": point.CommonMotions,
+				"sameVotes":     point.SameVotes,
+				"similarity":    point.Similarity,
+			})
+		}
+		series = append(series, map[string]any{
+			"party1SourceId": row.Party1SourceID,
+			"party1Name":     row.Party1Name,
+			"party2SourceId": row.Party2SourceID,
+			"party2Name":     row.Party2Name,
+			"commonMotions":  row.CommonMotions,
+			"points":         points,
+		})
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"interval":  result.Interval,
+		"buckets":   result.Buckets,
+		"series":    series,
+		"minCommon": minCommon,
+		"kind":      kind,
+		"period":    periodKey,
+		"dateFrom":  dateString(dateFrom),
+		"dateTo":    dateString(dateTo),
+	})
+}
+
 func (server Server) getPartyFocus(response http.ResponseWriter, request *http.Request) {
 	query := request.URL.Query()
 	jurisdiction := query.Get("jurisdiction")
@@ -468,6 +855,11 @@ func (server Server) getPartyFocus(response http.ResponseWriter, request *http.R
 		return
 	}
 	minCommon := clamp(parseInt(query.Get("minCommon"), 10), 1, 1000)
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
 	periodKey := query.Get("period")
 	if periodKey != "custom" {
 		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
@@ -490,6 +882,7 @@ func (server Server) getPartyFocus(response http.ResponseWriter, request *http.R
 		DateFrom:      dateFrom,
 		DateTo:        dateTo,
 		MinCommon:     minCommon,
+		Kind:          kind,
 	})
 	if err != nil {
 		if analysis.IsNotFound(err) {
@@ -541,12 +934,398 @@ func (server Server) getPartyFocus(response http.ResponseWriter, request *http.R
 		"categories": categories,
 		"likeness":   likeness,
 		"minCommon":  minCommon,
+		"kind":       kind,
 		"period":     periodKey,
 		"dateFrom":   dateString(dateFrom),
 		"dateTo":     dateString(dateTo),
 	})
 }
 
+func (server Server) getPartyMap(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	dateFrom, err := parseDate(query.Get("dateFrom"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_from"})
+		return
+	}
+	dateTo, err := parseDate(query.Get("dateTo"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_to"})
+		return
+	}
+	periodKey := query.Get("period")
+	if periodKey != "custom" {
+		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
+		if err != nil {
+			if analysis.IsNotFound(err) {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+				return
+			}
+			writeError(response, err)
+			return
+		}
+		periodKey = period.PeriodKey
+		dateFrom = &period.StartedOn
+		dateTo = period.EndedOn
+	}
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	minMotions := clamp(parseInt(query.Get("minMotions"), 20), 1, 1000)
+	topMotions := clamp(parseInt(query.Get("topMotions"), 5), 1, 50)
+
+	partyMap, err := analysis.LoadPartyMap(request.Context(), server.Pool, analysis.PartyMapOptions{
+		Jurisdiction: jurisdiction,
+		DateFrom:     dateFrom,
+		DateTo:       dateTo,
+		Kind:         kind,
+		MinMotions:   minMotions,
+		TopMotions:   topMotions,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	parties := make([]map[string]any, 0, len(partyMap.Parties))
+	for _, party := range partyMap.Parties {
+		parties = append(parties, map[string]any{
+			"partySourceId": party.PartySourceID,
+			"partyName":     party.PartyName,
+			"x":             party.X,
+			"y":             party.Y,
+			"motions":       party.Motions,
+		})
+	}
+	motionValues := func(motions []analysis.PartyMapMotion) []map[string]any {
+		values := make([]map[string]any, 0, len(motions))
+		for _, motion := range motions {
+			values = append(values, map[string]any{
+				"motionKey": motion.MotionKey,
+				"number":    motion.Number,
+				"subject":   motion.Subject,
+				"loading":   motion.Loading,
+			})
+		}
+		return values
+	}
+	axes := make([]map[string]any, 0, len(partyMap.Axes))
+	for _, axis := range partyMap.Axes {
+		axes = append(axes, map[string]any{
+			"explainedVariance": axis.ExplainedVariance,
+			"positive":          motionValues(axis.Positive),
+			"negative":          motionValues(axis.Negative),
+		})
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"parties":    parties,
+		"axes":       axes,
+		"motions":    partyMap.Motions,
+		"kind":       kind,
+		"minMotions": minMotions,
+		"period":     periodKey,
+		"dateFrom":   dateString(dateFrom),
+		"dateTo":     dateString(dateTo),
+	})
+}
+
+// getIdealPoints serves the estimate stored by `partijgedrag maintenance
+// ideal-points`; a period without one is not found.
+func (server Server) getIdealPoints(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, query.Get("period"))
+	if err != nil {
+		if analysis.IsNotFound(err) {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+			return
+		}
+		writeError(response, err)
+		return
+	}
+	scope, ok := analysis.NormalizeIdealPointScope(query.Get("scope"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_scope"})
+		return
+	}
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	limit := clamp(parseInt(query.Get("limit"), 20), 1, 200)
+
+	idealPoints, err := analysis.LoadIdealPoints(request.Context(), server.Pool, period.PeriodKey, scope, kind, limit)
+	if err != nil {
+		if analysis.IsNotFound(err) {
+			writeJSON(response, http.StatusNotFound, map[string]string{"error": "not_found"})
+			return
+		}
+		writeError(response, err)
+		return
+	}
+
+	points := make([]map[string]any, 0, len(idealPoints.Points))
+	for _, point := range idealPoints.Points {
+		points = append(points, map[string]any{
+			"subjectId":     point.SubjectID,
+			"name":          point.SubjectName,
+			"partySourceId": point.PartySourceID,
+			"partyName":     point.PartyName,
+			"coordinate":    point.Coordinate,
+			"votes":         point.Votes,
+			"errors":        point.Errors,
+		})
+	}
+	crossCutting := make([]map[string]any, 0, len(idealPoints.CrossCutting))
+	for _, motion := range idealPoints.CrossCutting {
+		crossCutting = append(crossCutting, map[string]any{
+			"motionKey":    motion.MotionKey,
+			"number":       motion.Number,
+			"subject":      motion.Subject,
+			"proposedAt":   motion.ProposedAt,
+			"cuttingPoint": motion.CuttingPoint,
+			"voorAbove":    motion.VoorAbove,
+			"voters":       motion.Voters,
+			"minority":     motion.Minority,
+			"errors":       motion.Errors,
+			"pre":          motion.PRE,
+		})
+	}
+
+	run := idealPoints.Run
+	writeJSON(response, http.StatusOK, map[string]any{
+		"scope":        scope,
+		"kind":         kind,
+		"points":       points,
+		"crossCutting": crossCutting,
+		"fit": map[string]any{
+			"subjects":   run.Subjects,
+			"motions":    run.Motions,
+			"votes":      run.Votes,
+			"errors":     run.Errors,
+			"classified": run.Classified,
+			"apre":       run.APRE,
+			"computedAt": run.ComputedAt,
+		},
+		"period":   period.PeriodKey,
+		"dateFrom": dateString(&period.StartedOn),
+		"dateTo":   dateString(period.EndedOn),
+	})
+}
+
+func (server Server) getPartyCohesion(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	dateFrom, err := parseDate(query.Get("dateFrom"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_from"})
+		return
+	}
+	dateTo, err := parseDate(query.Get("dateTo"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_to"})
+		return
+	}
+	periodKey := query.Get("period")
+	if periodKey != "custom" {
+		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
+		if err != nil {
+			if analysis.IsNotFound(err) {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+				return
+			}
+			writeError(response, err)
+			return
+		}
+		periodKey = period.PeriodKey
+		dateFrom = &period.StartedOn
+		dateTo = period.EndedOn
+	}
+	partySourceID := query.Get("party")
+	category := query.Get("category")
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	limit := clamp(parseInt(query.Get("limit"), 10), 1, 100)
+
+	cohesion, err := analysis.LoadPartyCohesion(request.Context(), server.Pool, analysis.PartyCohesionOptions{
+		Jurisdiction:  jurisdiction,
+		PartySourceID: partySourceID,
+		DateFrom:      dateFrom,
+		DateTo:        dateTo,
+		Category:      category,
+		Kind:          kind,
+		Limit:         limit,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	parties := make([]map[string]any, 0, len(cohesion.Parties))
+	for _, party := range cohesion.Parties {
+		parties = append(parties, map[string]any{
+			"partySourceId": party.PartySourceID,
+			"partyName":     party.PartyName,
+			"decisions":     party.Decisions,
+			"splitVotes":    party.SplitVotes,
+			"rice":          party.Rice,
+		})
+	}
+	categories := make([]map[string]any, 0, len(cohesion.Categories))
+	for _, row := range cohesion.Categories {
+		categories = append(categories, map[string]any{
+			"categoryKey": row.CategoryKey,
+			"name":        row.Name,
+			"kind":        row.Kind,
+			"decisions":   row.Decisions,
+			"splitVotes":  row.SplitVotes,
+			"rice":        row.Rice,
+		})
+	}
+	divided := make([]map[string]any, 0, len(cohesion.Divided))
+	for _, vote := range cohesion.Divided {
+		divided = append(divided, map[string]any{
+			"motionKey":     vote.MotionKey,
+			"decisionKey":   vote.DecisionKey,
+			"number":        vote.Number,
+			"subject":       vote.Subject,
+			"proposedAt":    vote.ProposedAt,
+			"partySourceId": vote.PartySourceID,
+			"partyName":     vote.PartyName,
+			"seatsFor":      vote.SeatsFor,
+			"seatsAgainst":  vote.SeatsAgainst,
+			"rice":          vote.Rice,
+		})
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"parties":    parties,
+		"categories": categories,
+		"divided":    divided,
+		"party":      partySourceID,
+		"category":   category,
+		"kind":       kind,
+		"limit":      limit,
+		"period":     periodKey,
+		"dateFrom":   dateString(dateFrom),
+		"dateTo":     dateString(dateTo),
+	})
+}
+
+func (server Server) listRebellions(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	jurisdiction := query.Get("jurisdiction")
+	if jurisdiction == "" {
+		jurisdiction = "nl-tweede-kamer"
+	}
+
+	dateFrom, err := parseDate(query.Get("dateFrom"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_from"})
+		return
+	}
+	dateTo, err := parseDate(query.Get("dateTo"))
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_date_to"})
+		return
+	}
+	periodKey := query.Get("period")
+	if periodKey != "custom" {
+		period, err := selectedCabinetPeriod(request.Context(), server.Pool, jurisdiction, periodKey)
+		if err != nil {
+			if analysis.IsNotFound(err) {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_period"})
+				return
+			}
+			writeError(response, err)
+			return
+		}
+		periodKey = period.PeriodKey
+		dateFrom = &period.StartedOn
+		dateTo = period.EndedOn
+	}
+	category := query.Get("category")
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	limit := clamp(parseInt(query.Get("limit"), 100), 1, 500)
+
+	rebellions, err := analysis.LoadRebellions(request.Context(), server.Pool, analysis.RebellionOptions{
+		Jurisdiction: jurisdiction,
+		DateFrom:     dateFrom,
+		DateTo:       dateTo,
+		Category:     category,
+		Kind:         kind,
+		Limit:        limit,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	members := make([]map[string]any, 0, len(rebellions.Members))
+	for _, member := range rebellions.Members {
+		members = append(members, map[string]any{
+			"personSourceId": member.PersonSourceID,
+			"memberName":     member.MemberName,
+			"partySourceId":  member.PartySourceID,
+			"partyName":      member.PartyName,
+			"votesCast":      member.VotesCast,
+			"rebellions":     member.Rebellions,
+			"rebellionRate":  member.RebellionRate,
+		})
+	}
+	votes := make([]map[string]any, 0, len(rebellions.Votes))
+	for _, vote := range rebellions.Votes {
+		votes = append(votes, map[string]any{
+			"motionKey":      vote.MotionKey,
+			"decisionKey":    vote.DecisionKey,
+			"number":         vote.Number,
+			"subject":        vote.Subject,
+			"proposedAt":     vote.ProposedAt,
+			"personSourceId": vote.PersonSourceID,
+			"memberName":     vote.MemberName,
+			"partySourceId":  vote.PartySourceID,
+			"partyName":      vote.PartyName,
+			"memberVote":     vote.MemberVote,
+			"partyPosition":  vote.PartyPosition,
+		})
+	}
+
+	writeJSON(response, http.StatusOK, map[string]any{
+		"members":  members,
+		"votes":    votes,
+		"category": category,
+		"kind":     kind,
+		"limit":    limit,
+		"period":   periodKey,
+		"dateFrom": dateString(dateFrom),
+		"dateTo":   dateString(dateTo),
+	})
+}
+
 func (server Server) listVotingCompassMotions(response http.ResponseWriter, request *http.Request) {
 	query := request.URL.Query()
 	jurisdiction := query.Get("jurisdiction")
@@ -573,6 +1352,11 @@ func (server Server) listVotingCompassMotions(response http.ResponseWriter, requ
 
 	limit := clamp(parseInt(query.Get("limit"), 20), 1, 50)
 	minParties := clamp(parseInt(query.Get("minParties"), 8), 1, 50)
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
 
 	motions, err := analysis.LoadVotingCompassMotions(request.Context(), server.Pool, analysis.VotingCompassOptions{
 		Jurisdiction:   jurisdiction,
@@ -583,6 +1367,7 @@ func (server Server) listVotingCompassMotions(response http.ResponseWriter, requ
 		ExcludeKeys:    splitListParam(query.Get("exclude"), 500),
 		CategoryKeys:   splitListParam(query.Get("categories"), 50),
 		PartySourceIDs: splitListParam(query.Get("parties"), 50),
+		Kind:           kind,
 	})
 	if err != nil {
 		writeError(response, err)
@@ -619,6 +1404,7 @@ func (server Server) listVotingCompassMotions(response http.ResponseWriter, requ
 		"period":     periodKey,
 		"limit":      limit,
 		"minParties": minParties,
+		"kind":       kind,
 		"motions":    items,
 	})
 }
@@ -742,6 +1528,27 @@ func (server Server) listMotions(response http.ResponseWriter, request *http.Req
 	}
 	withVotes := query.Get("withVotes") == "true"
 	category := query.Get("category")
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_kind"})
+		return
+	}
+	outcome := query.Get("outcome")
+	if outcome != "" && !slices.Contains(politics.Outcomes, politics.Outcome(outcome)) {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_outcome"})
+		return
+	}
+	// maxMargin keeps motions whose deciding vote was at most that many seats
+	// apart; motions decided without votes never match.
+	var maxMargin *int
+	if value := query.Get("maxMargin"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid_max_margin"})
+			return
+		}
+		maxMargin = &parsed
+	}
 
 	rows, err := server.Pool.Query(ctx, `
 		WITH subset AS (
@@ -756,10 +1563,17 @@ func (server Server) listMotions(response http.ResponseWriter, request *http.Req
 			       m.proposed_at,
 			       m.source_updated_at,
 			       m.source_deleted,
-			       m.votes_synced_at
+			       m.votes_synced_at,
+			       t.outcome,
+			       t.margin,
+			       t.voted
 			FROM motions m
+			LEFT JOIN LATERAL (`+decidingTallySQL("m.motion_key")+`) t ON true
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
+			  AND ($7::text = 'all' OR m.kind = $7)
+			  AND ($8::text = '' OR COALESCE(t.outcome, 'unknown') = $8)
+			  AND ($9::int IS NULL OR (t.voted AND abs(t.margin) <= $9))
 			  AND (
 			    $2::text IS NULL
 			    OR m.title ILIKE '%' || $2 || '%'
@@ -815,10 +1629,12 @@ func (server Server) listMotions(response http.ResponseWriter, request *http.Req
 		       (SELECT COALESCE(SUM(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END), 0)::int
 		          FROM votes v
 		         WHERE v.motion_key = p.motion_key AND v.source_deleted = false AND v.mistake = false AND v.vote_type = 'Tegen') AS votes_against,
+		       COALESCE(p.outcome, 'unknown'),
+		       CASE WHEN p.voted THEN p.margin END,
 		       p.total
 		FROM paged p
 		ORDER BY p.proposed_at DESC NULLS LAST, p.source_updated_at DESC NULLS LAST
-	`, jurisdiction, searchPtr, limit, offset, withVotes, category)
+	`, jurisdiction, searchPtr, limit, offset, withVotes, category, kind, outcome, maxMargin)
 	if err != nil {
 		writeError(response, err)
 		return
@@ -843,6 +1659,7 @@ func (server Server) listMotions(response http.ResponseWriter, request *http.Req
 
 	writeJSON(response, http.StatusOK, map[string]any{
 		"motions": motions,
+		"kind":    kind,
 		"total":   total,
 		"limit":   limit,
 		"offset":  offset,
@@ -871,8 +1688,11 @@ func (server Server) getMotion(response http.ResponseWriter, request *http.Reque
 		       (SELECT count(*)::int FROM votes v WHERE v.motion_key = motions.motion_key AND v.source_deleted = false) AS vote_count,
 		       (SELECT COALESCE(SUM(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END), 0)::int FROM votes v WHERE v.motion_key = motions.motion_key AND v.source_deleted = false AND v.mistake = false AND v.vote_type = 'Voor') AS votes_for,
 		       (SELECT COALESCE(SUM(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END), 0)::int FROM votes v WHERE v.motion_key = motions.motion_key AND v.source_deleted = false AND v.mistake = false AND v.vote_type = 'Tegen') AS votes_against,
+		       COALESCE(t.outcome, 'unknown'),
+		       CASE WHEN t.voted THEN t.margin END,
 		       1 AS total
 		FROM motions
+		LEFT JOIN LATERAL (`+decidingTallySQL("motions.motion_key")+`) t ON true
 		WHERE motion_key = $1
 	`, motionKey).Scan(
 		&motion.MotionKey,
@@ -891,6 +1711,8 @@ func (server Server) getMotion(response http.ResponseWriter, request *http.Reque
 		&motion.VoteCount,
 		&motion.VotesFor,
 		&motion.VotesAgainst,
+		&motion.Outcome,
+		&motion.Margin,
 		&motion.Total,
 	)
 	if err != nil {
@@ -933,8 +1755,51 @@ func (server Server) getMotion(response http.ResponseWriter, request *http.Reque
 		return
 	}
 
+	submitterRows, err := server.Pool.Query(request.Context(), `
+		SELECT s.relation,
+		       s.person_source_id,
+		       COALESCE(m.full_name, s.actor_name) AS member_name,
+		       s.party_source_id,
+		       COALESCE(p.short_name, s.party_name) AS party_name
+		FROM motion_submitters s
+		LEFT JOIN members m ON m.source_key = s.source_key
+		                   AND m.source_id = s.person_source_id
+		LEFT JOIN parties p ON p.source_key = s.source_key
+		                   AND p.source_id = s.party_source_id
+		WHERE s.motion_key = $1
+		  AND s.source_deleted = false
+		ORDER BY s.relation <> 'Indiener', m.last_name NULLS LAST, member_name
+	`, motionKey)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	defer submitterRows.Close()
+
+	submitters := []map[string]any{}
+	for submitterRows.Next() {
+		var relation string
+		var personSourceID, memberName, partySourceID, partyName *string
+		if err := submitterRows.Scan(&relation, &personSourceID, &memberName, &partySourceID, &partyName); err != nil {
+			writeError(response, err)
+			return
+		}
+		submitters = append(submitters, map[string]any{
+			"relation":       relation,
+			"personSourceId": personSourceID,
+			"memberName":     memberName,
+			"partySourceId":  partySourceID,
+			"partyName":      partyName,
+		})
+	}
+	if err := submitterRows.Err(); err != nil {
+		writeError(response, err)
+		return
+	}
+
 	value := motion.mapValue()
 	value["categories"] = categories
+	value["submitters"] = submitters
 	writeJSON(response, http.StatusOK, value)
 }
 
@@ -1044,7 +1909,28 @@ type motionRow struct {
 	VoteCount         int
 	VotesFor          int
 	VotesAgainst      int
-	Total             int
+	Outcome           string
+	// Margin is seats for minus against in the deciding vote, nil when the
+	// motion was decided, or is pending, without votes.
+	Margin *int
+	Total  int
+}
+
+// decidingTallySQL selects the tally of the latest Besluit with an outcome
+// for a lateral join; motionKey is the motion key column of the outer query.
+func decidingTallySQL(motionKey string) string {
+	return `
+	SELECT dt.outcome,
+	       dt.margin,
+	       dt.seats_for + dt.seats_against > 0 AS voted
+	FROM decision_tallies dt
+	JOIN decisions d ON d.decision_key = dt.decision_key
+	WHERE d.motion_key = ` + motionKey + `
+	  AND d.source_deleted = false
+	  AND dt.outcome <> 'unknown'
+	ORDER BY d.source_updated_at DESC NULLS LAST, d.decision_order DESC NULLS LAST
+	LIMIT 1
+`
 }
 
 func (row *motionRow) scan(scan scanner) error {
@@ -1065,6 +1951,8 @@ func (row *motionRow) scan(scan scanner) error {
 		&row.VoteCount,
 		&row.VotesFor,
 		&row.VotesAgainst,
+		&row.Outcome,
+		&row.Margin,
 		&row.Total,
 	)
 }
@@ -1087,6 +1975,8 @@ func (row motionRow) mapValue() map[string]any {
 		"voteCount":         row.VoteCount,
 		"votesFor":          row.VotesFor,
 		"votesAgainst":      row.VotesAgainst,
+		"outcome":           row.Outcome,
+		"seatMargin":        row.Margin,
 	}
 }
 
@@ -0,0 +1,83 @@
+package ingest
+
+import (
+	"context"
+
+	"partijgedrag/internal/politics"
+)
+
+// refreshDecisionTallies recomputes decision_tallies for every Besluit of the
+// given motions and drops the tallies of deleted ones. It runs in the
+// transaction that changed the decisions or votes.
+func refreshDecisionTallies(ctx context.Context, db dbExecutor, motionKeys []string) error {
+	if len(motionKeys) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT decision_key, decision_type
+		FROM decisions
+		WHERE motion_key = ANY($1::text[])
+		  AND source_deleted = false
+	`, motionKeys)
+	if err != nil {
+		return err
+	}
+	decisionKeys, outcomes := []string{}, []string{}
+	for rows.Next() {
+		var decisionKey string
+		var decisionType *string
+		if err := rows.Scan(&decisionKey, &decisionType); err != nil {
+			rows.Close()
+			return err
+		}
+		decisionKeys = append(decisionKeys, decisionKey)
+		outcomes = append(outcomes, string(politics.DecisionOutcome(decisionType)))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(ctx, `
+		DELETE FROM decision_tallies
+		WHERE motion_key = ANY($1::text[])
+		  AND NOT (decision_key = ANY($2::text[]))
+	`, motionKeys, decisionKeys); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO decision_tallies (decision_key, motion_key, seats_for, seats_against, seats_not_voting, margin, outcome, updated_at)
+		SELECT d.decision_key,
+		       d.motion_key,
+		       t.seats_for,
+		       t.seats_against,
+		       CASE WHEN t.vote_count > 0 THEN GREATEST($3 - t.seats_for - t.seats_against, 0) ELSE 0 END,
+		       t.seats_for - t.seats_against,
+		       o.outcome,
+		       now()
+		FROM unnest($1::text[], $2::text[]) AS o(decision_key, outcome)
+		JOIN decisions d ON d.decision_key = o.decision_key
+		CROSS JOIN LATERAL (
+			SELECT count(v.vote_key)::int AS vote_count,
+			       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Voor'), 0)::int AS seats_for,
+			       COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int AS seats_against
+			FROM votes v
+			WHERE v.decision_key = d.decision_key
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+		) t
+		ON CONFLICT (decision_key)
+		DO UPDATE SET motion_key = EXCLUDED.motion_key,
+		              seats_for = EXCLUDED.seats_for,
+		              seats_against = EXCLUDED.seats_against,
+		              seats_not_voting = EXCLUDED.seats_not_voting,
+		              margin = EXCLUDED.margin,
+		              outcome = EXCLUDED.outcome,
+		              updated_at = now()
+		WHERE (decision_tallies.seats_for, decision_tallies.seats_against, decision_tallies.seats_not_voting, decision_tallies.outcome, decision_tallies.motion_key)
+		      IS DISTINCT FROM (EXCLUDED.seats_for, EXCLUDED.seats_against, EXCLUDED.seats_not_voting, EXCLUDED.outcome, EXCLUDED.motion_key)
+	`, decisionKeys, outcomes, politics.HouseSeats)
+	return err
+}
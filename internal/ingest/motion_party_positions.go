@@ -0,0 +1,48 @@
+package ingest
+
+import "context"
+
+// refreshMotionPartyPositions rebuilds motion_party_positions for the given
+// motions. The classification mirrors politics.PartyPosition.
+func refreshMotionPartyPositions(ctx context.Context, db dbExecutor, motionKeys []string) error {
+	if len(motionKeys) == 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(ctx, `
+		DELETE FROM motion_party_positions
+		WHERE motion_key = ANY($1::text[])
+	`, motionKeys); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO motion_party_positions (motion_key, party_source_id, party_name, votes_for, votes_against, position, updated_at)
+		SELECT motion_key,
+		       party_source_id,
+		       party_name,
+		       votes_for,
+		       votes_against,
+		       CASE
+		         WHEN votes_for > votes_against THEN 'FOR'
+		         WHEN votes_against > votes_for THEN 'AGAINST'
+		         ELSE 'NEUTRAL'
+		       END,
+		       now()
+		FROM (
+			SELECT v.motion_key,
+			       v.party_source_id,
+			       COALESCE(max(v.party_name), max(v.actor_name)) AS party_name,
+			       count(*) FILTER (WHERE v.vote_type = 'Voor')::int AS votes_for,
+			       count(*) FILTER (WHERE v.vote_type = 'Tegen')::int AS votes_against
+			FROM votes v
+			WHERE v.motion_key = ANY($1::text[])
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+			  AND v.party_source_id IS NOT NULL
+			  AND v.vote_type IN ('Voor', 'Tegen')
+			GROUP BY v.motion_key, v.party_source_id
+		) counts
+	`, motionKeys)
+	return err
+}
@@ -0,0 +1,187 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Pipeline is one step of a sync. The ingest types implement it with their
+// Run method.
+type Pipeline interface {
+	Run(ctx context.Context) error
+}
+
+// PipelineFunc adapts a plain function, e.g. a step outside this package such
+// as categorization, to Pipeline.
+type PipelineFunc func(ctx context.Context) error
+
+func (run PipelineFunc) Run(ctx context.Context) error {
+	return run(ctx)
+}
+
+// Step is a pipeline registered under a name, with the steps that have to
+// succeed before it starts.
+type Step struct {
+	Name      string
+	DependsOn []string
+	// Fatal makes a failure stop the sync: steps that have not started yet
+	// are not started. A non-fatal failure only skips the steps that depend
+	// on it.
+	Fatal    bool
+	Pipeline Pipeline
+}
+
+// Registry holds the steps of a sync in registration order. A step can only
+// depend on steps registered before it, so the graph has no cycles.
+type Registry struct {
+	steps []Step
+}
+
+func (registry *Registry) Register(step Step) error {
+	if step.Name == "" {
+		return errors.New("pipeline step needs a name")
+	}
+	if step.Pipeline == nil {
+		return fmt.Errorf("pipeline step %s has no pipeline", step.Name)
+	}
+	if registry.has(step.Name) {
+		return fmt.Errorf("pipeline step %s is registered twice", step.Name)
+	}
+	for _, dependency := range step.DependsOn {
+		if !registry.has(dependency) {
+			return fmt.Errorf("pipeline step %s depends on %s, which is not registered before it", step.Name, dependency)
+		}
+	}
+	registry.steps = append(registry.steps, step)
+	return nil
+}
+
+func (registry *Registry) Names() []string {
+	names := make([]string, 0, len(registry.steps))
+	for _, step := range registry.steps {
+		names = append(names, step.Name)
+	}
+	return names
+}
+
+func (registry *Registry) has(name string) bool {
+	return slices.ContainsFunc(registry.steps, func(step Step) bool { return step.Name == name })
+}
+
+// Select returns the steps named in only, or all of them when only is empty,
+// minus the ones in skip. It does not pull in dependencies: a dependency that
+// is not selected counts as satisfied.
+func (registry *Registry) Select(only []string, skip []string) ([]Step, error) {
+	for _, name := range slices.Concat(only, skip) {
+		if !registry.has(name) {
+			return nil, fmt.Errorf("unknown pipeline %q, want one of %s", name, strings.Join(registry.Names(), ", "))
+		}
+	}
+
+	var steps []Step
+	for _, step := range registry.steps {
+		if len(only) > 0 && !slices.Contains(only, step.Name) {
+			continue
+		}
+		if slices.Contains(skip, step.Name) {
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+type stepState int
+
+const (
+	stepPending stepState = iota
+	stepRunning
+	stepSucceeded
+	stepFailed
+	stepSkipped
+)
+
+type stepResult struct {
+	index int
+	err   error
+}
+
+// RunSteps starts every step as soon as the selected steps it depends on have
+// succeeded, so independent steps run in parallel. It waits for running steps
+// before returning, also after a fatal failure, so no run is left behind
+// half-recorded. The error joins the fatal failures.
+func RunSteps(ctx context.Context, steps []Step) error {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		index[step.Name] = i
+	}
+
+	states := make([]stepState, len(steps))
+	results := make(chan stepResult)
+	running := 0
+	var fatal []error
+
+	for {
+		if len(fatal) == 0 && ctx.Err() == nil {
+			// Steps are in registration order, so one pass sees every
+			// dependency settled before its dependents.
+			for i, step := range steps {
+				if states[i] != stepPending {
+					continue
+				}
+				ready := true
+				for _, dependency := range step.DependsOn {
+					j, selected := index[dependency]
+					if !selected {
+						continue
+					}
+					switch states[j] {
+					case stepFailed, stepSkipped:
+						states[i] = stepSkipped
+						fmt.Printf("sync step=%s skipped, %s did not succeed\n", step.Name, dependency)
+					case stepSucceeded:
+						continue
+					}
+					ready = false
+					break
+				}
+				if !ready {
+					continue
+				}
+
+				states[i] = stepRunning
+				running++
+				fmt.Printf("sync step=%s\n", step.Name)
+				go func() {
+					results <- stepResult{index: i, err: step.Pipeline.Run(ctx)}
+				}()
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+		result := <-results
+		running--
+		step := steps[result.index]
+		if result.err == nil {
+			states[result.index] = stepSucceeded
+			continue
+		}
+		states[result.index] = stepFailed
+		if step.Fatal {
+			fmt.Printf("sync step=%s failed: %v\n", step.Name, result.err)
+			fatal = append(fatal, fmt.Errorf("%s: %w", step.Name, result.err))
+		} else {
+			fmt.Printf("sync step=%s failed, continuing: %v\n", step.Name, result.err)
+		}
+	}
+
+	if len(fatal) > 0 {
+		return errors.Join(fatal...)
+	}
+	return ctx.Err()
+}
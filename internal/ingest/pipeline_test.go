@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type stepLog struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (log *stepLog) step(name string, err error, dependsOn ...string) Step {
+	return Step{
+		Name:      name,
+		DependsOn: dependsOn,
+		Fatal:     true,
+		Pipeline: PipelineFunc(func(ctx context.Context) error {
+			log.mu.Lock()
+			defer log.mu.Unlock()
+			log.names = append(log.names, name)
+			return err
+		}),
+	}
+}
+
+func (log *stepLog) ran(name string) bool {
+	return slices.Contains(log.names, name)
+}
+
+func (log *stepLog) before(first string, second string) bool {
+	return slices.Index(log.names, first) < slices.Index(log.names, second)
+}
+
+func TestRegistryRejectsUnknownDependenciesAndDuplicates(t *testing.T) {
+	log := &stepLog{}
+	registry := &Registry{}
+	if err := registry.Register(log.step("votes", nil, "motions")); err == nil {
+		t.Fatal("registered a step before its dependency")
+	}
+	if err := registry.Register(log.step("motions", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(log.step("motions", nil)); err == nil {
+		t.Fatal("registered motions twice")
+	}
+}
+
+func TestRegistrySelect(t *testing.T) {
+	log := &stepLog{}
+	registry := &Registry{}
+	for _, step := range []Step{log.step("parties", nil), log.step("motions", nil), log.step("votes", nil, "motions")} {
+		if err := registry.Register(step); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(steps []Step) []string {
+		var names []string
+		for _, step := range steps {
+			names = append(names, step.Name)
+		}
+		return names
+	}
+
+	steps, err := registry.Select(nil, []string{"parties"})
+	if err != nil || !slices.Equal(names(steps), []string{"motions", "votes"}) {
+		t.Fatalf("skip parties = %v, %v", names(steps), err)
+	}
+	steps, err = registry.Select([]string{"votes", "parties"}, nil)
+	if err != nil || !slices.Equal(names(steps), []string{"parties", "votes"}) {
+		t.Fatalf("only votes,parties = %v, %v", names(steps), err)
+	}
+	if _, err := registry.Select([]string{"members"}, nil); err == nil {
+		t.Fatal("selected an unknown pipeline")
+	}
+}
+
+func TestRunStepsRespectsDependencies(t *testing.T) {
+	log := &stepLog{}
+	err := RunSteps(context.Background(), []Step{
+		log.step("parties", nil),
+		log.step("motions", nil),
+		log.step("members", nil, "parties"),
+		log.step("votes", nil, "motions", "members"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.names) != 4 || !log.before("parties", "members") || !log.before("members", "votes") || !log.before("motions", "votes") {
+		t.Fatalf("ran %v", log.names)
+	}
+}
+
+func TestRunStepsSkipsDependentsOfAFailedStep(t *testing.T) {
+	log := &stepLog{}
+	logos := log.step("logos", errors.New("logo endpoint down"))
+	logos.Fatal = false
+	err := RunSteps(context.Background(), []Step{
+		log.step("parties", nil),
+		logos,
+		log.step("logo-thumbnails", nil, "logos"),
+		log.step("members", nil, "parties"),
+	})
+	if err != nil {
+		t.Fatalf("non-fatal failure returned %v", err)
+	}
+	if log.ran("logo-thumbnails") || !log.ran("members") {
+		t.Fatalf("ran %v", log.names)
+	}
+}
+
+func TestRunStepsStopsAfterAFatalFailure(t *testing.T) {
+	log := &stepLog{}
+	cause := errors.New("odata down")
+	err := RunSteps(context.Background(), []Step{
+		log.step("parties", cause),
+		log.step("members", nil, "parties"),
+	})
+	if !errors.Is(err, cause) {
+		t.Fatalf("err = %v, want %v", err, cause)
+	}
+	if log.ran("members") {
+		t.Fatalf("ran %v", log.names)
+	}
+}
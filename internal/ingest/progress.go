@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// progressInterval bounds how often a run writes its progress, so a fast page
+// loop does not turn into an UPDATE per record.
+const progressInterval = 2 * time.Second
+
+// runProgress keeps the progress columns of a running ingestion_runs row up to
+// date, for status ingestion-runs --watch and /data-quality. Writes are best
+// effort: a failed one is skipped, the finish update has the final counts.
+// Report from one goroutine only, e.g. the one collecting worker results.
+type runProgress struct {
+	pool    *pgxpool.Pool
+	runID   int64
+	pages   int
+	items   int
+	total   *int
+	written time.Time
+}
+
+func newRunProgress(pool *pgxpool.Pool, runID int64) *runProgress {
+	return &runProgress{pool: pool, runID: runID}
+}
+
+// setTotal records how many items the run expects, e.g. the pending motions
+// in its batch, which makes an ETA possible.
+func (progress *runProgress) setTotal(ctx context.Context, total int) {
+	progress.total = &total
+	progress.write(ctx)
+}
+
+// countTotal sets the total from an upstream count of what the run pages
+// through, capped at maxPages pages of pageSize for a bounded run. Counting is
+// best effort: without a total the run still reports pages and items.
+func (progress *runProgress) countTotal(ctx context.Context, count func(context.Context) (int, error), maxPages int, pageSize int) {
+	total, err := count(ctx)
+	if err != nil {
+		fmt.Printf("progress total unavailable: %v\n", err)
+		return
+	}
+	if maxPages > 0 && pageSize > 0 {
+		total = min(total, maxPages*pageSize)
+	}
+	progress.setTotal(ctx, total)
+}
+
+func (progress *runProgress) addPage(ctx context.Context, items int) {
+	progress.pages++
+	progress.items += items
+	progress.maybeWrite(ctx)
+}
+
+func (progress *runProgress) addItems(ctx context.Context, items int) {
+	progress.items += items
+	progress.maybeWrite(ctx)
+}
+
+func (progress *runProgress) maybeWrite(ctx context.Context) {
+	if time.Since(progress.written) >= progressInterval {
+		progress.write(ctx)
+	}
+}
+
+func (progress *runProgress) write(ctx context.Context) {
+	progress.written = time.Now()
+	_, _ = progress.pool.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET progress_pages = $2,
+		    progress_items = $3,
+		    progress_total = $4,
+		    progress_updated_at = now()
+		WHERE id = $1
+		  AND status = 'running'
+	`, progress.runID, progress.pages, progress.items, progress.total)
+}
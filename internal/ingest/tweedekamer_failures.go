@@ -0,0 +1,97 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// A failed motion is retried after 15 minutes, then after twice as long on
+// every next failure, up to a week. A zaak that is broken upstream then costs
+// one request a week instead of failing every sync.
+const (
+	failureBackoffBase = 15 * time.Minute
+	failureBackoffMax  = 7 * 24 * time.Hour
+)
+
+type ingestionFailure struct {
+	Attempts    int
+	NextRetryAt time.Time
+}
+
+func failureBackoff(attempts int) time.Duration {
+	backoff := failureBackoffBase
+	for attempt := 1; attempt < attempts; attempt++ {
+		backoff *= 2
+		if backoff >= failureBackoffMax {
+			return failureBackoffMax
+		}
+	}
+	return backoff
+}
+
+// recordIngestionFailure counts another failed attempt at itemKey and schedules
+// the next one. The pipeline lock keeps two runs from recording the same item.
+func recordIngestionFailure(ctx context.Context, pool *pgxpool.Pool, pipeline string, itemKey string, cause error) (ingestionFailure, error) {
+	var failure ingestionFailure
+	err := pool.QueryRow(ctx, `
+		SELECT attempts
+		FROM ingestion_failures
+		WHERE source_key = $1 AND pipeline = $2 AND item_key = $3
+	`, tweedeKamerSourceKey, pipeline, itemKey).Scan(&failure.Attempts)
+	if err != nil && err != pgx.ErrNoRows {
+		return ingestionFailure{}, err
+	}
+	failure.Attempts++
+	failure.NextRetryAt = time.Now().Add(failureBackoff(failure.Attempts))
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO ingestion_failures (source_key, pipeline, item_key, attempts, last_error, next_retry_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (source_key, pipeline, item_key)
+		DO UPDATE SET attempts = EXCLUDED.attempts,
+		              last_error = EXCLUDED.last_error,
+		              last_failed_at = now(),
+		              next_retry_at = EXCLUDED.next_retry_at
+	`, tweedeKamerSourceKey, pipeline, itemKey, failure.Attempts, cause.Error(), failure.NextRetryAt)
+	return failure, err
+}
+
+func clearIngestionFailure(ctx context.Context, pool *pgxpool.Pool, pipeline string, itemKey string) error {
+	_, err := pool.Exec(ctx, `
+		DELETE FROM ingestion_failures
+		WHERE source_key = $1 AND pipeline = $2 AND item_key = $3
+	`, tweedeKamerSourceKey, pipeline, itemKey)
+	return err
+}
+
+// failedMotionCandidates returns the motions with a recorded failure in
+// pipeline, due or not, the longest waiting first.
+func failedMotionCandidates(ctx context.Context, pool *pgxpool.Pool, pipeline string, limit int) ([]motionCandidate, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT m.motion_key, m.source_id
+		FROM ingestion_failures f
+		JOIN motions m ON m.motion_key = f.item_key
+		WHERE f.source_key = $1
+		  AND f.pipeline = $2
+		  AND m.source_deleted = false
+		ORDER BY f.next_retry_at ASC
+		LIMIT $3
+	`, tweedeKamerSourceKey, pipeline, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var motions []motionCandidate
+	for rows.Next() {
+		var motion motionCandidate
+		if err := rows.Scan(&motion.MotionKey, &motion.SourceID); err != nil {
+			return nil, err
+		}
+		motions = append(motions, motion)
+	}
+	return motions, rows.Err()
+}
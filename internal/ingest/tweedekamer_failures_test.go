@@ -0,0 +1,21 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailureBackoffDoublesUpToAWeek(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  15 * time.Minute,
+		2:  30 * time.Minute,
+		3:  time.Hour,
+		10: 128 * time.Hour,
+		11: 7 * 24 * time.Hour,
+		50: 7 * 24 * time.Hour,
+	} {
+		if got := failureBackoff(attempts); got != want {
+			t.Fatalf("failureBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/source/tweedekamer"
+)
+
+const (
+	membersPipeline   = "members.raw"
+	persoonCollection = "Persoon"
+)
+
+type TweedeKamerMemberIngest struct {
+	Pool          *pgxpool.Pool
+	Client        *tweedekamer.Client
+	BatchSize     int
+	MaxPages      int
+	InitialSince  time.Time
+	CursorOverlap time.Duration
+	SinceOverride *time.Time
+	ResetCursor   bool
+}
+
+func (ingest TweedeKamerMemberIngest) Run(ctx context.Context) error {
+	releaseLock, err := acquirePipelineLock(ctx, ingest.Pool, membersPipeline)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	source, err := ingest.getSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ingest.ResetCursor {
+		if err := ingest.resetCursor(ctx); err != nil {
+			return err
+		}
+	}
+
+	cursorBefore, err := ingest.getCursor(ctx)
+	if err != nil {
+		return err
+	}
+	if ingest.SinceOverride != nil {
+		cursorBefore = Cursor{ApiUpdatedAt: ingest.SinceOverride}
+	}
+
+	since := ingest.cursorSince(cursorBefore)
+	if ingest.SinceOverride != nil {
+		since = *ingest.SinceOverride
+	}
+
+	runID, err := startPipelineRunWithCursor(ctx, ingest.Pool, membersPipeline, cursorBefore)
+	if err != nil {
+		return err
+	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
+	progress := newRunProgress(ingest.Pool, runID)
+	progress.countTotal(ctx, func(ctx context.Context) (int, error) {
+		return ingest.Client.CountChangedMembers(ctx, since)
+	}, ingest.MaxPages, ingest.BatchSize)
+
+	recordsSeen := 0
+	recordsChanged := 0
+	maxUpdatedAt := cursorBefore.ApiUpdatedAt
+	nextURL := ""
+	stopReason := ""
+	skip := 0
+	pagesProcessed := 0
+
+	for page := 1; ; page++ {
+		result, err := ingest.Client.FetchChangedMembers(ctx, since, ingest.BatchSize, skip, nextURL)
+		if err != nil {
+			_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, membersPipeline, "failed", cursorBefore, recordsSeen, recordsChanged, false, "error", err.Error())
+			return err
+		}
+
+		nextURL = result.NextURL
+		recordsSeen += len(result.Records)
+		skip += len(result.Records)
+		pagesProcessed = page
+
+		for _, record := range result.Records {
+			changed, err := ingest.storeMemberRecord(ctx, source.JurisdictionKey, record)
+			if err != nil {
+				_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, membersPipeline, "failed", cursorBefore, recordsSeen, recordsChanged, false, "error", err.Error())
+				return err
+			}
+			if changed {
+				recordsChanged++
+			}
+
+			apiUpdatedAt := timePtr(record.ApiGewijzigdOp)
+			if apiUpdatedAt != nil && (maxUpdatedAt == nil || apiUpdatedAt.After(*maxUpdatedAt)) {
+				value := *apiUpdatedAt
+				maxUpdatedAt = &value
+			}
+		}
+
+		hasMore := nextURL != "" || len(result.Records) == ingest.BatchSize
+		progress.addPage(ctx, len(result.Records))
+		fmt.Printf("members page=%d seen=%d changed=%d next=%t\n", page, recordsSeen, recordsChanged, hasMore)
+
+		if !hasMore {
+			stopReason = "complete"
+			break
+		}
+		if ingest.MaxPages > 0 && page >= ingest.MaxPages {
+			stopReason = "max_pages"
+			break
+		}
+	}
+
+	cursorAfter := Cursor{ApiUpdatedAt: maxUpdatedAt}
+	cursorSaved := stopReason == "complete"
+	if cursorSaved {
+		if err := ingest.saveCursor(ctx, cursorAfter); err != nil {
+			_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, membersPipeline, "failed", cursorAfter, recordsSeen, recordsChanged, false, stopReason, err.Error())
+			return err
+		}
+	} else {
+		cursorAfter = cursorBefore
+	}
+
+	if err := finishPipelineRunWithCursor(ctx, ingest.Pool, runID, membersPipeline, "succeeded", cursorAfter, recordsSeen, recordsChanged, cursorSaved, stopReason, ""); err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"member ingestion complete run_id=%d pages=%d seen=%d changed=%d cursor_before=%s cursor_after=%s cursor_saved=%t stop_reason=%s\n",
+		runID,
+		pagesProcessed,
+		recordsSeen,
+		recordsChanged,
+		formatCursor(cursorBefore),
+		formatCursor(cursorAfter),
+		cursorSaved,
+		stopReason,
+	)
+	return nil
+}
+
+func (ingest TweedeKamerMemberIngest) getSource(ctx context.Context) (source, error) {
+	var result source
+	err := ingest.Pool.QueryRow(ctx, `
+		SELECT source_key, jurisdiction_key, base_url
+		FROM data_sources
+		WHERE source_key = $1 AND enabled = true
+	`, tweedeKamerSourceKey).Scan(&result.SourceKey, &result.JurisdictionKey, &result.BaseURL)
+	if err != nil {
+		return source{}, fmt.Errorf("get data source %s: %w", tweedeKamerSourceKey, err)
+	}
+	return result, nil
+}
+
+func (ingest TweedeKamerMemberIngest) getCursor(ctx context.Context) (Cursor, error) {
+	var raw []byte
+	err := ingest.Pool.QueryRow(ctx, `
+		SELECT cursor
+		FROM source_cursors
+		WHERE source_key = $1 AND pipeline = $2
+	`, tweedeKamerSourceKey, membersPipeline).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		initial := ingest.InitialSince
+		return Cursor{ApiUpdatedAt: &initial}, nil
+	}
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return Cursor{}, err
+	}
+	return cursor, nil
+}
+
+func (ingest TweedeKamerMemberIngest) resetCursor(ctx context.Context) error {
+	_, err := ingest.Pool.Exec(ctx, `
+		DELETE FROM source_cursors
+		WHERE source_key = $1 AND pipeline = $2
+	`, tweedeKamerSourceKey, membersPipeline)
+	return err
+}
+
+func (ingest TweedeKamerMemberIngest) cursorSince(cursor Cursor) time.Time {
+	if cursor.ApiUpdatedAt == nil {
+		return ingest.InitialSince
+	}
+	if cursor.ApiUpdatedAt.Equal(ingest.InitialSince) {
+		return ingest.InitialSince
+	}
+	return cursor.ApiUpdatedAt.Add(-ingest.CursorOverlap)
+}
+
+func (ingest TweedeKamerMemberIngest) saveCursor(ctx context.Context, cursorAfter Cursor) error {
+	raw, err := json.Marshal(cursorAfter)
+	if err != nil {
+		return err
+	}
+
+	_, err = ingest.Pool.Exec(ctx, `
+		INSERT INTO source_cursors (source_key, pipeline, cursor, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (source_key, pipeline)
+		DO UPDATE SET cursor = EXCLUDED.cursor,
+		              updated_at = now()
+	`, tweedeKamerSourceKey, membersPipeline, string(raw))
+	return err
+}
+
+func (ingest TweedeKamerMemberIngest) storeMemberRecord(
+	ctx context.Context,
+	jurisdictionKey string,
+	record tweedekamer.MemberRecord,
+) (bool, error) {
+	tx, err := ingest.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	raw := projectMemberRaw(record)
+	member := projectMember(jurisdictionKey, record)
+
+	rawChanged, err := storeRawRecord(ctx, tx, raw)
+	if err != nil {
+		return false, err
+	}
+
+	if err := upsertMember(ctx, tx, member); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return rawChanged, nil
+}
+
+// upsertMember writes a projected member and their party seats.
+func upsertMember(ctx context.Context, tx pgx.Tx, member memberProjection) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO members (
+			member_key,
+			source_key,
+			jurisdiction_key,
+			source_id,
+			number,
+			initials,
+			first_name,
+			name_prefix,
+			last_name,
+			full_name,
+			gender,
+			function,
+			party_label,
+			residence,
+			source_updated_at,
+			source_deleted,
+			raw_collection,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, now())
+		ON CONFLICT (source_key, source_id)
+		DO UPDATE SET number = EXCLUDED.number,
+		              initials = EXCLUDED.initials,
+		              first_name = EXCLUDED.first_name,
+		              name_prefix = EXCLUDED.name_prefix,
+		              last_name = EXCLUDED.last_name,
+		              full_name = EXCLUDED.full_name,
+		              gender = EXCLUDED.gender,
+		              function = EXCLUDED.function,
+		              party_label = EXCLUDED.party_label,
+		              residence = EXCLUDED.residence,
+		              source_updated_at = EXCLUDED.source_updated_at,
+		              source_deleted = EXCLUDED.source_deleted,
+		              updated_at = now()
+	`, member.MemberKey, member.SourceKey, member.JurisdictionKey, member.SourceID, member.Number, member.Initials, member.FirstName, member.NamePrefix, member.LastName, member.FullName, member.Gender, member.Function, member.PartyLabel, member.Residence, member.SourceUpdatedAt, member.SourceDeleted, member.RawCollection)
+	if err != nil {
+		return err
+	}
+
+	seatKeys := make([]string, 0, len(member.Seats))
+	for _, seat := range member.Seats {
+		seatKeys = append(seatKeys, seat.SeatKey)
+		_, err = tx.Exec(ctx, `
+			INSERT INTO member_party_seats (
+				seat_key,
+				member_key,
+				party_source_id,
+				function,
+				started_on,
+				ended_on,
+				source_deleted,
+				updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5::timestamptz::date, $6::timestamptz::date, $7, now())
+			ON CONFLICT (seat_key)
+			DO UPDATE SET member_key = EXCLUDED.member_key,
+			              party_source_id = EXCLUDED.party_source_id,
+			              function = EXCLUDED.function,
+			              started_on = EXCLUDED.started_on,
+			              ended_on = EXCLUDED.ended_on,
+			              source_deleted = EXCLUDED.source_deleted,
+			              updated_at = now()
+		`, seat.SeatKey, member.MemberKey, seat.PartySourceID, seat.Function, seat.StartedOn, seat.EndedOn, seat.SourceDeleted)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The expansion returns every seat the member ever held, so a seat that is
+	// no longer listed was removed upstream.
+	_, err = tx.Exec(ctx, `
+		UPDATE member_party_seats
+		SET source_deleted = true,
+		    updated_at = now()
+		WHERE member_key = $1
+		  AND source_deleted = false
+		  AND NOT (seat_key = ANY($2::text[]))
+	`, member.MemberKey, seatKeys)
+	return err
+}
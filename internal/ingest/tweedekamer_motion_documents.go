@@ -21,7 +21,8 @@ const motionDocumentsPipeline = "motion_documents.raw"
 // their published motion text. It resolves the kamerstuk publication via the
 // OData Zaak -> Kamerstukdossier/Document navigation, fetches the XML from
 // zoek.officielebekendmakingen.nl, and stores the constaterende/overwegende/
-// verzoekt paragraphs on the motion row.
+// verzoekt paragraphs on the motion row. Amendementen have no such structure
+// and are skipped.
 type TweedeKamerMotionDocumentsIngest struct {
 	Pool        *pgxpool.Pool
 	Client      *tweedekamer.Client
@@ -34,6 +35,9 @@ type TweedeKamerMotionDocumentsIngest struct {
 	// successful extraction is never revisited. This covers motions ingested
 	// before their text appeared on officielebekendmakingen. Zero disables.
 	ResyncGrace time.Duration
+	// RetryFailures processes only motions with a recorded failure, whether or
+	// not their retry is due.
+	RetryFailures bool
 }
 
 func (ingest TweedeKamerMotionDocumentsIngest) Run(ctx context.Context) error {
@@ -47,6 +51,8 @@ func (ingest TweedeKamerMotionDocumentsIngest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
 
 	resyncBefore := ingest.resyncBefore()
 	proposedAfter := ingest.proposedAfter()
@@ -56,13 +62,28 @@ func (ingest TweedeKamerMotionDocumentsIngest) Run(ctx context.Context) error {
 		return err
 	}
 
-	motions, err := ingest.motionCandidates(ctx, resyncBefore, proposedAfter)
+	var motions []motionDocumentCandidate
+	if ingest.RetryFailures {
+		var failedMotions []motionCandidate
+		failedMotions, err = failedMotionCandidates(ctx, ingest.Pool, motionDocumentsPipeline, ingest.Limit)
+		for _, motion := range failedMotions {
+			motions = append(motions, motionDocumentCandidate(motion))
+		}
+	} else {
+		motions, err = ingest.motionCandidates(ctx, resyncBefore, proposedAfter)
+	}
 	if err != nil {
 		_ = finishPipelineRun(ctx, ingest.Pool, runID, motionDocumentsPipeline, "failed", 0, 0, false, "error", err.Error())
 		return err
 	}
 
-	recordsSeen, recordsChanged, err := ingest.processMotionCandidates(ctx, motions)
+	// The candidates are the pending motions, capped at the batch limit.
+	progress := newRunProgress(ingest.Pool, runID)
+	progress.setTotal(ctx, len(motions))
+	recordsSeen, recordsChanged, failed, err := ingest.processMotionCandidates(ctx, motions, progress)
+	if err == nil && failed > 0 && failed == len(motions) {
+		err = fmt.Errorf("all %d motions failed, see status failures", failed)
+	}
 	if err != nil {
 		_ = finishPipelineRun(ctx, ingest.Pool, runID, motionDocumentsPipeline, "failed", recordsSeen, recordsChanged, false, "error", err.Error())
 		return err
@@ -85,7 +106,7 @@ func (ingest TweedeKamerMotionDocumentsIngest) Run(ctx context.Context) error {
 		return err
 	}
 
-	fmt.Printf("motion document batch complete run_id=%d motions=%d seen=%d changed=%d pending_before=%d pending_after=%d stop=%s\n", runID, len(motions), recordsSeen, recordsChanged, pendingBefore, pendingAfter, stopReason)
+	fmt.Printf("motion document batch complete run_id=%d motions=%d seen=%d changed=%d failed=%d pending_before=%d pending_after=%d stop=%s\n", runID, len(motions), recordsSeen, recordsChanged, failed, pendingBefore, pendingAfter, stopReason)
 	return nil
 }
 
@@ -97,9 +118,11 @@ type motionDocumentResult struct {
 	err     error
 }
 
-func (ingest TweedeKamerMotionDocumentsIngest) processMotionCandidates(ctx context.Context, motions []motionDocumentCandidate) (int, int, error) {
+// processMotionCandidates records a failing motion in ingestion_failures and
+// carries on with the others, like the vote ingest does.
+func (ingest TweedeKamerMotionDocumentsIngest) processMotionCandidates(ctx context.Context, motions []motionDocumentCandidate, progress *runProgress) (int, int, int, error) {
 	if len(motions) == 0 {
-		return 0, 0, nil
+		return 0, 0, 0, nil
 	}
 
 	workerCount := ingest.Concurrency
@@ -131,10 +154,6 @@ func (ingest TweedeKamerMotionDocumentsIngest) processMotionCandidates(ctx conte
 				case <-ctx.Done():
 					return
 				}
-				if result.err != nil {
-					cancel()
-					return
-				}
 			}
 		}()
 	}
@@ -157,20 +176,32 @@ func (ingest TweedeKamerMotionDocumentsIngest) processMotionCandidates(ctx conte
 
 	recordsSeen := 0
 	recordsChanged := 0
-	var firstErr error
+	failed := 0
 	for result := range results {
+		progress.addItems(ctx, 1)
 		recordsSeen++
 		if result.changed {
 			recordsChanged++
 		}
-		if result.err != nil && firstErr == nil {
-			firstErr = result.err
+		if result.err != nil {
+			if ctx.Err() != nil {
+				return recordsSeen, recordsChanged, failed, ctx.Err()
+			}
+			failure, err := recordIngestionFailure(ctx, ingest.Pool, motionDocumentsPipeline, result.motion.MotionKey, result.err)
+			if err != nil {
+				return recordsSeen, recordsChanged, failed, err
+			}
+			failed++
+			fmt.Printf("motion=%s failed attempts=%d retry_at=%s error=%v\n", result.motion.MotionKey, failure.Attempts, failure.NextRetryAt.Format(time.RFC3339), result.err)
 			continue
 		}
+		if err := clearIngestionFailure(ctx, ingest.Pool, motionDocumentsPipeline, result.motion.MotionKey); err != nil {
+			return recordsSeen, recordsChanged, failed, err
+		}
 		fmt.Printf("motion=%s outcome=%s bullets=%d\n", result.motion.MotionKey, result.outcome, result.bullets)
 	}
 
-	return recordsSeen, recordsChanged, firstErr
+	return recordsSeen, recordsChanged, failed, nil
 }
 
 func (ingest TweedeKamerMotionDocumentsIngest) processMotionCandidate(ctx context.Context, motion motionDocumentCandidate) motionDocumentResult {
@@ -314,15 +345,22 @@ type motionDocumentCandidate struct {
 func (ingest TweedeKamerMotionDocumentsIngest) motionCandidates(ctx context.Context, resyncBefore, proposedAfter *time.Time) ([]motionDocumentCandidate, error) {
 	rows, err := ingest.Pool.Query(ctx, `
 		SELECT motion_key, source_id
-		FROM motions
+		FROM motions m
 		WHERE source_key = $1
 		  AND source_deleted = false
+		  AND kind = 'Motie'
 		  AND (document_synced_at IS NULL
 		       OR ($3::timestamptz IS NOT NULL AND document_synced_at < $3)
 		       OR ($4::timestamptz IS NOT NULL AND bullet_points IS NULL AND proposed_at > $4))
+		  AND NOT EXISTS (
+		        SELECT 1 FROM ingestion_failures f
+		        WHERE f.source_key = m.source_key
+		          AND f.pipeline = $5
+		          AND f.item_key = m.motion_key
+		          AND f.next_retry_at > now())
 		ORDER BY document_synced_at ASC NULLS FIRST, proposed_at DESC NULLS LAST
 		LIMIT $2
-	`, tweedeKamerSourceKey, ingest.Limit, resyncBefore, proposedAfter)
+	`, tweedeKamerSourceKey, ingest.Limit, resyncBefore, proposedAfter, motionDocumentsPipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -343,13 +381,20 @@ func (ingest TweedeKamerMotionDocumentsIngest) pendingCount(ctx context.Context,
 	var count int
 	err := ingest.Pool.QueryRow(ctx, `
 		SELECT count(*)::int
-		FROM motions
+		FROM motions m
 		WHERE source_key = $1
 		  AND source_deleted = false
+		  AND kind = 'Motie'
 		  AND (document_synced_at IS NULL
 		       OR ($2::timestamptz IS NOT NULL AND document_synced_at < $2)
 		       OR ($3::timestamptz IS NOT NULL AND bullet_points IS NULL AND proposed_at > $3))
-	`, tweedeKamerSourceKey, resyncBefore, proposedAfter).Scan(&count)
+		  AND NOT EXISTS (
+		        SELECT 1 FROM ingestion_failures f
+		        WHERE f.source_key = m.source_key
+		          AND f.pipeline = $4
+		          AND f.item_key = m.motion_key
+		          AND f.next_retry_at > now())
+	`, tweedeKamerSourceKey, resyncBefore, proposedAfter, motionDocumentsPipeline).Scan(&count)
 	return count, err
 }
 
@@ -30,6 +30,9 @@ type TweedeKamerMotionVotesIngest struct {
 	// after a motion is decided; a vergissing is typically filed a week later.
 	// Zero disables outcome-scoped resync.
 	ResyncGrace time.Duration
+	// RetryFailures processes only motions with a recorded failure, whether or
+	// not their retry is due.
+	RetryFailures bool
 }
 
 // settledDecisionTypes are the Besluit types that terminate a motion. These key
@@ -55,6 +58,8 @@ func (ingest TweedeKamerMotionVotesIngest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
 
 	resyncBefore := ingest.resyncBefore()
 	settledBefore := ingest.settledBefore()
@@ -64,13 +69,24 @@ func (ingest TweedeKamerMotionVotesIngest) Run(ctx context.Context) error {
 		return err
 	}
 
-	motions, err := ingest.motionCandidates(ctx, resyncBefore, settledBefore)
+	var motions []motionCandidate
+	if ingest.RetryFailures {
+		motions, err = failedMotionCandidates(ctx, ingest.Pool, motionVotesPipeline, ingest.Limit)
+	} else {
+		motions, err = ingest.motionCandidates(ctx, resyncBefore, settledBefore)
+	}
 	if err != nil {
 		_ = finishPipelineRun(ctx, ingest.Pool, runID, motionVotesPipeline, "failed", 0, 0, false, "error", err.Error())
 		return err
 	}
 
-	recordsSeen, recordsChanged, err := ingest.processMotionCandidates(ctx, motions)
+	// The candidates are the pending motions, capped at the batch limit.
+	progress := newRunProgress(ingest.Pool, runID)
+	progress.setTotal(ctx, len(motions))
+	recordsSeen, recordsChanged, failed, err := ingest.processMotionCandidates(ctx, motions, progress)
+	if err == nil && failed > 0 && failed == len(motions) {
+		err = fmt.Errorf("all %d motions failed, see status failures", failed)
+	}
 	if err != nil {
 		_ = finishPipelineRun(ctx, ingest.Pool, runID, motionVotesPipeline, "failed", recordsSeen, recordsChanged, false, "error", err.Error())
 		return err
@@ -93,7 +109,7 @@ func (ingest TweedeKamerMotionVotesIngest) Run(ctx context.Context) error {
 		return err
 	}
 
-	fmt.Printf("motion vote batch complete run_id=%d motions=%d seen=%d changed=%d pending_before=%d pending_after=%d stop=%s\n", runID, len(motions), recordsSeen, recordsChanged, pendingBefore, pendingAfter, stopReason)
+	fmt.Printf("motion vote batch complete run_id=%d motions=%d seen=%d changed=%d failed=%d pending_before=%d pending_after=%d stop=%s\n", runID, len(motions), recordsSeen, recordsChanged, failed, pendingBefore, pendingAfter, stopReason)
 	return nil
 }
 
@@ -106,9 +122,12 @@ type motionVoteResult struct {
 	err             error
 }
 
-func (ingest TweedeKamerMotionVotesIngest) processMotionCandidates(ctx context.Context, motions []motionCandidate) (int, int, error) {
+// processMotionCandidates syncs every motion it can. A motion that fails is
+// recorded in ingestion_failures and does not stop the others; only a database
+// error or cancellation does.
+func (ingest TweedeKamerMotionVotesIngest) processMotionCandidates(ctx context.Context, motions []motionCandidate, progress *runProgress) (int, int, int, error) {
 	if len(motions) == 0 {
-		return 0, 0, nil
+		return 0, 0, 0, nil
 	}
 
 	workerCount := ingest.Concurrency
@@ -140,10 +159,6 @@ func (ingest TweedeKamerMotionVotesIngest) processMotionCandidates(ctx context.C
 				case <-ctx.Done():
 					return
 				}
-				if result.err != nil {
-					cancel()
-					return
-				}
 			}
 		}()
 	}
@@ -166,18 +181,30 @@ func (ingest TweedeKamerMotionVotesIngest) processMotionCandidates(ctx context.C
 
 	recordsSeen := 0
 	recordsChanged := 0
-	var firstErr error
+	failed := 0
 	for result := range results {
+		progress.addItems(ctx, 1)
 		recordsSeen += result.decisionsSeen + result.votesSeen
 		recordsChanged += result.decisionChanges + result.voteChanges
-		if result.err != nil && firstErr == nil {
-			firstErr = result.err
+		if result.err != nil {
+			if ctx.Err() != nil {
+				return recordsSeen, recordsChanged, failed, ctx.Err()
+			}
+			failure, err := recordIngestionFailure(ctx, ingest.Pool, motionVotesPipeline, result.motion.MotionKey, result.err)
+			if err != nil {
+				return recordsSeen, recordsChanged, failed, err
+			}
+			failed++
+			fmt.Printf("motion=%s failed attempts=%d retry_at=%s error=%v\n", result.motion.MotionKey, failure.Attempts, failure.NextRetryAt.Format(time.RFC3339), result.err)
 			continue
 		}
+		if err := clearIngestionFailure(ctx, ingest.Pool, motionVotesPipeline, result.motion.MotionKey); err != nil {
+			return recordsSeen, recordsChanged, failed, err
+		}
 		fmt.Printf("motion=%s decisions=%d votes=%d changed=%d\n", result.motion.MotionKey, result.decisionsSeen, result.votesSeen, result.decisionChanges+result.voteChanges)
 	}
 
-	return recordsSeen, recordsChanged, firstErr
+	return recordsSeen, recordsChanged, failed, nil
 }
 
 func (ingest TweedeKamerMotionVotesIngest) processMotionCandidate(ctx context.Context, motion motionCandidate) motionVoteResult {
@@ -220,9 +247,15 @@ func (ingest TweedeKamerMotionVotesIngest) motionCandidates(ctx context.Context,
 		               AND d.source_deleted = false
 		               AND d.decision_type = ANY($5::text[])
 		               AND d.source_updated_at <= $4)))
+		  AND NOT EXISTS (
+		        SELECT 1 FROM ingestion_failures f
+		        WHERE f.source_key = m.source_key
+		          AND f.pipeline = $6
+		          AND f.item_key = m.motion_key
+		          AND f.next_retry_at > now())
 		ORDER BY m.votes_synced_at ASC NULLS FIRST, m.proposed_at DESC NULLS LAST
 		LIMIT $2
-	`, tweedeKamerSourceKey, ingest.Limit, resyncBefore, settledBefore, settledDecisionTypes)
+	`, tweedeKamerSourceKey, ingest.Limit, resyncBefore, settledBefore, settledDecisionTypes, motionVotesPipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -254,7 +287,13 @@ func (ingest TweedeKamerMotionVotesIngest) pendingCount(ctx context.Context, res
 		               AND d.source_deleted = false
 		               AND d.decision_type = ANY($4::text[])
 		               AND d.source_updated_at <= $3)))
-	`, tweedeKamerSourceKey, resyncBefore, settledBefore, settledDecisionTypes).Scan(&count)
+		  AND NOT EXISTS (
+		        SELECT 1 FROM ingestion_failures f
+		        WHERE f.source_key = m.source_key
+		          AND f.pipeline = $5
+		          AND f.item_key = m.motion_key
+		          AND f.next_retry_at > now())
+	`, tweedeKamerSourceKey, resyncBefore, settledBefore, settledDecisionTypes, motionVotesPipeline).Scan(&count)
 	return count, err
 }
 
@@ -321,6 +360,15 @@ func (ingest TweedeKamerMotionVotesIngest) storeMotionDecisionsAndVotes(
 	if _, err := tx.Exec(ctx, "UPDATE motions SET votes_synced_at = now(), updated_at = now() WHERE motion_key = $1", motion.MotionKey); err != nil {
 		return 0, 0, 0, err
 	}
+	if err := recordBillFinalDecision(ctx, tx, motion.MotionKey); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := refreshDecisionTallies(ctx, tx, []string{motion.MotionKey}); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := refreshMotionPartyPositions(ctx, tx, []string{motion.MotionKey}); err != nil {
+		return 0, 0, 0, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return 0, 0, 0, err
@@ -329,6 +377,34 @@ func (ingest TweedeKamerMotionVotesIngest) storeMotionDecisionsAndVotes(
 	return decisionChanges, votesSeen, voteChanges, nil
 }
 
+// recordBillFinalDecision keeps bills.final_decision_key pointing at the most
+// recent settling Besluit of a wetsvoorstel. It is a no-op for other kinds.
+func recordBillFinalDecision(ctx context.Context, tx pgx.Tx, motionKey string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO bills (bill_key, final_decision_key, final_decision_type, updated_at)
+		SELECT m.motion_key, d.decision_key, d.decision_type, now()
+		FROM motions m
+		LEFT JOIN LATERAL (
+			SELECT decision_key, decision_type
+			FROM decisions
+			WHERE motion_key = m.motion_key
+			  AND source_deleted = false
+			  AND decision_type = ANY($2::text[])
+			ORDER BY source_updated_at DESC NULLS LAST, decision_order DESC NULLS LAST
+			LIMIT 1
+		) d ON true
+		WHERE m.motion_key = $1
+		  AND m.kind = $3
+		ON CONFLICT (bill_key)
+		DO UPDATE SET final_decision_key = EXCLUDED.final_decision_key,
+		              final_decision_type = EXCLUDED.final_decision_type,
+		              updated_at = now()
+		WHERE bills.final_decision_key IS DISTINCT FROM EXCLUDED.final_decision_key
+		   OR bills.final_decision_type IS DISTINCT FROM EXCLUDED.final_decision_type
+	`, motionKey, settledDecisionTypes, tweedekamer.ZaakSoortWetsvoorstel)
+	return err
+}
+
 func storeDecision(ctx context.Context, tx pgx.Tx, motion motionCandidate, decision tweedekamer.DecisionRecord) (bool, error) {
 	raw := projectDecisionRaw(decision)
 	projected := projectDecision(motion, decision)
@@ -338,7 +414,15 @@ func storeDecision(ctx context.Context, tx pgx.Tx, motion motionCandidate, decis
 		return false, err
 	}
 
-	_, err = tx.Exec(ctx, `
+	if err := upsertDecision(ctx, tx, projected); err != nil {
+		return false, err
+	}
+
+	return rawChanged, nil
+}
+
+func upsertDecision(ctx context.Context, tx pgx.Tx, decision decisionProjection) error {
+	_, err := tx.Exec(ctx, `
 		INSERT INTO decisions (
 			decision_key,
 			source_key,
@@ -368,12 +452,8 @@ func storeDecision(ctx context.Context, tx pgx.Tx, motion motionCandidate, decis
 		              source_updated_at = EXCLUDED.source_updated_at,
 		              source_deleted = EXCLUDED.source_deleted,
 		              updated_at = now()
-	`, projected.DecisionKey, projected.SourceKey, projected.MotionKey, projected.SourceID, projected.AgendaPointSourceID, projected.VotingType, projected.DecisionType, projected.DecisionText, projected.Comment, projected.Status, projected.DecisionOrder, projected.SourceUpdatedAt, projected.SourceDeleted)
-	if err != nil {
-		return false, err
-	}
-
-	return rawChanged, nil
+	`, decision.DecisionKey, decision.SourceKey, decision.MotionKey, decision.SourceID, decision.AgendaPointSourceID, decision.VotingType, decision.DecisionType, decision.DecisionText, decision.Comment, decision.Status, decision.DecisionOrder, decision.SourceUpdatedAt, decision.SourceDeleted)
+	return err
 }
 
 func storeVote(ctx context.Context, tx pgx.Tx, motion motionCandidate, decision tweedekamer.DecisionRecord, vote tweedekamer.VoteRecord) (bool, error) {
@@ -385,7 +465,15 @@ func storeVote(ctx context.Context, tx pgx.Tx, motion motionCandidate, decision
 		return false, err
 	}
 
-	_, err = tx.Exec(ctx, `
+	if err := upsertVote(ctx, tx, projected); err != nil {
+		return false, err
+	}
+
+	return rawChanged, nil
+}
+
+func upsertVote(ctx context.Context, tx pgx.Tx, vote voteProjection) error {
+	_, err := tx.Exec(ctx, `
 		INSERT INTO votes (
 			vote_key,
 			source_key,
@@ -417,12 +505,8 @@ func storeVote(ctx context.Context, tx pgx.Tx, motion motionCandidate, decision
 		              source_updated_at = EXCLUDED.source_updated_at,
 		              source_deleted = EXCLUDED.source_deleted,
 		              updated_at = now()
-	`, projected.VoteKey, projected.SourceKey, projected.MotionKey, projected.DecisionKey, projected.SourceID, projected.VoteType, projected.PartySourceID, projected.PartyName, projected.ActorName, projected.PartySize, projected.Mistake, projected.PersonSourceID, projected.SourceUpdatedAt, projected.SourceDeleted)
-	if err != nil {
-		return false, err
-	}
-
-	return rawChanged, nil
+	`, vote.VoteKey, vote.SourceKey, vote.MotionKey, vote.DecisionKey, vote.SourceID, vote.VoteType, vote.PartySourceID, vote.PartyName, vote.ActorName, vote.PartySize, vote.Mistake, vote.PersonSourceID, vote.SourceUpdatedAt, vote.SourceDeleted)
+	return err
 }
 
 func storeRawRecord(
@@ -454,7 +538,38 @@ func storeRawRecord(
 	if err != nil {
 		return false, err
 	}
-	return tag.RowsAffected() > 0, nil
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	return true, storeRawRecordVersion(ctx, tx, record)
+}
+
+// storeRawRecordVersion appends the payload to raw_record_versions unless it
+// is the one the record's latest version already holds. A payload that
+// changes back is appended again, so the history reads in order.
+func storeRawRecordVersion(ctx context.Context, tx pgx.Tx, record rawRecordProjection) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO raw_record_versions (
+			source_key,
+			collection,
+			source_id,
+			source_updated_at,
+			source_deleted,
+			payload,
+			payload_hash
+		)
+		SELECT $1::text, $2::text, $3::text, $4::timestamptz, $5::boolean, $6::jsonb, $7::text
+		WHERE $7 IS DISTINCT FROM (
+			SELECT payload_hash
+			FROM raw_record_versions
+			WHERE source_key = $1
+			  AND collection = $2
+			  AND source_id = $3
+			ORDER BY version_id DESC
+			LIMIT 1
+		)
+	`, tweedeKamerSourceKey, record.Collection, record.SourceID, record.SourceUpdatedAt, record.SourceDeleted, string(record.Payload), record.PayloadHash)
+	return err
 }
 
 func acquirePipelineLock(ctx context.Context, pool *pgxpool.Pool, pipeline string) (func(), error) {
@@ -499,6 +614,26 @@ func startPipelineRunWithCursor(ctx context.Context, pool *pgxpool.Pool, pipelin
 	return runID, err
 }
 
+// trackRunRequests returns a context that counts the retries and throttled
+// responses of the run's requests, and a func, to be deferred, that stores
+// them on its ingestion_runs row. It runs after the finish update and also
+// records the counts of a cancelled run.
+func trackRunRequests(ctx context.Context, pool *pgxpool.Pool, runID int64) (context.Context, func()) {
+	ctx, counter := tweedekamer.WithRequestCounter(ctx)
+	return ctx, func() {
+		stats := counter.Stats()
+		if stats == (tweedekamer.RequestStats{}) {
+			return
+		}
+		_, _ = pool.Exec(context.WithoutCancel(ctx), `
+			UPDATE ingestion_runs
+			SET retries = $2,
+			    throttled = $3
+			WHERE id = $1
+		`, runID, stats.Retries, stats.Throttled)
+	}
+}
+
 func finishPipelineRun(
 	ctx context.Context,
 	pool *pgxpool.Pool,
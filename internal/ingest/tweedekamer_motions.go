@@ -17,12 +17,18 @@ import (
 const (
 	tweedeKamerSourceKey = "tweedekamer-odata-v2"
 	motionsPipeline      = "motions.raw"
+	amendmentsPipeline   = "amendments.raw"
+	billsPipeline        = "bills.raw"
 	zaakCollection       = "Zaak"
 )
 
+// TweedeKamerMotionIngest syncs zaken of one Soort into motions. Kind defaults
+// to Motie; Amendement and Wetsvoorstel run the same path under their own
+// pipeline and cursor.
 type TweedeKamerMotionIngest struct {
 	Pool          *pgxpool.Pool
 	Client        *tweedekamer.Client
+	Kind          string
 	BatchSize     int
 	MaxPages      int
 	InitialSince  time.Time
@@ -65,6 +71,12 @@ func (ingest TweedeKamerMotionIngest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
+	progress := newRunProgress(ingest.Pool, runID)
+	progress.countTotal(ctx, func(ctx context.Context) (int, error) {
+		return ingest.Client.CountChangedZaken(ctx, ingest.kind(), since)
+	}, ingest.MaxPages, ingest.BatchSize)
 
 	recordsSeen := 0
 	recordsChanged := 0
@@ -75,7 +87,7 @@ func (ingest TweedeKamerMotionIngest) Run(ctx context.Context) error {
 
 	pagesProcessed := 0
 	for page := 1; ; page++ {
-		result, err := ingest.Client.FetchChangedMotions(ctx, since, ingest.BatchSize, skip, nextURL)
+		result, err := ingest.Client.FetchChangedZaken(ctx, ingest.kind(), since, ingest.BatchSize, skip, nextURL)
 		if err != nil {
 			_ = ingest.finishRun(ctx, runID, "failed", cursorBefore, recordsSeen, recordsChanged, false, "error", err.Error())
 			return err
@@ -105,6 +117,7 @@ func (ingest TweedeKamerMotionIngest) Run(ctx context.Context) error {
 
 		hasMore := nextURL != "" || len(result.Records) == ingest.BatchSize
 
+		progress.addPage(ctx, len(result.Records))
 		fmt.Printf("page=%d seen=%d changed=%d next=%t\n", page, recordsSeen, recordsChanged, hasMore)
 
 		if !hasMore {
@@ -147,6 +160,24 @@ func (ingest TweedeKamerMotionIngest) Run(ctx context.Context) error {
 	return nil
 }
 
+func (ingest TweedeKamerMotionIngest) kind() string {
+	if ingest.Kind == "" {
+		return tweedekamer.ZaakSoortMotie
+	}
+	return ingest.Kind
+}
+
+func (ingest TweedeKamerMotionIngest) pipeline() string {
+	switch ingest.kind() {
+	case tweedekamer.ZaakSoortAmendement:
+		return amendmentsPipeline
+	case tweedekamer.ZaakSoortWetsvoorstel:
+		return billsPipeline
+	default:
+		return motionsPipeline
+	}
+}
+
 type source struct {
 	SourceKey       string
 	JurisdictionKey string
@@ -173,23 +204,26 @@ func (ingest TweedeKamerMotionIngest) acquireLock(ctx context.Context) (func(),
 	}
 
 	var acquired bool
-	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", tweedeKamerSourceKey+":"+motionsPipeline).Scan(&acquired); err != nil {
+	pipeline := ingest.pipeline()
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", tweedeKamerSourceKey+":"+pipeline).Scan(&acquired); err != nil {
 		conn.Release()
 		return nil, err
 	}
 	if !acquired {
 		conn.Release()
-		return nil, fmt.Errorf("ingestion pipeline already running: %s/%s", tweedeKamerSourceKey, motionsPipeline)
+		return nil, fmt.Errorf("ingestion pipeline already running: %s/%s", tweedeKamerSourceKey, pipeline)
 	}
 
 	return func() {
-		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", tweedeKamerSourceKey+":"+motionsPipeline)
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", tweedeKamerSourceKey+":"+pipeline)
 		conn.Release()
 	}, nil
 }
 
 type Cursor struct {
 	ApiUpdatedAt *time.Time `json:"apiUpdatedAt"`
+	// SkipToken is the SyncFeed position; OData pipelines leave it nil.
+	SkipToken *int64 `json:"skipToken,omitempty"`
 }
 
 func (ingest TweedeKamerMotionIngest) getCursor(ctx context.Context) (Cursor, error) {
@@ -198,7 +232,7 @@ func (ingest TweedeKamerMotionIngest) getCursor(ctx context.Context) (Cursor, er
 		SELECT cursor
 		FROM source_cursors
 		WHERE source_key = $1 AND pipeline = $2
-	`, tweedeKamerSourceKey, motionsPipeline).Scan(&raw)
+	`, tweedeKamerSourceKey, ingest.pipeline()).Scan(&raw)
 	if err == pgx.ErrNoRows {
 		initial := ingest.InitialSince
 		return Cursor{ApiUpdatedAt: &initial}, nil
@@ -218,7 +252,7 @@ func (ingest TweedeKamerMotionIngest) resetCursor(ctx context.Context) error {
 	_, err := ingest.Pool.Exec(ctx, `
 		DELETE FROM source_cursors
 		WHERE source_key = $1 AND pipeline = $2
-	`, tweedeKamerSourceKey, motionsPipeline)
+	`, tweedeKamerSourceKey, ingest.pipeline())
 	return err
 }
 
@@ -243,7 +277,7 @@ func (ingest TweedeKamerMotionIngest) startRun(ctx context.Context, cursorBefore
 		INSERT INTO ingestion_runs (source_key, pipeline, status, cursor_before)
 		VALUES ($1, $2, 'running', $3)
 		RETURNING id
-	`, tweedeKamerSourceKey, motionsPipeline, string(raw)).Scan(&runID)
+	`, tweedeKamerSourceKey, ingest.pipeline(), string(raw)).Scan(&runID)
 	return runID, err
 }
 
@@ -295,7 +329,7 @@ func (ingest TweedeKamerMotionIngest) saveCursor(ctx context.Context, cursorAfte
 		ON CONFLICT (source_key, pipeline)
 		DO UPDATE SET cursor = EXCLUDED.cursor,
 		              updated_at = now()
-	`, tweedeKamerSourceKey, motionsPipeline, string(raw))
+	`, tweedeKamerSourceKey, ingest.pipeline(), string(raw))
 	return err
 }
 
@@ -313,32 +347,26 @@ func (ingest TweedeKamerMotionIngest) storeMotionRecord(
 	raw := projectMotionRaw(record)
 	motion := projectMotion(jurisdictionKey, record)
 
-	tag, err := tx.Exec(ctx, `
-		INSERT INTO raw_records (
-			source_key,
-			collection,
-			source_id,
-			source_updated_at,
-			source_deleted,
-			payload,
-			payload_hash
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (source_key, collection, source_id)
-		DO UPDATE SET source_updated_at = EXCLUDED.source_updated_at,
-		              source_deleted = EXCLUDED.source_deleted,
-		              payload = EXCLUDED.payload,
-		              payload_hash = EXCLUDED.payload_hash,
-		              last_seen_at = now()
-		WHERE raw_records.payload_hash IS DISTINCT FROM EXCLUDED.payload_hash
-		   OR raw_records.source_updated_at IS DISTINCT FROM EXCLUDED.source_updated_at
-		   OR raw_records.source_deleted IS DISTINCT FROM EXCLUDED.source_deleted
-	`, tweedeKamerSourceKey, raw.Collection, raw.SourceID, raw.SourceUpdatedAt, raw.SourceDeleted, string(raw.Payload), raw.PayloadHash)
+	rawChanged, err := storeRawRecord(ctx, tx, raw)
 	if err != nil {
 		return false, err
 	}
 
-	_, err = tx.Exec(ctx, `
+	if err := upsertMotion(ctx, tx, motion); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return rawChanged, nil
+}
+
+// upsertMotion writes a projected motion and its submitters. It is shared by
+// ingestion and by reprojecting stored payloads.
+func upsertMotion(ctx context.Context, tx pgx.Tx, motion motionProjection) error {
+	_, err := tx.Exec(ctx, `
 		INSERT INTO motions (
 			motion_key,
 			source_key,
@@ -350,13 +378,15 @@ func (ingest TweedeKamerMotionIngest) storeMotionRecord(
 			status,
 			kind,
 			parliamentary_year,
+			dossier_number,
+			dossier_addition,
 			proposed_at,
 			source_updated_at,
 			source_deleted,
 			raw_collection,
 			updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, now())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, now())
 		ON CONFLICT (source_key, source_id)
 		DO UPDATE SET number = EXCLUDED.number,
 		              title = EXCLUDED.title,
@@ -364,20 +394,68 @@ func (ingest TweedeKamerMotionIngest) storeMotionRecord(
 		              status = EXCLUDED.status,
 		              kind = EXCLUDED.kind,
 		              parliamentary_year = EXCLUDED.parliamentary_year,
+		              dossier_number = EXCLUDED.dossier_number,
+		              dossier_addition = EXCLUDED.dossier_addition,
 		              proposed_at = EXCLUDED.proposed_at,
 		              source_updated_at = EXCLUDED.source_updated_at,
 		              source_deleted = EXCLUDED.source_deleted,
 		              updated_at = now()
-	`, motion.MotionKey, motion.SourceKey, motion.JurisdictionKey, motion.SourceID, motion.Number, motion.Title, motion.Subject, motion.Status, motion.Kind, motion.ParliamentaryYear, motion.ProposedAt, motion.SourceUpdatedAt, motion.SourceDeleted, motion.RawCollection)
+	`, motion.MotionKey, motion.SourceKey, motion.JurisdictionKey, motion.SourceID, motion.Number, motion.Title, motion.Subject, motion.Status, motion.Kind, motion.ParliamentaryYear, motion.DossierNumber, motion.DossierAddition, motion.ProposedAt, motion.SourceUpdatedAt, motion.SourceDeleted, motion.RawCollection)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return false, err
+	return storeMotionSubmitters(ctx, tx, motion)
+}
+
+// storeMotionSubmitters upserts the indieners of a motion. The expansion lists
+// every submitter of the zaak, so one that is no longer listed was removed
+// upstream.
+func storeMotionSubmitters(ctx context.Context, tx pgx.Tx, motion motionProjection) error {
+	submitterKeys := make([]string, 0, len(motion.Submitters))
+	for _, submitter := range motion.Submitters {
+		submitterKeys = append(submitterKeys, submitter.SubmitterKey)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO motion_submitters (
+				submitter_key,
+				source_key,
+				motion_key,
+				source_id,
+				relation,
+				actor_name,
+				party_name,
+				function,
+				person_source_id,
+				party_source_id,
+				source_deleted,
+				updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now())
+			ON CONFLICT (submitter_key)
+			DO UPDATE SET motion_key = EXCLUDED.motion_key,
+			              relation = EXCLUDED.relation,
+			              actor_name = EXCLUDED.actor_name,
+			              party_name = EXCLUDED.party_name,
+			              function = EXCLUDED.function,
+			              person_source_id = EXCLUDED.person_source_id,
+			              party_source_id = EXCLUDED.party_source_id,
+			              source_deleted = EXCLUDED.source_deleted,
+			              updated_at = now()
+		`, submitter.SubmitterKey, motion.SourceKey, motion.MotionKey, submitter.SourceID, submitter.Relation, submitter.ActorName, submitter.PartyName, submitter.Function, submitter.PersonSourceID, submitter.PartySourceID, submitter.SourceDeleted)
+		if err != nil {
+			return err
+		}
 	}
 
-	return tag.RowsAffected() > 0, nil
+	_, err := tx.Exec(ctx, `
+		UPDATE motion_submitters
+		SET source_deleted = true,
+		    updated_at = now()
+		WHERE motion_key = $1
+		  AND source_deleted = false
+		  AND NOT (submitter_key = ANY($2::text[]))
+	`, motion.MotionKey, submitterKeys)
+	return err
 }
 
 func title(record tweedekamer.MotionRecord) *string {
@@ -410,6 +488,9 @@ func hashBytes(value []byte) string {
 }
 
 func formatCursor(cursor Cursor) string {
+	if cursor.SkipToken != nil {
+		return fmt.Sprintf("skiptoken:%d", *cursor.SkipToken)
+	}
 	if cursor.ApiUpdatedAt == nil {
 		return "null"
 	}
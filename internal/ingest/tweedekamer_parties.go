@@ -63,6 +63,12 @@ func (ingest TweedeKamerPartyIngest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
+	progress := newRunProgress(ingest.Pool, runID)
+	progress.countTotal(ctx, func(ctx context.Context) (int, error) {
+		return ingest.Client.CountChangedParties(ctx, since)
+	}, ingest.MaxPages, ingest.BatchSize)
 
 	recordsSeen := 0
 	recordsChanged := 0
@@ -102,6 +108,7 @@ func (ingest TweedeKamerPartyIngest) Run(ctx context.Context) error {
 		}
 
 		hasMore := nextURL != "" || len(result.Records) == ingest.BatchSize
+		progress.addPage(ctx, len(result.Records))
 		fmt.Printf("parties page=%d seen=%d changed=%d next=%t\n", page, recordsSeen, recordsChanged, hasMore)
 
 		if !hasMore {
@@ -231,7 +238,19 @@ func (ingest TweedeKamerPartyIngest) storePartyRecord(
 		return false, err
 	}
 
-	_, err = tx.Exec(ctx, `
+	if err := upsertParty(ctx, tx, party); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return rawChanged, nil
+}
+
+func upsertParty(ctx context.Context, tx pgx.Tx, party partyProjection) error {
+	_, err := tx.Exec(ctx, `
 		INSERT INTO parties (
 			party_key,
 			source_key,
@@ -264,13 +283,5 @@ func (ingest TweedeKamerPartyIngest) storePartyRecord(
 		              source_deleted = EXCLUDED.source_deleted,
 		              updated_at = now()
 	`, party.PartyKey, party.SourceKey, party.JurisdictionKey, party.SourceID, party.Number, party.ShortName, party.Name, party.NameEN, party.Seats, party.ElectoralVotes, party.ActiveFrom, party.ActiveTo, party.SourceUpdatedAt, party.SourceDeleted, party.RawCollection)
-	if err != nil {
-		return false, err
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return false, err
-	}
-
-	return rawChanged, nil
+	return err
 }
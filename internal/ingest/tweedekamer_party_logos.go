@@ -45,6 +45,8 @@ func (ingest TweedeKamerPartyLogoIngest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
 
 	fail := func(err error, seen int, changed int) error {
 		_ = finishPipelineRun(ctx, ingest.Pool, runID, partyLogosPipeline, "failed", seen, changed, false, "error", err.Error())
@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"strings"
 	"time"
 
 	"partijgedrag/internal/source/tweedekamer"
@@ -26,10 +27,25 @@ type motionProjection struct {
 	Status            *string
 	Kind              *string
 	ParliamentaryYear *string
+	DossierNumber     *string
+	DossierAddition   *string
 	ProposedAt        *time.Time
 	SourceUpdatedAt   *time.Time
 	SourceDeleted     bool
 	RawCollection     string
+	Submitters        []motionSubmitterProjection
+}
+
+type motionSubmitterProjection struct {
+	SubmitterKey   string
+	SourceID       string
+	Relation       string
+	ActorName      *string
+	PartyName      *string
+	Function       *string
+	PersonSourceID *string
+	PartySourceID  *string
+	SourceDeleted  bool
 }
 
 type partyProjection struct {
@@ -50,6 +66,36 @@ type partyProjection struct {
 	RawCollection   string
 }
 
+type memberProjection struct {
+	MemberKey       string
+	SourceKey       string
+	JurisdictionKey string
+	SourceID        string
+	Number          *int
+	Initials        *string
+	FirstName       *string
+	NamePrefix      *string
+	LastName        *string
+	FullName        *string
+	Gender          *string
+	Function        *string
+	PartyLabel      *string
+	Residence       *string
+	SourceUpdatedAt *time.Time
+	SourceDeleted   bool
+	RawCollection   string
+	Seats           []memberSeatProjection
+}
+
+type memberSeatProjection struct {
+	SeatKey       string
+	PartySourceID *string
+	Function      *string
+	StartedOn     *time.Time
+	EndedOn       *time.Time
+	SourceDeleted bool
+}
+
 type decisionProjection struct {
 	DecisionKey         string
 	SourceKey           string
@@ -88,7 +134,8 @@ func projectMotionRaw(record tweedekamer.MotionRecord) rawRecordProjection {
 }
 
 func projectMotion(jurisdictionKey string, record tweedekamer.MotionRecord) motionProjection {
-	return motionProjection{
+	dossierNumber, dossierAddition := motionDossier(record)
+	motion := motionProjection{
 		MotionKey:         motionKey(record.ID),
 		SourceKey:         tweedeKamerSourceKey,
 		JurisdictionKey:   jurisdictionKey,
@@ -99,11 +146,49 @@ func projectMotion(jurisdictionKey string, record tweedekamer.MotionRecord) moti
 		Status:            record.Status,
 		Kind:              record.Soort,
 		ParliamentaryYear: record.Vergaderjaar,
+		DossierNumber:     dossierNumber,
+		DossierAddition:   dossierAddition,
 		ProposedAt:        proposedAt(record),
 		SourceUpdatedAt:   timePtr(record.ApiGewijzigdOp),
 		SourceDeleted:     boolValue(record.Verwijderd),
 		RawCollection:     zaakCollection,
 	}
+	for _, actor := range record.Actors {
+		if actor.Relatie == nil {
+			continue
+		}
+		motion.Submitters = append(motion.Submitters, motionSubmitterProjection{
+			SubmitterKey:   submitterKey(actor.ID),
+			SourceID:       actor.ID,
+			Relation:       *actor.Relatie,
+			ActorName:      actor.ActorNaam,
+			PartyName:      actor.ActorFractie,
+			Function:       actor.Functie,
+			PersonSourceID: actor.PersoonID,
+			PartySourceID:  actor.FractieID,
+			SourceDeleted:  boolValue(actor.Verwijderd),
+		})
+	}
+	return motion
+}
+
+// motionDossier returns the Kamerstukdossier number and toevoeging of a zaak.
+// A zaak belongs to at most one dossier in practice; the first is used.
+func motionDossier(record tweedekamer.MotionRecord) (*string, *string) {
+	if len(record.Dossiers) == 0 {
+		return nil, nil
+	}
+	dossier := record.Dossiers[0]
+	number := strings.TrimSpace(dossier.Nummer.String())
+	if number == "" {
+		return nil, nil
+	}
+	var addition *string
+	if dossier.Toevoeging != nil && strings.TrimSpace(*dossier.Toevoeging) != "" {
+		value := strings.TrimSpace(*dossier.Toevoeging)
+		addition = &value
+	}
+	return &number, addition
 }
 
 func projectPartyRaw(record tweedekamer.PartyRecord) rawRecordProjection {
@@ -146,6 +231,71 @@ func projectParty(jurisdictionKey string, record tweedekamer.PartyRecord) partyP
 	}
 }
 
+func projectMemberRaw(record tweedekamer.MemberRecord) rawRecordProjection {
+	return projectRawRecord(persoonCollection, record.ID, record.ApiGewijzigdOp, record.Verwijderd, record.Raw)
+}
+
+func projectMember(jurisdictionKey string, record tweedekamer.MemberRecord) memberProjection {
+	member := memberProjection{
+		MemberKey:       memberKey(record.ID),
+		SourceKey:       tweedeKamerSourceKey,
+		JurisdictionKey: jurisdictionKey,
+		SourceID:        record.ID,
+		Number:          record.Nummer,
+		Initials:        record.Initialen,
+		FirstName:       memberFirstName(record),
+		NamePrefix:      record.Tussenvoegsel,
+		LastName:        record.Achternaam,
+		FullName:        memberFullName(record),
+		Gender:          record.Geslacht,
+		Function:        record.Functie,
+		PartyLabel:      record.Fractielabel,
+		Residence:       record.Woonplaats,
+		SourceUpdatedAt: timePtr(record.ApiGewijzigdOp),
+		SourceDeleted:   boolValue(record.Verwijderd),
+		RawCollection:   persoonCollection,
+	}
+	for _, seat := range record.FractieZetels {
+		projected := memberSeatProjection{
+			SeatKey:       tweedeKamerSourceKey + ":seat:" + seat.ID,
+			Function:      seat.Functie,
+			StartedOn:     timePtr(seat.Van),
+			EndedOn:       timePtr(seat.TotEnMet),
+			SourceDeleted: boolValue(seat.Verwijderd),
+		}
+		if seat.FractieZetel != nil {
+			projected.PartySourceID = seat.FractieZetel.FractieID
+		}
+		member.Seats = append(member.Seats, projected)
+	}
+	return member
+}
+
+// memberFirstName prefers the roepnaam: "Pieter Heerma" is how a member is
+// known, not "Pieter Hendrik Heerma".
+func memberFirstName(record tweedekamer.MemberRecord) *string {
+	for _, value := range []*string{record.Roepnaam, record.Voornamen} {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			return value
+		}
+	}
+	return nil
+}
+
+func memberFullName(record tweedekamer.MemberRecord) *string {
+	parts := []string{}
+	for _, value := range []*string{memberFirstName(record), record.Tussenvoegsel, record.Achternaam} {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			parts = append(parts, strings.TrimSpace(*value))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	fullName := strings.Join(parts, " ")
+	return &fullName
+}
+
 func projectDecisionRaw(record tweedekamer.DecisionRecord) rawRecordProjection {
 	return projectRawRecord(besluitCollection, record.ID, record.ApiGewijzigdOp, record.Verwijderd, record.Raw)
 }
@@ -209,3 +359,11 @@ func boolValue(value *bool) bool {
 func partyKey(sourceID string) string {
 	return tweedeKamerSourceKey + ":party:" + sourceID
 }
+
+func memberKey(sourceID string) string {
+	return tweedeKamerSourceKey + ":member:" + sourceID
+}
+
+func submitterKey(sourceID string) string {
+	return tweedeKamerSourceKey + ":submitter:" + sourceID
+}
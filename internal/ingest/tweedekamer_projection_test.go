@@ -44,6 +44,35 @@ func TestProjectMotionFromFixture(t *testing.T) {
 	if motion.SourceDeleted {
 		t.Fatal("motion.SourceDeleted = true, want false")
 	}
+	if motion.DossierNumber != nil {
+		t.Fatalf("motion.DossierNumber = %q, want nil", *motion.DossierNumber)
+	}
+
+	if len(motion.Submitters) != 2 {
+		t.Fatalf("len(motion.Submitters) = %d, want 2", len(motion.Submitters))
+	}
+	indiener := motion.Submitters[0]
+	assertString(t, indiener.SubmitterKey, "tweedekamer-odata-v2:submitter:6f0e2d4c-1b3a-4e59-8d7c-2a1b0c9d8e7f")
+	assertString(t, indiener.Relation, tweedekamer.ZaakActorIndiener)
+	assertStringPtr(t, indiener.PersonSourceID, "5b9a1e08-7c4d-4a2b-9f60-1d2e3c4b5a69")
+	assertStringPtr(t, indiener.PartySourceID, "cda-fractie-id")
+	assertStringPtr(t, indiener.PartyName, "CDA")
+	medeindiener := motion.Submitters[1]
+	assertString(t, medeindiener.Relation, tweedekamer.ZaakActorMedeindiener)
+	if medeindiener.PersonSourceID != nil {
+		t.Fatalf("medeindiener.PersonSourceID = %q, want nil", *medeindiener.PersonSourceID)
+	}
+}
+
+func TestProjectAmendmentLinksDossier(t *testing.T) {
+	record := readFixture[tweedekamer.MotionRecord](t, "testdata/tweedekamer_amendment.json")
+
+	motion := projectMotion("nl-tweede-kamer", record)
+	assertStringPtr(t, motion.Kind, tweedekamer.ZaakSoortAmendement)
+	assertStringPtr(t, motion.DossierNumber, "36760")
+	if motion.DossierAddition != nil {
+		t.Fatalf("motion.DossierAddition = %q, want nil", *motion.DossierAddition)
+	}
 }
 
 func TestProjectPartyFromFixture(t *testing.T) {
@@ -81,6 +110,43 @@ func TestProjectPartyFromFixture(t *testing.T) {
 	}
 }
 
+func TestProjectMemberFromFixture(t *testing.T) {
+	record := readFixture[tweedekamer.MemberRecord](t, "testdata/tweedekamer_member.json")
+
+	raw := projectMemberRaw(record)
+	if raw.Collection != persoonCollection {
+		t.Fatalf("raw.Collection = %q, want %q", raw.Collection, persoonCollection)
+	}
+	if raw.PayloadHash != hashBytes(record.Raw) {
+		t.Fatalf("raw.PayloadHash = %q, want hash of fixture", raw.PayloadHash)
+	}
+
+	member := projectMember("nl-tweede-kamer", record)
+	assertString(t, member.MemberKey, "tweedekamer-odata-v2:member:5b9a1e08-7c4d-4a2b-9f60-1d2e3c4b5a69")
+	assertString(t, member.SourceKey, tweedeKamerSourceKey)
+	assertStringPtr(t, member.FirstName, "Pieter")
+	assertStringPtr(t, member.FullName, "Pieter Heerma")
+	assertStringPtr(t, member.PartyLabel, "CDA")
+	assertIntPtr(t, member.Number, 4321)
+	if member.NamePrefix != nil {
+		t.Fatalf("member.NamePrefix = %v, want nil", member.NamePrefix)
+	}
+
+	if len(member.Seats) != 1 {
+		t.Fatalf("len(member.Seats) = %d, want 1", len(member.Seats))
+	}
+	seat := member.Seats[0]
+	assertString(t, seat.SeatKey, "tweedekamer-odata-v2:seat:0c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f")
+	assertStringPtr(t, seat.PartySourceID, "cda-fractie-id")
+	assertStringPtr(t, seat.Function, "Fractievoorzitter")
+	if got := seat.StartedOn.Format("2006-01-02"); got != "2026-02-23" {
+		t.Fatalf("seat.StartedOn = %q", got)
+	}
+	if seat.EndedOn != nil {
+		t.Fatalf("seat.EndedOn = %v, want nil", seat.EndedOn)
+	}
+}
+
 func TestProjectDecisionAndVoteFromFixtures(t *testing.T) {
 	decisionRecord := readFixture[tweedekamer.DecisionRecord](t, "testdata/tweedekamer_decision.json")
 	voteRecord := readFixture[tweedekamer.VoteRecord](t, "testdata/tweedekamer_vote.json")
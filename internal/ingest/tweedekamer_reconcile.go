@@ -0,0 +1,426 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/source/tweedekamer"
+	"partijgedrag/internal/source/tweedekamer/syncfeed"
+)
+
+const (
+	reconcilePipeline = "reconcile"
+	// reconcileSampleSize bounds the ids stored per window for /data-quality.
+	reconcileSampleSize = 20
+)
+
+// ReconcileCollections are the OData collections Reconcile compares, in the
+// order they are compared and repaired: a Besluit can only be routed to a
+// motion that is stored.
+var ReconcileCollections = []string{
+	fractieCollection,
+	zaakCollection,
+	besluitCollection,
+	stemmingCollection,
+}
+
+type ReconcileOptions struct {
+	// Collections defaults to ReconcileCollections.
+	Collections []string
+	// Since and Until bound the zaken compared by GestartOp. The range is
+	// compared per calendar month; Until defaults to now.
+	Since time.Time
+	Until time.Time
+	// Full diffs the id sets of every window, not only of the windows whose
+	// counts differ.
+	Full bool
+	// Apply refetches the records missing locally and tombstones the ones
+	// gone upstream. Without it the run only reports.
+	Apply bool
+}
+
+type ReconcileStats struct {
+	Windows []ReconcileWindow
+}
+
+// ReconcileWindow compares one collection over the zaken started in
+// [From, To). Fractie has a single window with zero bounds.
+type ReconcileWindow struct {
+	Collection  string
+	From        time.Time
+	To          time.Time
+	RemoteCount int
+	LocalCount  int
+	// Compared is set when the id sets were diffed. MissingLocal and
+	// MissingRemote are only known then.
+	Compared      bool
+	MissingLocal  []string
+	MissingRemote []string
+	// Refetched and Tombstoned count the rows Apply wrote.
+	Refetched  int
+	Tombstoned int
+}
+
+func (window ReconcileWindow) Label() string {
+	if window.From.IsZero() {
+		return "all"
+	}
+	return window.From.Format("2006-01")
+}
+
+func (window ReconcileWindow) Differs() bool {
+	return window.RemoteCount != window.LocalCount || len(window.MissingLocal) > 0 || len(window.MissingRemote) > 0
+}
+
+// Reconcile compares the OData $count and id sets of Zaak, Besluit, Stemming
+// and Fractie against motions, decisions, votes and parties, to catch records
+// the change feeds never delivered and deletions they never announced.
+//
+// Besluiten and Stemmingen are only stored for vote-synced motions, so remote
+// records of motions that still wait for the motion-votes pipeline are not
+// reported missing; their window counts do differ and are always diffed.
+func Reconcile(ctx context.Context, pool *pgxpool.Pool, client *tweedekamer.Client, options ReconcileOptions) (ReconcileStats, error) {
+	collections := options.Collections
+	if len(collections) == 0 {
+		collections = ReconcileCollections
+	}
+	for _, collection := range collections {
+		if !slices.Contains(ReconcileCollections, collection) {
+			return ReconcileStats{}, fmt.Errorf("unsupported collection %q", collection)
+		}
+	}
+	until := options.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	if !options.Since.Before(until) {
+		return ReconcileStats{}, fmt.Errorf("reconcile since %s is not before %s", options.Since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	releaseLock, err := acquirePipelineLock(ctx, pool, reconcilePipeline)
+	if err != nil {
+		return ReconcileStats{}, err
+	}
+	defer releaseLock()
+
+	source, err := TweedeKamerMotionIngest{Pool: pool}.getSource(ctx)
+	if err != nil {
+		return ReconcileStats{}, err
+	}
+	reconciler := reconciler{
+		pool:            pool,
+		client:          client,
+		jurisdictionKey: source.JurisdictionKey,
+		options:         options,
+	}
+
+	stats := ReconcileStats{}
+	for _, collection := range collections {
+		windows := []ReconcileWindow{{Collection: collection}}
+		if collection != fractieCollection {
+			windows = monthWindows(collection, options.Since, until)
+		}
+		for _, window := range windows {
+			if err := reconciler.reconcile(ctx, &window); err != nil {
+				return stats, fmt.Errorf("reconcile %s %s: %w", collection, window.Label(), err)
+			}
+			if err := saveReconcileWindow(ctx, pool, window); err != nil {
+				return stats, err
+			}
+			stats.Windows = append(stats.Windows, window)
+		}
+	}
+	return stats, nil
+}
+
+type reconciler struct {
+	pool            *pgxpool.Pool
+	client          *tweedekamer.Client
+	jurisdictionKey string
+	options         ReconcileOptions
+}
+
+func (reconciler reconciler) reconcile(ctx context.Context, window *ReconcileWindow) error {
+	filter, err := tweedekamer.ReconcileFilter(window.Collection, reconcileSoorten(), window.From, window.To)
+	if err != nil {
+		return err
+	}
+	window.RemoteCount, err = reconciler.client.CountEntities(ctx, window.Collection, filter)
+	if err != nil {
+		return err
+	}
+	local, err := reconciler.loadIDs(ctx, reconcileLocalSQL[window.Collection], window)
+	if err != nil {
+		return err
+	}
+	window.LocalCount = len(local)
+	if window.RemoteCount == window.LocalCount && !reconciler.options.Full {
+		return nil
+	}
+
+	remote, err := reconciler.client.FetchEntityRefs(ctx, window.Collection, filter)
+	if err != nil {
+		return err
+	}
+	var scope []string
+	if scopeSQL, ok := reconcileScopeSQL[window.Collection]; ok {
+		if scope, err = reconciler.loadIDs(ctx, scopeSQL, window); err != nil {
+			return err
+		}
+	}
+	window.Compared = true
+	window.MissingLocal, window.MissingRemote = diffEntityRefs(remote, local, scope)
+
+	if reconciler.options.Apply {
+		return reconciler.apply(ctx, window, remote)
+	}
+	return nil
+}
+
+// apply refetches what is missing locally and tombstones what is missing
+// upstream. A local record outside the remote window may only have moved,
+// e.g. after a correction of GestartOp, so it is tombstoned only when it is
+// gone or Verwijderd upstream and refetched otherwise.
+func (reconciler reconciler) apply(ctx context.Context, window *ReconcileWindow, remote []tweedekamer.EntityRef) error {
+	parents := map[string][]string{}
+	for _, ref := range remote {
+		parents[ref.ID] = ref.ParentIDs
+	}
+	refKey := map[string]string{besluitCollection: "zaak", stemmingCollection: "besluit"}[window.Collection]
+
+	refetch := []syncfeed.Entry{}
+	for _, id := range window.MissingLocal {
+		entry := syncfeed.Entry{ID: id}
+		if refKey != "" {
+			entry.Refs = map[string][]string{refKey: parents[id]}
+		}
+		refetch = append(refetch, entry)
+	}
+
+	live, err := reconciler.client.FetchLiveIDs(ctx, window.Collection, window.MissingRemote)
+	if err != nil {
+		return err
+	}
+	tombstone := []syncfeed.Entry{}
+	for _, id := range window.MissingRemote {
+		if slices.Contains(live, id) {
+			refetch = append(refetch, syncfeed.Entry{ID: id})
+		} else {
+			tombstone = append(tombstone, syncfeed.Entry{ID: id, Deleted: true})
+		}
+	}
+
+	feed := TweedeKamerSyncFeedIngest{Pool: reconciler.pool, Client: reconciler.client}
+	if window.Tombstoned, err = feed.applyEntries(ctx, reconciler.jurisdictionKey, window.Collection, tombstone); err != nil {
+		return err
+	}
+	window.Refetched, err = feed.applyEntries(ctx, reconciler.jurisdictionKey, window.Collection, refetch)
+	return err
+}
+
+// reconcileLocalSQL lists the live local ids per collection. $2 are the zaak
+// soorten, $3 and $4 the GestartOp window; Fractie only takes $1.
+var reconcileLocalSQL = map[string]string{
+	fractieCollection: `
+		SELECT source_id
+		FROM parties
+		WHERE source_key = $1
+		  AND source_deleted = false
+	`,
+	zaakCollection: `
+		SELECT source_id
+		FROM motions
+		WHERE source_key = $1
+		  AND source_deleted = false
+		  AND kind = ANY($2::text[])
+		  AND proposed_at >= $3
+		  AND proposed_at < $4
+	`,
+	besluitCollection: `
+		SELECT d.source_id
+		FROM decisions d
+		JOIN motions m ON m.motion_key = d.motion_key
+		WHERE d.source_key = $1
+		  AND d.source_deleted = false
+		  AND m.kind = ANY($2::text[])
+		  AND m.proposed_at >= $3
+		  AND m.proposed_at < $4
+	`,
+	stemmingCollection: `
+		SELECT v.source_id
+		FROM votes v
+		JOIN motions m ON m.motion_key = v.motion_key
+		WHERE v.source_key = $1
+		  AND v.source_deleted = false
+		  AND m.kind = ANY($2::text[])
+		  AND m.proposed_at >= $3
+		  AND m.proposed_at < $4
+	`,
+}
+
+// reconcileScopeSQL lists the parents a remote Besluit or Stemming must hang
+// off to be expected locally: vote-synced motions and their decisions.
+var reconcileScopeSQL = map[string]string{
+	besluitCollection: `
+		SELECT source_id
+		FROM motions
+		WHERE source_key = $1
+		  AND votes_synced_at IS NOT NULL
+		  AND kind = ANY($2::text[])
+		  AND proposed_at >= $3
+		  AND proposed_at < $4
+	`,
+	stemmingCollection: `
+		SELECT d.source_id
+		FROM decisions d
+		JOIN motions m ON m.motion_key = d.motion_key
+		WHERE d.source_key = $1
+		  AND d.source_deleted = false
+		  AND m.votes_synced_at IS NOT NULL
+		  AND m.kind = ANY($2::text[])
+		  AND m.proposed_at >= $3
+		  AND m.proposed_at < $4
+	`,
+}
+
+func (reconciler reconciler) loadIDs(ctx context.Context, query string, window *ReconcileWindow) ([]string, error) {
+	arguments := []any{tweedeKamerSourceKey}
+	if !window.From.IsZero() {
+		arguments = append(arguments, reconcileSoorten(), window.From, window.To)
+	}
+	rows, err := reconciler.pool.Query(ctx, query, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func reconcileSoorten() []string {
+	return []string{tweedekamer.ZaakSoortMotie, tweedekamer.ZaakSoortAmendement, tweedekamer.ZaakSoortWetsvoorstel}
+}
+
+// monthWindows splits [since, until) into calendar months in UTC. The first
+// and last windows are clipped to the range.
+func monthWindows(collection string, since time.Time, until time.Time) []ReconcileWindow {
+	windows := []ReconcileWindow{}
+	since, until = since.UTC(), until.UTC()
+	for from := since; from.Before(until); {
+		to := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		if to.After(until) {
+			to = until
+		}
+		windows = append(windows, ReconcileWindow{Collection: collection, From: from, To: to})
+		from = to
+	}
+	return windows
+}
+
+// diffEntityRefs returns the remote ids absent locally and the local ids
+// absent remotely, both sorted. scope, when set, drops remote records none of
+// whose parents is in it: those are not expected locally yet.
+func diffEntityRefs(remote []tweedekamer.EntityRef, local []string, scope []string) ([]string, []string) {
+	localIDs := map[string]bool{}
+	for _, id := range local {
+		localIDs[id] = true
+	}
+	scoped := map[string]bool{}
+	for _, id := range scope {
+		scoped[id] = true
+	}
+
+	missingLocal := []string{}
+	remoteIDs := map[string]bool{}
+	for _, ref := range remote {
+		remoteIDs[ref.ID] = true
+		if localIDs[ref.ID] {
+			continue
+		}
+		if scope != nil && !slices.ContainsFunc(ref.ParentIDs, func(id string) bool { return scoped[id] }) {
+			continue
+		}
+		missingLocal = append(missingLocal, ref.ID)
+	}
+
+	missingRemote := []string{}
+	for _, id := range local {
+		if !remoteIDs[id] {
+			missingRemote = append(missingRemote, id)
+		}
+	}
+	slices.Sort(missingLocal)
+	slices.Sort(missingRemote)
+	return missingLocal, missingRemote
+}
+
+func saveReconcileWindow(ctx context.Context, pool *pgxpool.Pool, window ReconcileWindow) error {
+	var from, to *time.Time
+	if !window.From.IsZero() {
+		from, to = &window.From, &window.To
+	}
+	sample := func(ids []string) []string {
+		return ids[:min(len(ids), reconcileSampleSize)]
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO reconcile_results (
+			source_key,
+			collection,
+			window_label,
+			window_start,
+			window_end,
+			remote_count,
+			local_count,
+			compared,
+			missing_local,
+			missing_remote,
+			missing_local_sample,
+			missing_remote_sample,
+			refetched,
+			tombstoned,
+			checked_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, now())
+		ON CONFLICT (source_key, collection, window_label) DO UPDATE SET
+			window_start = EXCLUDED.window_start,
+			window_end = EXCLUDED.window_end,
+			remote_count = EXCLUDED.remote_count,
+			local_count = EXCLUDED.local_count,
+			compared = EXCLUDED.compared,
+			missing_local = EXCLUDED.missing_local,
+			missing_remote = EXCLUDED.missing_remote,
+			missing_local_sample = EXCLUDED.missing_local_sample,
+			missing_remote_sample = EXCLUDED.missing_remote_sample,
+			refetched = EXCLUDED.refetched,
+			tombstoned = EXCLUDED.tombstoned,
+			checked_at = EXCLUDED.checked_at
+	`,
+		tweedeKamerSourceKey,
+		window.Collection,
+		window.Label(),
+		from,
+		to,
+		window.RemoteCount,
+		window.LocalCount,
+		window.Compared,
+		len(window.MissingLocal),
+		len(window.MissingRemote),
+		sample(window.MissingLocal),
+		sample(window.MissingRemote),
+		window.Refetched,
+		window.Tombstoned,
+	)
+	return err
+}
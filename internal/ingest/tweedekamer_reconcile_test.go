@@ -0,0 +1,52 @@
+package ingest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"partijgedrag/internal/source/tweedekamer"
+)
+
+func TestMonthWindowsClipToTheRange(t *testing.T) {
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	windows := monthWindows(zaakCollection, since, until)
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows, want 3", len(windows))
+	}
+	if !windows[0].From.Equal(since) || !windows[0].To.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("first window %s - %s", windows[0].From, windows[0].To)
+	}
+	if !windows[2].To.Equal(until) || windows[2].Label() != "2024-03" {
+		t.Fatalf("last window %s - %s labelled %s", windows[2].From, windows[2].To, windows[2].Label())
+	}
+	if got := (ReconcileWindow{Collection: fractieCollection}).Label(); got != "all" {
+		t.Fatalf("Fractie window labelled %q", got)
+	}
+}
+
+func TestDiffEntityRefs(t *testing.T) {
+	remote := []tweedekamer.EntityRef{
+		{ID: "b1", ParentIDs: []string{"z1"}},
+		{ID: "b2", ParentIDs: []string{"z1"}},
+		// Its motion was not vote-synced yet, so it is not expected locally.
+		{ID: "b3", ParentIDs: []string{"z2"}},
+		{ID: "b4", ParentIDs: []string{"z2", "z1"}},
+	}
+	local := []string{"b1", "b9"}
+
+	missingLocal, missingRemote := diffEntityRefs(remote, local, []string{"z1"})
+	if !slices.Equal(missingLocal, []string{"b2", "b4"}) {
+		t.Fatalf("missing locally %v, want [b2 b4]", missingLocal)
+	}
+	if !slices.Equal(missingRemote, []string{"b9"}) {
+		t.Fatalf("missing upstream %v, want [b9]", missingRemote)
+	}
+
+	missingLocal, _ = diffEntityRefs(remote, local, nil)
+	if !slices.Equal(missingLocal, []string{"b2", "b3", "b4"}) {
+		t.Fatalf("unscoped missing locally %v", missingLocal)
+	}
+}
@@ -0,0 +1,418 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/source/tweedekamer"
+)
+
+const reprojectPipeline = "reproject"
+
+// ReprojectCollections are the raw_records collections Reproject can replay,
+// in the order they are replayed: a Besluit or Stemming is only replayed onto
+// a motion that exists.
+var ReprojectCollections = []string{
+	fractieCollection,
+	persoonCollection,
+	zaakCollection,
+	besluitCollection,
+	stemmingCollection,
+}
+
+type ReprojectOptions struct {
+	// Collections defaults to ReprojectCollections.
+	Collections []string
+	// Apply writes the changes. Without it every record is rolled back after
+	// it was compared, so the run only reports what would change.
+	Apply     bool
+	BatchSize int
+	// ExampleLimit bounds the changed records listed per collection.
+	ExampleLimit int
+}
+
+type ReprojectStats struct {
+	Collections []CollectionReprojectStats
+}
+
+type CollectionReprojectStats struct {
+	Collection string
+	Seen       int
+	Changed    int
+	// Skipped counts Besluiten and Stemmingen without a projected row. Their
+	// payload does not name the motion they belong to, so they can only be
+	// replayed onto a row the vote pipeline wrote before.
+	Skipped int
+	// Fields counts changed records per column of the projected row.
+	Fields   map[string]int
+	Examples []ReprojectChange
+}
+
+type ReprojectChange struct {
+	SourceID string
+	Fields   []string
+}
+
+// reprojectRow is one stored payload. MotionKey and DecisionSourceID come from
+// the projected rows of Besluiten and Stemmingen and are nil otherwise.
+type reprojectRow struct {
+	SourceID         string
+	Payload          []byte
+	MotionKey        *string
+	DecisionSourceID *string
+}
+
+type reprojector struct {
+	// load reads a batch of payloads after source id $3.
+	load string
+	// snapshot returns the projected row of key $1, and its child rows, as
+	// jsonb without the $2 columns.
+	snapshot string
+	key      func(sourceID string) string
+	apply    func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error
+	// refresh rebuilds what is derived from the rows of the changed motions,
+	// once per batch. It is nil for collections nothing is derived from.
+	refresh func(ctx context.Context, tx pgx.Tx, motionKeys []string) error
+}
+
+// reprojectIgnoredColumns only record when a row was written.
+var reprojectIgnoredColumns = []string{"created_at", "updated_at"}
+
+var reprojectors = map[string]reprojector{
+	fractieCollection: {
+		load: reprojectLoadSQL(`NULL::text, NULL::text`, ``),
+		snapshot: `
+			SELECT to_jsonb(p) - $2::text[]
+			FROM parties p
+			WHERE p.party_key = $1
+		`,
+		key: partyKey,
+		apply: func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error {
+			var record tweedekamer.PartyRecord
+			if err := json.Unmarshal(row.Payload, &record); err != nil {
+				return err
+			}
+			return upsertParty(ctx, tx, projectParty(jurisdictionKey, record))
+		},
+	},
+	persoonCollection: {
+		load: reprojectLoadSQL(`NULL::text, NULL::text`, ``),
+		snapshot: `
+			SELECT (to_jsonb(m) - $2::text[]) || jsonb_build_object('seats', COALESCE((
+				SELECT jsonb_agg(to_jsonb(s) - $2::text[] ORDER BY s.seat_key)
+				FROM member_party_seats s
+				WHERE s.member_key = m.member_key
+			), '[]'::jsonb))
+			FROM members m
+			WHERE m.member_key = $1
+		`,
+		key: memberKey,
+		apply: func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error {
+			var record tweedekamer.MemberRecord
+			if err := json.Unmarshal(row.Payload, &record); err != nil {
+				return err
+			}
+			return upsertMember(ctx, tx, projectMember(jurisdictionKey, record))
+		},
+	},
+	zaakCollection: {
+		load: reprojectLoadSQL(`NULL::text, NULL::text`, ``),
+		snapshot: `
+			SELECT (to_jsonb(m) - $2::text[]) || jsonb_build_object('submitters', COALESCE((
+				SELECT jsonb_agg(to_jsonb(s) - $2::text[] ORDER BY s.submitter_key)
+				FROM motion_submitters s
+				WHERE s.motion_key = m.motion_key
+			), '[]'::jsonb))
+			FROM motions m
+			WHERE m.motion_key = $1
+		`,
+		key: motionKey,
+		apply: func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error {
+			var record tweedekamer.MotionRecord
+			if err := json.Unmarshal(row.Payload, &record); err != nil {
+				return err
+			}
+			return upsertMotion(ctx, tx, projectMotion(jurisdictionKey, record))
+		},
+	},
+	besluitCollection: {
+		load: reprojectLoadSQL(`d.motion_key, NULL::text`, `
+			LEFT JOIN decisions d ON d.source_key = r.source_key
+			                     AND d.source_id = r.source_id
+		`),
+		snapshot: `
+			SELECT to_jsonb(d) - $2::text[]
+			FROM decisions d
+			WHERE d.decision_key = $1
+		`,
+		key: decisionKey,
+		apply: func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error {
+			var record tweedekamer.DecisionRecord
+			if err := json.Unmarshal(row.Payload, &record); err != nil {
+				return err
+			}
+			motion := motionCandidate{MotionKey: *row.MotionKey}
+			if err := upsertDecision(ctx, tx, projectDecision(motion, record)); err != nil {
+				return err
+			}
+			return recordBillFinalDecision(ctx, tx, motion.MotionKey)
+		},
+		refresh: func(ctx context.Context, tx pgx.Tx, motionKeys []string) error {
+			return refreshDecisionTallies(ctx, tx, motionKeys)
+		},
+	},
+	stemmingCollection: {
+		load: reprojectLoadSQL(`v.motion_key, d.source_id`, `
+			LEFT JOIN votes v ON v.source_key = r.source_key
+			                 AND v.source_id = r.source_id
+			LEFT JOIN decisions d ON d.decision_key = v.decision_key
+		`),
+		snapshot: `
+			SELECT to_jsonb(v) - $2::text[]
+			FROM votes v
+			WHERE v.vote_key = $1
+		`,
+		key: voteKey,
+		apply: func(ctx context.Context, tx pgx.Tx, jurisdictionKey string, row reprojectRow) error {
+			var record tweedekamer.VoteRecord
+			if err := json.Unmarshal(row.Payload, &record); err != nil {
+				return err
+			}
+			motion := motionCandidate{MotionKey: *row.MotionKey}
+			decision := tweedekamer.DecisionRecord{ID: *row.DecisionSourceID}
+			return upsertVote(ctx, tx, projectVote(motion, decision, record))
+		},
+		refresh: func(ctx context.Context, tx pgx.Tx, motionKeys []string) error {
+			if err := refreshDecisionTallies(ctx, tx, motionKeys); err != nil {
+				return err
+			}
+			return refreshMotionPartyPositions(ctx, tx, motionKeys)
+		},
+	},
+}
+
+func reprojectLoadSQL(parentColumns string, joins string) string {
+	return `
+		SELECT r.source_id, r.payload, ` + parentColumns + `
+		FROM raw_records r
+		` + joins + `
+		WHERE r.source_key = $1
+		  AND r.collection = $2
+		  AND r.source_id > $3
+		ORDER BY r.source_id
+		LIMIT $4
+	`
+}
+
+// Reproject replays the payloads in raw_records through the projection code,
+// so a projection fix reaches existing rows without refetching them. Each
+// record is compared before and after in a savepoint, which is released only
+// when the projected row changed and Apply is set.
+func Reproject(ctx context.Context, pool *pgxpool.Pool, options ReprojectOptions) (ReprojectStats, error) {
+	collections := options.Collections
+	if len(collections) == 0 {
+		collections = ReprojectCollections
+	}
+	for _, collection := range collections {
+		if _, ok := reprojectors[collection]; !ok {
+			return ReprojectStats{}, fmt.Errorf("unsupported collection %q", collection)
+		}
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	releaseLock, err := acquirePipelineLock(ctx, pool, reprojectPipeline)
+	if err != nil {
+		return ReprojectStats{}, err
+	}
+	defer releaseLock()
+
+	source, err := TweedeKamerMotionIngest{Pool: pool}.getSource(ctx)
+	if err != nil {
+		return ReprojectStats{}, err
+	}
+
+	stats := ReprojectStats{}
+	for _, collection := range collections {
+		collectionStats, err := reprojectCollection(ctx, pool, source.JurisdictionKey, collection, reprojectors[collection], batchSize, options)
+		if err != nil {
+			return stats, fmt.Errorf("reproject %s: %w", collection, err)
+		}
+		stats.Collections = append(stats.Collections, collectionStats)
+	}
+	return stats, nil
+}
+
+func reprojectCollection(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	jurisdictionKey string,
+	collection string,
+	projector reprojector,
+	batchSize int,
+	options ReprojectOptions,
+) (CollectionReprojectStats, error) {
+	stats := CollectionReprojectStats{Collection: collection, Fields: map[string]int{}}
+	afterID := ""
+	for {
+		rows, err := loadReprojectRows(ctx, pool, projector.load, collection, afterID, batchSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(rows) == 0 {
+			return stats, nil
+		}
+		afterID = rows[len(rows)-1].SourceID
+
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			return stats, err
+		}
+		changedMotions := []string{}
+		seenMotions := map[string]bool{}
+		for _, row := range rows {
+			stats.Seen++
+			if collection == besluitCollection || collection == stemmingCollection {
+				if row.MotionKey == nil || (collection == stemmingCollection && row.DecisionSourceID == nil) {
+					stats.Skipped++
+					continue
+				}
+			}
+
+			fields, err := reprojectRecord(ctx, tx, jurisdictionKey, projector, row, options.Apply)
+			if err != nil {
+				tx.Rollback(ctx)
+				return stats, fmt.Errorf("%s: %w", row.SourceID, err)
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			stats.Changed++
+			if options.Apply && row.MotionKey != nil && !seenMotions[*row.MotionKey] {
+				seenMotions[*row.MotionKey] = true
+				changedMotions = append(changedMotions, *row.MotionKey)
+			}
+			for _, field := range fields {
+				stats.Fields[field]++
+			}
+			if len(stats.Examples) < options.ExampleLimit {
+				stats.Examples = append(stats.Examples, ReprojectChange{SourceID: row.SourceID, Fields: fields})
+			}
+		}
+		if projector.refresh != nil && len(changedMotions) > 0 {
+			if err := projector.refresh(ctx, tx, changedMotions); err != nil {
+				tx.Rollback(ctx)
+				return stats, err
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return stats, err
+		}
+
+		fmt.Printf("reproject collection=%s seen=%d changed=%d skipped=%d\n", collection, stats.Seen, stats.Changed, stats.Skipped)
+	}
+}
+
+// reprojectRecord returns the columns of the projected row that the payload
+// changes. The savepoint is kept only when apply is set.
+func reprojectRecord(ctx context.Context, tx pgx.Tx, jurisdictionKey string, projector reprojector, row reprojectRow, apply bool) ([]string, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer savepoint.Rollback(ctx)
+
+	key := projector.key(row.SourceID)
+	before, err := reprojectSnapshot(ctx, savepoint, projector.snapshot, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := projector.apply(ctx, savepoint, jurisdictionKey, row); err != nil {
+		return nil, err
+	}
+	after, err := reprojectSnapshot(ctx, savepoint, projector.snapshot, key)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := changedFields(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 && apply {
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func loadReprojectRows(ctx context.Context, pool *pgxpool.Pool, query string, collection string, afterID string, limit int) ([]reprojectRow, error) {
+	rows, err := pool.Query(ctx, query, tweedeKamerSourceKey, collection, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []reprojectRow{}
+	for rows.Next() {
+		var row reprojectRow
+		if err := rows.Scan(&row.SourceID, &row.Payload, &row.MotionKey, &row.DecisionSourceID); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// reprojectSnapshot returns nil when the projected row does not exist.
+func reprojectSnapshot(ctx context.Context, tx pgx.Tx, query string, key string) ([]byte, error) {
+	var snapshot []byte
+	err := tx.QueryRow(ctx, query, key, reprojectIgnoredColumns).Scan(&snapshot)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+// changedFields lists the top-level keys whose values differ between two
+// jsonb snapshots, in key order. A missing before snapshot means the row was
+// created; that is reported as the single field "created".
+func changedFields(before []byte, after []byte) ([]string, error) {
+	if before == nil {
+		if after == nil {
+			return nil, nil
+		}
+		return []string{"created"}, nil
+	}
+
+	var beforeFields, afterFields map[string]json.RawMessage
+	if err := json.Unmarshal(before, &beforeFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &afterFields); err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+	for key, value := range afterFields {
+		if !bytes.Equal(value, beforeFields[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
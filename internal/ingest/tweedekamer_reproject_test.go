@@ -0,0 +1,34 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{name: "unchanged", before: `{"kind": "Motie", "title": "A"}`, after: `{"kind": "Motie", "title": "A"}`, want: []string{}},
+		{name: "changed", before: `{"kind": "Motie", "title": "A", "submitters": []}`, after: `{"kind": "Motie", "title": "B", "submitters": [{"relation": "Indiener"}]}`, want: []string{"submitters", "title"}},
+		{name: "added and removed", before: `{"a": 1}`, after: `{"b": 1}`, want: []string{"a", "b"}},
+		{name: "created", after: `{"a": 1}`, want: []string{"created"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var before []byte
+			if test.before != "" {
+				before = []byte(test.before)
+			}
+			got, err := changedFields(before, []byte(test.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("changedFields = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
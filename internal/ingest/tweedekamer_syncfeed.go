@@ -0,0 +1,607 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/source/tweedekamer"
+	"partijgedrag/internal/source/tweedekamer/syncfeed"
+)
+
+// TweedeKamerSyncFeedIngest follows the SyncFeed instead of polling OData on
+// ApiGewijzigdOp. Each category keeps its own skiptoken cursor:
+//
+//   - Zaak and Fractie entries are refetched from OData and stored like the
+//     polling pipelines do.
+//   - Besluit and Stemming entries only reset votes_synced_at on the motion
+//     they belong to, so the motion-votes pipeline picks it up next. That makes
+//     re-polling unsettled motions within ResyncGrace unnecessary.
+//
+// Deletions mark the projected row source_deleted. Entries older than our own
+// copy are skipped, so replaying the feed from the start is cheap once the
+// database is populated.
+type TweedeKamerSyncFeedIngest struct {
+	Pool   *pgxpool.Pool
+	Client *tweedekamer.Client
+	Feed   *syncfeed.Client
+	// Categories defaults to DefaultSyncFeedCategories.
+	Categories []string
+	// MaxPages bounds each category; zero means until the feed is caught up.
+	MaxPages          int
+	SkipTokenOverride *int64
+	ResetCursor       bool
+}
+
+// DefaultSyncFeedCategories are followed in this order: a Besluit can only be
+// routed to a motion that is already stored.
+var DefaultSyncFeedCategories = []string{
+	syncfeed.CategoryFractie,
+	syncfeed.CategoryZaak,
+	syncfeed.CategoryBesluit,
+	syncfeed.CategoryStemming,
+}
+
+// zaakSoorten are the zaak soorten stored in motions.
+var zaakSoorten = map[string]bool{
+	tweedekamer.ZaakSoortMotie:        true,
+	tweedekamer.ZaakSoortAmendement:   true,
+	tweedekamer.ZaakSoortWetsvoorstel: true,
+}
+
+func SyncFeedPipeline(category string) string {
+	return "syncfeed." + strings.ToLower(category)
+}
+
+func (ingest TweedeKamerSyncFeedIngest) Run(ctx context.Context) error {
+	categories := ingest.Categories
+	if len(categories) == 0 {
+		categories = DefaultSyncFeedCategories
+	}
+	for _, category := range categories {
+		switch category {
+		case syncfeed.CategoryZaak, syncfeed.CategoryFractie, syncfeed.CategoryBesluit, syncfeed.CategoryStemming:
+		default:
+			return fmt.Errorf("unsupported syncfeed category %q", category)
+		}
+	}
+
+	for _, category := range categories {
+		if err := ingest.runCategory(ctx, category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ingest TweedeKamerSyncFeedIngest) runCategory(ctx context.Context, category string) error {
+	pipeline := SyncFeedPipeline(category)
+	releaseLock, err := acquirePipelineLock(ctx, ingest.Pool, pipeline)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	source, err := TweedeKamerMotionIngest{Pool: ingest.Pool}.getSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ingest.ResetCursor {
+		if err := resetPipelineCursor(ctx, ingest.Pool, pipeline); err != nil {
+			return err
+		}
+	}
+
+	cursorBefore, err := loadPipelineCursor(ctx, ingest.Pool, pipeline)
+	if err != nil {
+		return err
+	}
+	if ingest.SkipTokenOverride != nil {
+		cursorBefore = Cursor{SkipToken: ingest.SkipTokenOverride}
+	}
+	var skipToken int64
+	if cursorBefore.SkipToken != nil {
+		skipToken = *cursorBefore.SkipToken
+	}
+
+	runID, err := startPipelineRunWithCursor(ctx, ingest.Pool, pipeline, cursorBefore)
+	if err != nil {
+		return err
+	}
+	ctx, saveRequests := trackRunRequests(ctx, ingest.Pool, runID)
+	defer saveRequests()
+	progress := newRunProgress(ingest.Pool, runID)
+	// A skiptoken says nothing about how much of the feed is left, but a
+	// catch-up from the start replays about one entry per record, so the
+	// size of the collection does for a total.
+	if skipToken == 0 && ingest.MaxPages == 0 {
+		progress.countTotal(ctx, func(ctx context.Context) (int, error) {
+			return ingest.Client.CountEntities(ctx, category, "")
+		}, 0, 0)
+	}
+
+	recordsSeen := 0
+	recordsChanged := 0
+	cursorAfter := cursorBefore
+	cursorSaved := false
+	stopReason := ""
+	pagesProcessed := 0
+	for page := 1; ; page++ {
+		result, err := ingest.Feed.FetchPage(ctx, category, skipToken)
+		if err != nil {
+			_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, pipeline, "failed", cursorAfter, recordsSeen, recordsChanged, cursorSaved, "error", err.Error())
+			return err
+		}
+		pagesProcessed = page
+		recordsSeen += len(result.Entries)
+
+		changed, err := ingest.applyEntries(ctx, source.JurisdictionKey, category, result.Entries)
+		if err != nil {
+			_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, pipeline, "failed", cursorAfter, recordsSeen, recordsChanged, cursorSaved, "error", err.Error())
+			return err
+		}
+		recordsChanged += changed
+
+		progress.addPage(ctx, len(result.Entries))
+		fmt.Printf("category=%s page=%d seen=%d changed=%d next=%t\n", category, page, recordsSeen, recordsChanged, result.NextURL != "")
+
+		// The last page has no next link, so the cursor stays on it and the next
+		// run reads it again. Its entries are then older than our copies and
+		// skipped.
+		if result.NextURL == "" {
+			stopReason = "complete"
+			break
+		}
+
+		// The feed only moves forward, so the cursor is saved per page: an
+		// interrupted catch-up resumes where it stopped.
+		skipToken = result.SkipToken
+		next := skipToken
+		cursorAfter = Cursor{SkipToken: &next}
+		if err := savePipelineCursor(ctx, ingest.Pool, pipeline, cursorAfter); err != nil {
+			_ = finishPipelineRunWithCursor(ctx, ingest.Pool, runID, pipeline, "failed", cursorAfter, recordsSeen, recordsChanged, cursorSaved, "error", err.Error())
+			return err
+		}
+		cursorSaved = true
+
+		if ingest.MaxPages > 0 && page >= ingest.MaxPages {
+			stopReason = "max_pages"
+			break
+		}
+	}
+
+	if err := finishPipelineRunWithCursor(ctx, ingest.Pool, runID, pipeline, "succeeded", cursorAfter, recordsSeen, recordsChanged, cursorSaved, stopReason, ""); err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"syncfeed complete category=%s run_id=%d pages=%d seen=%d changed=%d cursor_before=%s cursor_after=%s stop_reason=%s\n",
+		category,
+		runID,
+		pagesProcessed,
+		recordsSeen,
+		recordsChanged,
+		formatCursor(cursorBefore),
+		formatCursor(cursorAfter),
+		stopReason,
+	)
+	return nil
+}
+
+func (ingest TweedeKamerSyncFeedIngest) applyEntries(ctx context.Context, jurisdictionKey string, category string, entries []syncfeed.Entry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	switch category {
+	case syncfeed.CategoryZaak:
+		return ingest.applyZaakEntries(ctx, jurisdictionKey, entries)
+	case syncfeed.CategoryFractie:
+		return ingest.applyFractieEntries(ctx, jurisdictionKey, entries)
+	case syncfeed.CategoryBesluit:
+		return ingest.applyBesluitEntries(ctx, entries)
+	case syncfeed.CategoryStemming:
+		return ingest.applyStemmingEntries(ctx, entries)
+	default:
+		return 0, fmt.Errorf("unsupported syncfeed category %q", category)
+	}
+}
+
+func (ingest TweedeKamerSyncFeedIngest) applyZaakEntries(ctx context.Context, jurisdictionKey string, entries []syncfeed.Entry) (int, error) {
+	deleted, changed := splitDeletedEntries(entries, func(entry syncfeed.Entry) bool {
+		// Entries without a soort are fetched and filtered on the record.
+		soort := entry.Fields["soort"]
+		return soort == "" || zaakSoorten[soort]
+	})
+
+	total, err := markDeleted(ctx, ingest.Pool, "motions", deleted)
+	if err != nil {
+		return 0, err
+	}
+
+	stale, err := staleEntryIDs(ctx, ingest.Pool, "motions", changed)
+	if err != nil {
+		return 0, err
+	}
+	records, err := ingest.Client.FetchZakenByID(ctx, stale)
+	if err != nil {
+		return 0, err
+	}
+	motions := TweedeKamerMotionIngest{Pool: ingest.Pool}
+	for _, record := range records {
+		if record.Soort == nil || !zaakSoorten[*record.Soort] {
+			continue
+		}
+		stored, err := motions.storeMotionRecord(ctx, jurisdictionKey, record)
+		if err != nil {
+			return 0, err
+		}
+		if stored {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (ingest TweedeKamerSyncFeedIngest) applyFractieEntries(ctx context.Context, jurisdictionKey string, entries []syncfeed.Entry) (int, error) {
+	deleted, changed := splitDeletedEntries(entries, nil)
+
+	total, err := markDeleted(ctx, ingest.Pool, "parties", deleted)
+	if err != nil {
+		return 0, err
+	}
+
+	stale, err := staleEntryIDs(ctx, ingest.Pool, "parties", changed)
+	if err != nil {
+		return 0, err
+	}
+	records, err := ingest.Client.FetchPartiesByID(ctx, stale)
+	if err != nil {
+		return 0, err
+	}
+	parties := TweedeKamerPartyIngest{Pool: ingest.Pool}
+	for _, record := range records {
+		stored, err := parties.storePartyRecord(ctx, jurisdictionKey, record)
+		if err != nil {
+			return 0, err
+		}
+		if stored {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// applyBesluitEntries routes each Besluit to its motion through the zaak it
+// references, or through the stored decision when the feed entry carries no
+// reference, as is the case for deletions.
+func (ingest TweedeKamerSyncFeedIngest) applyBesluitEntries(ctx context.Context, entries []syncfeed.Entry) (int, error) {
+	deleted, _ := splitDeletedEntries(entries, nil)
+
+	tx, err := ingest.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	total, err := markDeleted(ctx, tx, "decisions", deleted)
+	if err != nil {
+		return 0, err
+	}
+	if len(deleted) > 0 {
+		_, err := tx.Exec(ctx, `
+			UPDATE votes v
+			SET source_deleted = true,
+			    updated_at = now()
+			FROM decisions d
+			WHERE d.decision_key = v.decision_key
+			  AND d.source_key = $1
+			  AND d.source_id = ANY($2::text[])
+			  AND v.source_deleted = false
+		`, tweedeKamerSourceKey, entryIDs(deleted))
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := refreshDeletedMotions(ctx, tx, "decisions", deleted); err != nil {
+		return 0, err
+	}
+
+	changes := []motionVoteChange{}
+	for _, entry := range entries {
+		changes = append(changes, motionVoteChange{BesluitID: entry.ID, Updated: entry.Updated})
+		for _, zaakID := range entry.Refs["zaak"] {
+			changes = append(changes, motionVoteChange{ZaakID: zaakID, Updated: entry.Updated})
+		}
+	}
+	flagged, err := flagMotionVotes(ctx, tx, changes)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return total + flagged, nil
+}
+
+// applyStemmingEntries routes each Stemming to its motion through the Besluit
+// it references. A Stemming on a Besluit we have not stored yet is picked up
+// through that Besluit's own feed entry.
+func (ingest TweedeKamerSyncFeedIngest) applyStemmingEntries(ctx context.Context, entries []syncfeed.Entry) (int, error) {
+	deleted, _ := splitDeletedEntries(entries, nil)
+
+	tx, err := ingest.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	total, err := markDeleted(ctx, tx, "votes", deleted)
+	if err != nil {
+		return 0, err
+	}
+	if err := refreshDeletedMotions(ctx, tx, "votes", deleted); err != nil {
+		return 0, err
+	}
+
+	changes := []motionVoteChange{}
+	for _, entry := range entries {
+		changes = append(changes, motionVoteChange{StemmingID: entry.ID, Updated: entry.Updated})
+		for _, besluitID := range entry.Refs["besluit"] {
+			changes = append(changes, motionVoteChange{BesluitID: besluitID, Updated: entry.Updated})
+		}
+	}
+	flagged, err := flagMotionVotes(ctx, tx, changes)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return total + flagged, nil
+}
+
+// motionVoteChange points at a motion through one of its ids. Only one id is
+// set per change.
+type motionVoteChange struct {
+	ZaakID     string
+	BesluitID  string
+	StemmingID string
+	Updated    *time.Time
+}
+
+// flagMotionVotes clears votes_synced_at on every motion a change points at,
+// unless the motion was vote-synced after the change was made.
+func flagMotionVotes(ctx context.Context, tx pgx.Tx, changes []motionVoteChange) (int, error) {
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	zaakIDs := make([]string, len(changes))
+	besluitIDs := make([]string, len(changes))
+	stemmingIDs := make([]string, len(changes))
+	updated := make([]*time.Time, len(changes))
+	for index, change := range changes {
+		zaakIDs[index] = change.ZaakID
+		besluitIDs[index] = change.BesluitID
+		stemmingIDs[index] = change.StemmingID
+		updated[index] = change.Updated
+	}
+
+	tag, err := tx.Exec(ctx, `
+		WITH changes AS (
+			SELECT *
+			FROM unnest($2::text[], $3::text[], $4::text[], $5::timestamptz[])
+			     AS c(zaak_id, besluit_id, stemming_id, updated)
+		),
+		changed AS (
+			SELECT m.motion_key, c.updated
+			FROM changes c
+			JOIN motions m ON m.source_key = $1 AND m.source_id = c.zaak_id
+			UNION ALL
+			SELECT d.motion_key, c.updated
+			FROM changes c
+			JOIN decisions d ON d.source_key = $1 AND d.source_id = c.besluit_id
+			UNION ALL
+			SELECT v.motion_key, c.updated
+			FROM changes c
+			JOIN votes v ON v.source_key = $1 AND v.source_id = c.stemming_id
+		)
+		UPDATE motions m
+		SET votes_synced_at = NULL
+		FROM (
+			SELECT motion_key,
+			       bool_or(updated IS NULL) AS undated,
+			       max(updated) AS updated
+			FROM changed
+			GROUP BY motion_key
+		) c
+		WHERE m.motion_key = c.motion_key
+		  AND m.votes_synced_at IS NOT NULL
+		  AND (c.undated OR m.votes_synced_at < c.updated)
+	`, tweedeKamerSourceKey, zaakIDs, besluitIDs, stemmingIDs, updated)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// splitDeletedEntries separates deletions from changes. keep, when set, drops
+// changed entries that are not ingested; deletions are always kept, since
+// marking a row we do not have is a no-op.
+func splitDeletedEntries(entries []syncfeed.Entry, keep func(syncfeed.Entry) bool) ([]syncfeed.Entry, []syncfeed.Entry) {
+	deleted := []syncfeed.Entry{}
+	changed := []syncfeed.Entry{}
+	seen := map[string]bool{}
+	// A page can list the same entity more than once; the last entry wins.
+	for index := len(entries) - 1; index >= 0; index-- {
+		entry := entries[index]
+		if seen[entry.ID] {
+			continue
+		}
+		seen[entry.ID] = true
+		if entry.Deleted {
+			deleted = append(deleted, entry)
+		} else if keep == nil || keep(entry) {
+			changed = append(changed, entry)
+		}
+	}
+	return deleted, changed
+}
+
+func entryIDs(entries []syncfeed.Entry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+type dbExecutor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, arguments ...any) (pgx.Rows, error)
+}
+
+// markDeleted flags the rows of table whose source id was deleted upstream.
+// table is one of the projection tables keyed by (source_key, source_id).
+func markDeleted(ctx context.Context, db dbExecutor, table string, entries []syncfeed.Entry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	tag, err := db.Exec(ctx, `
+		UPDATE `+table+`
+		SET source_deleted = true,
+		    updated_at = now()
+		WHERE source_key = $1
+		  AND source_id = ANY($2::text[])
+		  AND source_deleted = false
+	`, tweedeKamerSourceKey, entryIDs(entries))
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// refreshDeletedMotions refreshes the tallies and party positions of the
+// motions whose decisions or votes the feed deleted. table is decisions or
+// votes.
+func refreshDeletedMotions(ctx context.Context, db dbExecutor, table string, deleted []syncfeed.Entry) error {
+	if len(deleted) == 0 {
+		return nil
+	}
+	rows, err := db.Query(ctx, `
+		SELECT DISTINCT motion_key
+		FROM `+table+`
+		WHERE source_key = $1
+		  AND source_id = ANY($2::text[])
+	`, tweedeKamerSourceKey, entryIDs(deleted))
+	if err != nil {
+		return err
+	}
+	motionKeys := []string{}
+	for rows.Next() {
+		var motionKey string
+		if err := rows.Scan(&motionKey); err != nil {
+			rows.Close()
+			return err
+		}
+		motionKeys = append(motionKeys, motionKey)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := refreshDecisionTallies(ctx, db, motionKeys); err != nil {
+		return err
+	}
+	return refreshMotionPartyPositions(ctx, db, motionKeys)
+}
+
+// staleEntryIDs returns the ids of entries we have no copy of, or whose copy
+// was written before the entry changed. updated_at is our own write time.
+func staleEntryIDs(ctx context.Context, db dbExecutor, table string, entries []syncfeed.Entry) ([]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	updated := make([]*time.Time, len(entries))
+	for index, entry := range entries {
+		updated[index] = entry.Updated
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT e.id
+		FROM unnest($2::text[], $3::timestamptz[]) AS e(id, updated)
+		LEFT JOIN `+table+` t ON t.source_key = $1
+		                     AND t.source_id = e.id
+		WHERE t.source_id IS NULL
+		   OR e.updated IS NULL
+		   OR t.updated_at < e.updated
+	`, tweedeKamerSourceKey, entryIDs(entries), updated)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func loadPipelineCursor(ctx context.Context, pool *pgxpool.Pool, pipeline string) (Cursor, error) {
+	var raw []byte
+	err := pool.QueryRow(ctx, `
+		SELECT cursor
+		FROM source_cursors
+		WHERE source_key = $1 AND pipeline = $2
+	`, tweedeKamerSourceKey, pipeline).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		return Cursor{}, nil
+	}
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return Cursor{}, err
+	}
+	return cursor, nil
+}
+
+func savePipelineCursor(ctx context.Context, pool *pgxpool.Pool, pipeline string, cursor Cursor) error {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO source_cursors (source_key, pipeline, cursor, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (source_key, pipeline)
+		DO UPDATE SET cursor = EXCLUDED.cursor,
+		              updated_at = now()
+	`, tweedeKamerSourceKey, pipeline, string(raw))
+	return err
+}
+
+func resetPipelineCursor(ctx context.Context, pool *pgxpool.Pool, pipeline string) error {
+	_, err := pool.Exec(ctx, `
+		DELETE FROM source_cursors
+		WHERE source_key = $1 AND pipeline = $2
+	`, tweedeKamerSourceKey, pipeline)
+	return err
+}
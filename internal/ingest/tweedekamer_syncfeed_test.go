@@ -0,0 +1,27 @@
+package ingest
+
+import (
+	"testing"
+
+	"partijgedrag/internal/source/tweedekamer/syncfeed"
+)
+
+func TestSplitDeletedEntriesKeepsLastEntryPerID(t *testing.T) {
+	deleted, changed := splitDeletedEntries([]syncfeed.Entry{
+		{ID: "a", Fields: map[string]string{"soort": "Motie"}},
+		{ID: "b", Fields: map[string]string{"soort": "Brief regering"}},
+		{ID: "a", Deleted: true},
+		{ID: "c", Fields: map[string]string{"soort": "Amendement"}},
+		{ID: "d", Deleted: true},
+		{ID: "d", Fields: map[string]string{"soort": "Motie"}},
+	}, func(entry syncfeed.Entry) bool {
+		return zaakSoorten[entry.Fields["soort"]]
+	})
+
+	if got := entryIDs(deleted); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("deleted = %v, want [a]", got)
+	}
+	if got := entryIDs(changed); len(got) != 2 || got[0] != "d" || got[1] != "c" {
+		t.Fatalf("changed = %v, want [d c]", got)
+	}
+}
@@ -0,0 +1,227 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FieldChange is one payload field that differs between two versions. Before
+// is empty for an added field and After for a removed one.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+type RecordVersion struct {
+	// Number counts the versions of the record from 1.
+	Number          int
+	VersionID       int64
+	SeenAt          time.Time
+	SourceUpdatedAt *time.Time
+	SourceDeleted   bool
+	// Changes are against the previous version and nil for the first.
+	Changes []FieldChange
+}
+
+// RecordHistory lists the payload versions of one raw record, the oldest
+// first.
+type RecordHistory struct {
+	Collection string
+	SourceID   string
+	// Label names the record for a reader, e.g. the party of a Stemming.
+	Label    string
+	Versions []RecordVersion
+}
+
+func (history RecordHistory) Changed() bool {
+	return len(history.Versions) > 1
+}
+
+// LoadMotionHistory returns the payload history of a motion's Zaak and of the
+// Besluiten and Stemmingen stored for it, also deleted ones. It returns
+// pgx.ErrNoRows when the motion does not exist.
+func LoadMotionHistory(ctx context.Context, pool *pgxpool.Pool, motionKey string) ([]RecordHistory, error) {
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM motions WHERE motion_key = $1)`, motionKey).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, pgx.ErrNoRows
+	}
+
+	rows, err := pool.Query(ctx, `
+		WITH records AS (
+			SELECT 0 AS rank, source_key, 'Zaak' AS collection, source_id, COALESCE(number, source_id) AS label
+			FROM motions
+			WHERE motion_key = $1
+			UNION ALL
+			SELECT 1, source_key, 'Besluit', source_id, COALESCE(decision_type, '')
+			FROM decisions
+			WHERE motion_key = $1
+			UNION ALL
+			SELECT 2, source_key, 'Stemming', source_id, COALESCE(party_name, actor_name, '')
+			FROM votes
+			WHERE motion_key = $1
+		)
+		SELECT r.collection,
+		       r.source_id,
+		       r.label,
+		       v.version_id,
+		       v.seen_at,
+		       v.source_updated_at,
+		       v.source_deleted,
+		       v.payload
+		FROM records r
+		JOIN raw_record_versions v ON v.source_key = r.source_key
+		                          AND v.collection = r.collection
+		                          AND v.source_id = r.source_id
+		ORDER BY r.rank, r.label, r.source_id, v.version_id
+	`, motionKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []RecordHistory{}
+	var previous []byte
+	for rows.Next() {
+		var collection, sourceID, label string
+		var version RecordVersion
+		var payload []byte
+		if err := rows.Scan(&collection, &sourceID, &label, &version.VersionID, &version.SeenAt, &version.SourceUpdatedAt, &version.SourceDeleted, &payload); err != nil {
+			return nil, err
+		}
+
+		last := len(histories) - 1
+		if last < 0 || histories[last].Collection != collection || histories[last].SourceID != sourceID {
+			histories = append(histories, RecordHistory{Collection: collection, SourceID: sourceID, Label: label})
+			last++
+		} else {
+			changes, err := DiffPayloads(previous, payload)
+			if err != nil {
+				return nil, fmt.Errorf("diff %s %s: %w", collection, sourceID, err)
+			}
+			version.Changes = changes
+		}
+		version.Number = len(histories[last].Versions) + 1
+		histories[last].Versions = append(histories[last].Versions, version)
+		previous = payload
+	}
+	return histories, rows.Err()
+}
+
+// DiffPayloads compares two JSON payloads field by field. Nested fields are
+// named by path, e.g. Kamerstukdossier[0].Nummer; OData annotations are
+// ignored.
+func DiffPayloads(before []byte, after []byte) ([]FieldChange, error) {
+	beforeFields, err := flattenPayload(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flattenPayload(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	changes := []FieldChange{}
+	for name := range names {
+		if beforeFields[name] != afterFields[name] {
+			changes = append(changes, FieldChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func flattenPayload(payload []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	flattenValue(fields, "", value)
+	return fields, nil
+}
+
+func flattenValue(fields map[string]string, path string, value any) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if strings.HasPrefix(key, "@odata.") {
+				continue
+			}
+			name := key
+			if path != "" {
+				name = path + "." + key
+			}
+			flattenValue(fields, name, child)
+		}
+	case []any:
+		for index, child := range typed {
+			flattenValue(fields, fmt.Sprintf("%s[%d]", path, index), child)
+		}
+	case nil:
+		fields[path] = "null"
+	case string:
+		fields[path] = typed
+	default:
+		fields[path] = fmt.Sprint(typed)
+	}
+}
+
+// PrintHistory writes the payload history of a motion. Records with a single
+// version are only counted unless all is set.
+func PrintHistory(ctx context.Context, pool *pgxpool.Pool, writer io.Writer, motionKey string, all bool) error {
+	histories, err := LoadMotionHistory(ctx, pool, motionKey)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("motion not found: %s", motionKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "motion_key: %s\n", motionKey)
+	unchanged := 0
+	for _, history := range histories {
+		if !history.Changed() && !all {
+			unchanged++
+			continue
+		}
+		fmt.Fprintf(writer, "\n%s %s %s versions=%d\n", history.Collection, history.SourceID, history.Label, len(history.Versions))
+		for _, version := range history.Versions {
+			fmt.Fprintf(writer, "- v%d seen=%s source_updated=%s deleted=%t\n",
+				version.Number,
+				version.SeenAt.Format(time.RFC3339),
+				timeValue(version.SourceUpdatedAt),
+				version.SourceDeleted,
+			)
+			for _, change := range version.Changes {
+				fmt.Fprintf(writer, "    %s: %q -> %q\n", change.Field, change.Before, change.After)
+			}
+		}
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(writer, "\nunchanged_records: %d\n", unchanged)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package inspect
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffPayloadsListsChangedFieldsByPath(t *testing.T) {
+	before := []byte(`{"@odata.etag":"1","Id":"s1","Soort":"Voor","Vergissing":false,"FractieGrootte":15,"Zaak":[{"Id":"z1"}]}`)
+	after := []byte(`{"@odata.etag":"2","Id":"s1","Soort":"Tegen","Vergissing":true,"FractieGrootte":15,"Zaak":[{"Id":"z2"}],"Opmerking":null}`)
+
+	changes, err := DiffPayloads(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldChange{
+		{Field: "Opmerking", Before: "", After: "null"},
+		{Field: "Soort", Before: "Voor", After: "Tegen"},
+		{Field: "Vergissing", Before: "false", After: "true"},
+		{Field: "Zaak[0].Id", Before: "z1", After: "z2"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+
+	if changes, err := DiffPayloads(before, before); err != nil || len(changes) != 0 {
+		t.Fatalf("identical payloads gave %+v, %v", changes, err)
+	}
+	if _, err := DiffPayloads(before, []byte(`{`)); err == nil {
+		t.Fatal("accepted an invalid payload")
+	}
+}
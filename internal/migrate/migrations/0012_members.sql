@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS members (
+  member_key text PRIMARY KEY,
+  source_key text NOT NULL REFERENCES data_sources(source_key),
+  jurisdiction_key text NOT NULL REFERENCES jurisdictions(jurisdiction_key),
+  source_id text NOT NULL,
+  number integer,
+  initials text,
+  first_name text,
+  name_prefix text,
+  last_name text,
+  full_name text,
+  gender text,
+  function text,
+  party_label text,
+  residence text,
+  source_updated_at timestamptz,
+  source_deleted boolean NOT NULL DEFAULT false,
+  raw_collection text NOT NULL DEFAULT 'Persoon',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (source_key, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS members_source_updated_idx
+  ON members (source_key, source_updated_at);
+
+-- One row per FractieZetelPersoon: a stretch of a member holding a seat for a
+-- fractie. party_source_id is the Fractie id, the same value votes carry, so
+-- this joins to parties without going through names.
+CREATE TABLE IF NOT EXISTS member_party_seats (
+  seat_key text PRIMARY KEY,
+  member_key text NOT NULL REFERENCES members(member_key) ON DELETE CASCADE,
+  party_source_id text,
+  function text,
+  started_on date,
+  ended_on date,
+  source_deleted boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS member_party_seats_member_idx
+  ON member_party_seats (member_key, started_on DESC);
+
+CREATE INDEX IF NOT EXISTS member_party_seats_party_idx
+  ON member_party_seats (party_source_id, started_on DESC);
+
+-- Hoofdelijke stemmingen carry one vote per member; the motion page looks them
+-- up by decision.
+CREATE INDEX IF NOT EXISTS votes_person_idx
+  ON votes (decision_key, person_source_id)
+  WHERE person_source_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Amendementen are ingested into motions next to moties; kind holds the Zaak
+-- Soort. The dossier is the wetsvoorstel an amendement amends.
+ALTER TABLE motions ADD COLUMN IF NOT EXISTS dossier_number text;
+ALTER TABLE motions ADD COLUMN IF NOT EXISTS dossier_addition text;
+
+CREATE INDEX IF NOT EXISTS motions_kind_proposed_idx
+  ON motions (jurisdiction_key, kind, proposed_at DESC);
+
+CREATE INDEX IF NOT EXISTS motions_dossier_idx
+  ON motions (dossier_number, dossier_addition)
+  WHERE dossier_number IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Wetsvoorstellen are ingested into motions with kind 'Wetsvoorstel', so their
+-- Besluiten and Stemmingen go through the motion vote pipeline. bills records
+-- which of those decisions settled the bill; the amendementen and moties of the
+-- same Kamerstukdossier are found through motions.dossier_number.
+CREATE TABLE IF NOT EXISTS bills (
+  bill_key text PRIMARY KEY REFERENCES motions(motion_key) ON DELETE CASCADE,
+  final_decision_key text REFERENCES decisions(decision_key) ON DELETE SET NULL,
+  final_decision_type text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
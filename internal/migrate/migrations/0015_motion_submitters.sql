@@ -0,0 +1,32 @@
+-- One row per ZaakActor with relatie Indiener or Medeindiener. person_source_id
+-- and party_source_id are the Persoon and Fractie ids votes carry, so this
+-- joins to members and parties the same way votes do. Motions ingested before
+-- this table existed get their submitters once they are re-ingested, e.g. with
+-- `ingest tweedekamer motions --reset-cursor`.
+CREATE TABLE IF NOT EXISTS motion_submitters (
+  submitter_key text PRIMARY KEY,
+  source_key text NOT NULL REFERENCES data_sources(source_key),
+  motion_key text NOT NULL REFERENCES motions(motion_key) ON DELETE CASCADE,
+  source_id text NOT NULL,
+  relation text NOT NULL,
+  actor_name text,
+  party_name text,
+  function text,
+  person_source_id text,
+  party_source_id text,
+  source_deleted boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (source_key, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS motion_submitters_motion_idx
+  ON motion_submitters (motion_key);
+
+CREATE INDEX IF NOT EXISTS motion_submitters_person_idx
+  ON motion_submitters (person_source_id)
+  WHERE person_source_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS motion_submitters_party_idx
+  ON motion_submitters (party_source_id, relation)
+  WHERE source_deleted = false;
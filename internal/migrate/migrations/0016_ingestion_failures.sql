@@ -0,0 +1,18 @@
+-- Dead letters of the per-motion pipelines (motion_votes.raw,
+-- motion_documents.raw). A motion that fails is recorded here and skipped
+-- until next_retry_at, so one broken zaak no longer fails every batch. The row
+-- is deleted once the motion syncs.
+CREATE TABLE IF NOT EXISTS ingestion_failures (
+  source_key text NOT NULL REFERENCES data_sources(source_key),
+  pipeline text NOT NULL,
+  item_key text NOT NULL,
+  attempts integer NOT NULL DEFAULT 1,
+  last_error text NOT NULL,
+  first_failed_at timestamptz NOT NULL DEFAULT now(),
+  last_failed_at timestamptz NOT NULL DEFAULT now(),
+  next_retry_at timestamptz NOT NULL,
+  PRIMARY KEY (source_key, pipeline, item_key)
+);
+
+CREATE INDEX IF NOT EXISTS ingestion_failures_retry_idx
+  ON ingestion_failures (source_key, pipeline, next_retry_at);
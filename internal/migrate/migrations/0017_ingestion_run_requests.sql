@@ -0,0 +1,3 @@
+ALTER TABLE ingestion_runs
+ADD COLUMN IF NOT EXISTS retries integer NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS throttled integer NOT NULL DEFAULT 0;
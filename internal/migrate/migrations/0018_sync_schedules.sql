@@ -0,0 +1,10 @@
+-- The built-in scheduler stores the next planned run per sync pipeline, so a
+-- restart of serve picks the schedule up where it left off.
+CREATE TABLE IF NOT EXISTS sync_schedules (
+  pipeline text PRIMARY KEY,
+  next_run_at timestamptz NOT NULL,
+  last_started_at timestamptz,
+  last_finished_at timestamptz,
+  last_error text,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
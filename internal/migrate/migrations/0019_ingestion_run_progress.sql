@@ -0,0 +1,8 @@
+-- Progress of a running pipeline, written every few seconds while it runs.
+-- progress_total is only known for batch pipelines that count their pending
+-- items up front; paged pipelines leave it NULL.
+ALTER TABLE ingestion_runs
+ADD COLUMN IF NOT EXISTS progress_pages integer NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS progress_items integer NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS progress_total integer,
+ADD COLUMN IF NOT EXISTS progress_updated_at timestamptz;
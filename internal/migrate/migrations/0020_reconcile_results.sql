@@ -0,0 +1,21 @@
+-- Latest outcome of maintenance reconcile per collection and GestartOp month,
+-- shown on /data-quality. Fractie is compared as a whole under window 'all'.
+-- The samples hold the first ids of each difference.
+CREATE TABLE IF NOT EXISTS reconcile_results (
+  source_key text NOT NULL REFERENCES data_sources(source_key),
+  collection text NOT NULL,
+  window_label text NOT NULL,
+  window_start timestamptz,
+  window_end timestamptz,
+  remote_count integer NOT NULL,
+  local_count integer NOT NULL,
+  compared boolean NOT NULL DEFAULT false,
+  missing_local integer NOT NULL DEFAULT 0,
+  missing_remote integer NOT NULL DEFAULT 0,
+  missing_local_sample text[] NOT NULL DEFAULT '{}',
+  missing_remote_sample text[] NOT NULL DEFAULT '{}',
+  refetched integer NOT NULL DEFAULT 0,
+  tombstoned integer NOT NULL DEFAULT 0,
+  checked_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (source_key, collection, window_label)
+);
@@ -0,0 +1,46 @@
+-- Append-only history of raw_records: a row per distinct payload a record
+-- had, in the order they were seen. raw_records keeps only the latest, which
+-- loses what a vote looked like before a vergissing was corrected.
+CREATE TABLE IF NOT EXISTS raw_record_versions (
+  version_id bigserial PRIMARY KEY,
+  source_key text NOT NULL REFERENCES data_sources(source_key),
+  collection text NOT NULL,
+  source_id text NOT NULL,
+  source_updated_at timestamptz,
+  source_deleted boolean NOT NULL DEFAULT false,
+  payload jsonb NOT NULL,
+  payload_hash text NOT NULL,
+  seen_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS raw_record_versions_record_idx
+  ON raw_record_versions (source_key, collection, source_id, version_id);
+
+-- Records stored before the history existed start with their current payload,
+-- as of the last time it changed.
+INSERT INTO raw_record_versions (
+  source_key,
+  collection,
+  source_id,
+  source_updated_at,
+  source_deleted,
+  payload,
+  payload_hash,
+  seen_at
+)
+SELECT r.source_key,
+       r.collection,
+       r.source_id,
+       r.source_updated_at,
+       r.source_deleted,
+       r.payload,
+       r.payload_hash,
+       r.last_seen_at
+FROM raw_records r
+WHERE NOT EXISTS (
+  SELECT 1
+  FROM raw_record_versions v
+  WHERE v.source_key = r.source_key
+    AND v.collection = r.collection
+    AND v.source_id = r.source_id
+);
@@ -0,0 +1,19 @@
+-- Violations of the internal/quality rules. A finding is keyed by its rule and
+-- subject (a decision, or a decision or motion with a party) and stays open
+-- while the rule keeps matching; a run that no longer finds it sets
+-- resolved_at, and a recurrence reopens it.
+CREATE TABLE IF NOT EXISTS data_quality_findings (
+  rule_key text NOT NULL,
+  subject_key text NOT NULL,
+  motion_key text NOT NULL,
+  severity text NOT NULL CHECK (severity IN ('error', 'warning', 'info')),
+  detail text NOT NULL,
+  first_seen_at timestamptz NOT NULL DEFAULT now(),
+  last_seen_at timestamptz NOT NULL DEFAULT now(),
+  resolved_at timestamptz,
+  PRIMARY KEY (rule_key, subject_key)
+);
+
+CREATE INDEX IF NOT EXISTS data_quality_findings_open_motion_idx
+  ON data_quality_findings (motion_key)
+  WHERE resolved_at IS NULL;
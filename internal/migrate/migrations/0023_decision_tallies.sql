@@ -0,0 +1,44 @@
+-- Seat-weighted tally and normalized outcome per Besluit, kept fresh by the
+-- vote ingest. A faction vote counts for its seats and a member vote for one;
+-- margin is seats_for minus seats_against. outcome follows
+-- politics.DecisionOutcome, which the backfill below mirrors.
+CREATE TABLE IF NOT EXISTS decision_tallies (
+  decision_key text PRIMARY KEY REFERENCES decisions(decision_key) ON DELETE CASCADE,
+  motion_key text NOT NULL,
+  seats_for integer NOT NULL,
+  seats_against integer NOT NULL,
+  seats_not_voting integer NOT NULL,
+  margin integer NOT NULL,
+  outcome text NOT NULL CHECK (outcome IN ('adopted', 'rejected', 'withdrawn', 'held', 'unknown')),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS decision_tallies_motion_idx
+  ON decision_tallies (motion_key);
+
+INSERT INTO decision_tallies (decision_key, motion_key, seats_for, seats_against, seats_not_voting, margin, outcome)
+SELECT d.decision_key,
+       d.motion_key,
+       t.seats_for,
+       t.seats_against,
+       CASE WHEN t.vote_count > 0 THEN GREATEST(150 - t.seats_for - t.seats_against, 0) ELSE 0 END,
+       t.seats_for - t.seats_against,
+       CASE
+         WHEN lower(d.decision_type) LIKE '%aangenomen%' OR lower(d.decision_type) = 'overgenomen' THEN 'adopted'
+         WHEN lower(d.decision_type) LIKE '%verworpen%' THEN 'rejected'
+         WHEN lower(d.decision_type) LIKE '%ingetrokken%' THEN 'withdrawn'
+         WHEN lower(d.decision_type) LIKE '%aangehouden%' THEN 'held'
+         ELSE 'unknown'
+       END
+FROM decisions d
+CROSS JOIN LATERAL (
+  SELECT count(v.vote_key)::int AS vote_count,
+         COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Voor'), 0)::int AS seats_for,
+         COALESCE(sum(CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int AS seats_against
+  FROM votes v
+  WHERE v.decision_key = d.decision_key
+    AND v.source_deleted = false
+    AND v.mistake = false
+) t
+WHERE d.source_deleted = false
+ON CONFLICT (decision_key) DO NOTHING;
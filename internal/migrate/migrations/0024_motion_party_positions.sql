@@ -0,0 +1,39 @@
+-- Each party's position per motion, kept fresh by the vote ingest so the
+-- analyses no longer classify raw votes on every cache miss. votes_for and
+-- votes_against count the party's Voor and Tegen rows over all decisions, one
+-- per faction vote and one per member in a hoofdelijke stemming; a tie is
+-- NEUTRAL. Votes without a fractie are left out. party_name is the name on the
+-- votes, a fallback for parties.short_name.
+CREATE TABLE IF NOT EXISTS motion_party_positions (
+  motion_key text NOT NULL REFERENCES motions(motion_key) ON DELETE CASCADE,
+  party_source_id text NOT NULL,
+  party_name text,
+  votes_for integer NOT NULL,
+  votes_against integer NOT NULL,
+  position text NOT NULL CHECK (position IN ('FOR', 'AGAINST', 'NEUTRAL')),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (motion_key, party_source_id)
+);
+
+CREATE INDEX IF NOT EXISTS motion_party_positions_party_idx
+  ON motion_party_positions (party_source_id, motion_key);
+
+INSERT INTO motion_party_positions (motion_key, party_source_id, party_name, votes_for, votes_against, position)
+SELECT v.motion_key,
+       v.party_source_id,
+       COALESCE(max(v.party_name), max(v.actor_name)),
+       count(*) FILTER (WHERE v.vote_type = 'Voor')::int,
+       count(*) FILTER (WHERE v.vote_type = 'Tegen')::int,
+       CASE
+         WHEN count(*) FILTER (WHERE v.vote_type = 'Voor') > count(*) FILTER (WHERE v.vote_type = 'Tegen') THEN 'FOR'
+         WHEN count(*) FILTER (WHERE v.vote_type = 'Tegen') > count(*) FILTER (WHERE v.vote_type = 'Voor') THEN 'AGAINST'
+         ELSE 'NEUTRAL'
+       END
+FROM votes v
+JOIN motions m ON m.motion_key = v.motion_key
+WHERE v.source_deleted = false
+  AND v.mistake = false
+  AND v.party_source_id IS NOT NULL
+  AND v.vote_type IN ('Voor', 'Tegen')
+GROUP BY v.motion_key, v.party_source_id
+ON CONFLICT (motion_key, party_source_id) DO NOTHING;
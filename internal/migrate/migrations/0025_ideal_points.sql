@@ -0,0 +1,56 @@
+-- One-dimensional ideal points per cabinet period, estimated offline by
+-- `partijgedrag maintenance ideal-points`. scope is 'party' for fracties,
+-- from motion_party_positions, or 'member' for Kamerleden, from hoofdelijke
+-- stemmingen. kind is the motion kind scaled, as NormalizeMotionKind gives it,
+-- or 'all'. Each run replaces the rows of its period, scope and kind.
+CREATE TABLE IF NOT EXISTS ideal_point_runs (
+  period_key text NOT NULL REFERENCES cabinet_periods(period_key) ON DELETE CASCADE,
+  scope text NOT NULL CHECK (scope IN ('party', 'member')),
+  kind text NOT NULL,
+  subjects integer NOT NULL,
+  motions integer NOT NULL,
+  votes integer NOT NULL,
+  errors integer NOT NULL,
+  classified double precision NOT NULL,
+  apre double precision NOT NULL,
+  computed_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (period_key, scope, kind)
+);
+
+-- subject_id is the fractie or person source id; coordinate runs from -1 to 1.
+CREATE TABLE IF NOT EXISTS ideal_points (
+  period_key text NOT NULL,
+  scope text NOT NULL,
+  kind text NOT NULL,
+  subject_id text NOT NULL,
+  subject_name text,
+  party_source_id text,
+  coordinate double precision NOT NULL,
+  votes integer NOT NULL,
+  errors integer NOT NULL,
+  PRIMARY KEY (period_key, scope, kind, subject_id),
+  FOREIGN KEY (period_key, scope, kind) REFERENCES ideal_point_runs(period_key, scope, kind) ON DELETE CASCADE
+);
+
+-- The cutting point of each scaled motion. voor_above says whether the Voor
+-- side lies above the cutting point. A motion is cross_cutting when the
+-- model explains less than half of its minority votes.
+CREATE TABLE IF NOT EXISTS ideal_point_motions (
+  period_key text NOT NULL,
+  scope text NOT NULL,
+  kind text NOT NULL,
+  motion_key text NOT NULL REFERENCES motions(motion_key) ON DELETE CASCADE,
+  cutting_point double precision NOT NULL,
+  voor_above boolean NOT NULL,
+  voters integer NOT NULL,
+  minority integer NOT NULL,
+  errors integer NOT NULL,
+  pre double precision NOT NULL,
+  cross_cutting boolean NOT NULL,
+  PRIMARY KEY (period_key, scope, kind, motion_key),
+  FOREIGN KEY (period_key, scope, kind) REFERENCES ideal_point_runs(period_key, scope, kind) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ideal_point_motions_cross_cutting_idx
+  ON ideal_point_motions (period_key, scope, kind, pre)
+  WHERE cross_cutting;
@@ -0,0 +1,56 @@
+package politics
+
+import "strings"
+
+type Outcome string
+
+const (
+	OutcomeAdopted   Outcome = "adopted"
+	OutcomeRejected  Outcome = "rejected"
+	OutcomeWithdrawn Outcome = "withdrawn"
+	OutcomeHeld      Outcome = "held"
+	OutcomeUnknown   Outcome = "unknown"
+)
+
+var Outcomes = []Outcome{OutcomeAdopted, OutcomeRejected, OutcomeWithdrawn, OutcomeHeld, OutcomeUnknown}
+
+// HouseSeats is the size of the Tweede Kamer.
+const HouseSeats = 150
+
+// DecisionOutcome normalizes a BesluitSoort. Overgenomen means the cabinet
+// adopted the motion without a vote, so it counts as adopted; procedural
+// Besluiten are unknown.
+func DecisionOutcome(decisionType *string) Outcome {
+	if decisionType == nil {
+		return OutcomeUnknown
+	}
+	value := strings.ToLower(*decisionType)
+	switch {
+	case strings.Contains(value, "aangenomen"), value == "overgenomen":
+		return OutcomeAdopted
+	case strings.Contains(value, "verworpen"):
+		return OutcomeRejected
+	case strings.Contains(value, "ingetrokken"):
+		return OutcomeWithdrawn
+	case strings.Contains(value, "aangehouden"):
+		return OutcomeHeld
+	default:
+		return OutcomeUnknown
+	}
+}
+
+// Carried reports whether a tally adopts the motion; a tie (staking van
+// stemmen) does not.
+func Carried(seatsFor int, seatsAgainst int) bool {
+	return seatsFor > seatsAgainst
+}
+
+// Pivotal reports whether moving a party's seats on the winning side over to
+// the other side would have flipped the result of the tally. Seats the party
+// cast on the losing side do not count.
+func Pivotal(seatsFor int, seatsAgainst int, partyFor int, partyAgainst int) bool {
+	if Carried(seatsFor, seatsAgainst) {
+		return partyFor > 0 && !Carried(seatsFor-partyFor, seatsAgainst+partyFor)
+	}
+	return partyAgainst > 0 && Carried(seatsFor+partyAgainst, seatsAgainst-partyAgainst)
+}
@@ -0,0 +1,49 @@
+package politics
+
+import "testing"
+
+func TestDecisionOutcome(t *testing.T) {
+	tests := []struct {
+		decisionType *string
+		want         Outcome
+	}{
+		{decisionType: stringPointer("Stemmen - aangenomen"), want: OutcomeAdopted},
+		{decisionType: stringPointer("Overgenomen"), want: OutcomeAdopted},
+		{decisionType: stringPointer("Stemmen - verworpen"), want: OutcomeRejected},
+		{decisionType: stringPointer("Ingetrokken"), want: OutcomeWithdrawn},
+		{decisionType: stringPointer("Aangehouden (tijdens vergadering)"), want: OutcomeHeld},
+		{decisionType: stringPointer("Stemmingen - uitgesteld"), want: OutcomeUnknown},
+		{decisionType: nil, want: OutcomeUnknown},
+	}
+
+	for _, test := range tests {
+		if got := DecisionOutcome(test.decisionType); got != test.want {
+			t.Fatalf("DecisionOutcome(%v) = %q, want %q", test.decisionType, got, test.want)
+		}
+	}
+}
+
+func TestPivotal(t *testing.T) {
+	tests := []struct {
+		name                                           string
+		seatsFor, seatsAgainst, partyFor, partyAgainst int
+		want                                           bool
+	}{
+		{"winning side switch flips", 80, 70, 10, 0, true},
+		{"winning side switch ties and so rejects", 80, 70, 5, 0, true},
+		{"too small to flip", 80, 70, 4, 0, false},
+		{"losing side seats do not count", 80, 70, 0, 30, false},
+		{"rejected motion needs a strict majority after the switch", 70, 80, 0, 5, false},
+		{"rejected motion flips", 70, 80, 0, 6, true},
+		{"a tie is flipped by any tegen seat", 75, 75, 0, 1, true},
+	}
+	for _, test := range tests {
+		if got := Pivotal(test.seatsFor, test.seatsAgainst, test.partyFor, test.partyAgainst); got != test.want {
+			t.Fatalf("%s: Pivotal(%d, %d, %d, %d) = %v, want %v", test.name, test.seatsFor, test.seatsAgainst, test.partyFor, test.partyAgainst, got, test.want)
+		}
+	}
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
@@ -0,0 +1,78 @@
+package politics
+
+// MajorityQuota is the seats a simple majority of a house of totalSeats
+// needs: 76 of 150.
+func MajorityQuota(totalSeats int) int {
+	return totalSeats/2 + 1
+}
+
+// PowerIndices computes the Shapley–Shubik and normalized Banzhaf index of
+// every party in a weighted majority game. Both count the coalitions of the
+// other parties that a party turns from losing into winning, found by
+// dynamic programming over coalition size and seats instead of enumerating
+// all orderings. Shapley–Shubik weighs a coalition of k others by the share
+// of orderings in which exactly those come first, k!(n-k-1)!/n!; Banzhaf
+// counts every coalition once and normalizes the counts to sum to 1.
+func PowerIndices(seats []int, quota int) (shapleyShubik []float64, banzhaf []float64) {
+	parties := len(seats)
+	shapleyShubik = make([]float64, parties)
+	banzhaf = make([]float64, parties)
+	total := 0
+	for _, partySeats := range seats {
+		total += max(partySeats, 0)
+	}
+	if parties == 0 {
+		return shapleyShubik, banzhaf
+	}
+
+	// orderWeight[k] = k!(n-k-1)!/n! = 1 / (n * C(n-1, k)).
+	orderWeight := make([]float64, parties)
+	binomial := 1.0
+	for size := 0; size < parties; size++ {
+		orderWeight[size] = 1 / (float64(parties) * binomial)
+		binomial = binomial * float64(parties-1-size) / float64(size+1)
+	}
+
+	swingTotal := 0.0
+	for party, partySeats := range seats {
+		// counts[k][w] is the number of coalitions of k other parties with w
+		// seats together.
+		counts := make([][]float64, parties)
+		for size := range counts {
+			counts[size] = make([]float64, total+1)
+		}
+		counts[0][0] = 1
+		members := 0
+		for other, otherSeats := range seats {
+			if other == party {
+				continue
+			}
+			otherSeats = max(otherSeats, 0)
+			for size := members; size >= 0; size-- {
+				for weight := total - otherSeats; weight >= 0; weight-- {
+					if counts[size][weight] != 0 {
+						counts[size+1][weight+otherSeats] += counts[size][weight]
+					}
+				}
+			}
+			members++
+		}
+
+		swings := 0.0
+		for size := range counts {
+			for weight := max(quota-partySeats, 0); weight < quota && weight <= total; weight++ {
+				swings += counts[size][weight]
+				shapleyShubik[party] += counts[size][weight] * orderWeight[size]
+			}
+		}
+		banzhaf[party] = swings
+		swingTotal += swings
+	}
+
+	if swingTotal > 0 {
+		for party := range banzhaf {
+			banzhaf[party] /= swingTotal
+		}
+	}
+	return shapleyShubik, banzhaf
+}
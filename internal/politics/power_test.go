@@ -0,0 +1,59 @@
+package politics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMajorityQuota(t *testing.T) {
+	if got := MajorityQuota(HouseSeats); got != 76 {
+		t.Fatalf("MajorityQuota(150) = %d, want 76", got)
+	}
+	if got := MajorityQuota(149); got != 75 {
+		t.Fatalf("MajorityQuota(149) = %d, want 75", got)
+	}
+}
+
+func TestPowerIndices(t *testing.T) {
+	tests := []struct {
+		name          string
+		seats         []int
+		quota         int
+		shapleyShubik []float64
+		banzhaf       []float64
+	}{
+		// The large party wins with either partner; the other two only with
+		// the large one.
+		{"dominant party", []int{50, 49, 1}, 51, []float64{2.0 / 3, 1.0 / 6, 1.0 / 6}, []float64{3.0 / 5, 1.0 / 5, 1.0 / 5}},
+		{"majority on its own", []int{76, 74}, 76, []float64{1, 0}, []float64{1, 0}},
+		{"any two of three", []int{50, 50, 50}, 76, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}},
+		{"dummy party", []int{40, 40, 40, 30}, 76, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3, 0}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3, 0}},
+	}
+	for _, test := range tests {
+		shapleyShubik, banzhaf := PowerIndices(test.seats, test.quota)
+		for party := range test.seats {
+			if math.Abs(shapleyShubik[party]-test.shapleyShubik[party]) > 1e-12 {
+				t.Fatalf("%s: Shapley–Shubik = %v, want %v", test.name, shapleyShubik, test.shapleyShubik)
+			}
+			if math.Abs(banzhaf[party]-test.banzhaf[party]) > 1e-12 {
+				t.Fatalf("%s: Banzhaf = %v, want %v", test.name, banzhaf, test.banzhaf)
+			}
+		}
+	}
+}
+
+func TestPowerIndicesSumToOne(t *testing.T) {
+	// Tweede Kamer 2023.
+	shapleyShubik, banzhaf := PowerIndices([]int{37, 25, 24, 20, 9, 7, 5, 5, 5, 3, 3, 3, 2, 1, 1}, 76)
+	sumShapley, sumBanzhaf := 0.0, 0.0
+	for party := range shapleyShubik {
+		sumShapley += shapleyShubik[party]
+		sumBanzhaf += banzhaf[party]
+	}
+	if math.Abs(sumShapley-1) > 1e-9 || math.Abs(sumBanzhaf-1) > 1e-9 {
+		t.Fatalf("sums = %v, %v, want 1", sumShapley, sumBanzhaf)
+	}
+	if shapleyShubik[0] <= shapleyShubik[1] || shapleyShubik[13] != shapleyShubik[14] {
+		t.Fatalf("Shapley–Shubik = %v, want the largest party strongest and equal parties equal", shapleyShubik)
+	}
+}
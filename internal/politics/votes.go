@@ -1,5 +1,7 @@
 package politics
 
+import "math"
+
 type Position string
 
 const (
@@ -24,3 +26,13 @@ func SimilarityPercentage(sameVotes int, totalVotes int) float64 {
 	}
 	return (float64(sameVotes) / float64(totalVotes)) * 100
 }
+
+// RiceIndex measures how united a group voted: 1 when every vote went the same
+// way, 0 for an even split. A group without votes counts as united.
+func RiceIndex(votesFor int, votesAgainst int) float64 {
+	total := votesFor + votesAgainst
+	if total <= 0 {
+		return 1
+	}
+	return math.Abs(float64(votesFor-votesAgainst)) / float64(total)
+}
@@ -56,3 +56,23 @@ func TestSimilarityPercentage(t *testing.T) {
 		t.Fatalf("SimilarityPercentage(3, 0) = %f, want 0", got)
 	}
 }
+
+func TestRiceIndex(t *testing.T) {
+	tests := []struct {
+		votesFor     int
+		votesAgainst int
+		want         float64
+	}{
+		{votesFor: 24, votesAgainst: 0, want: 1},
+		{votesFor: 0, votesAgainst: 9, want: 1},
+		{votesFor: 6, votesAgainst: 6, want: 0},
+		{votesFor: 15, votesAgainst: 5, want: 0.5},
+		{votesFor: 0, votesAgainst: 0, want: 1},
+	}
+
+	for _, test := range tests {
+		if got := RiceIndex(test.votesFor, test.votesAgainst); got != test.want {
+			t.Fatalf("RiceIndex(%d, %d) = %v, want %v", test.votesFor, test.votesAgainst, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package quality
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Finding struct {
+	Rule        string     `json:"rule"`
+	SubjectKey  string     `json:"subjectKey"`
+	MotionKey   string     `json:"motionKey"`
+	Severity    Severity   `json:"severity"`
+	Detail      string     `json:"detail"`
+	FirstSeenAt time.Time  `json:"firstSeenAt"`
+	LastSeenAt  time.Time  `json:"lastSeenAt"`
+	ResolvedAt  *time.Time `json:"resolvedAt"`
+}
+
+// FindingFilter narrows LoadFindings; zero fields match everything. Resolved
+// lists resolved findings instead of open ones.
+type FindingFilter struct {
+	Rule      string
+	Severity  Severity
+	MotionKey string
+	Resolved  bool
+	Limit     int
+}
+
+// LoadFindings lists findings, the most severe and most recently seen first.
+func LoadFindings(ctx context.Context, pool *pgxpool.Pool, filter FindingFilter) ([]Finding, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT rule_key,
+		       subject_key,
+		       motion_key,
+		       severity,
+		       detail,
+		       first_seen_at,
+		       last_seen_at,
+		       resolved_at
+		FROM data_quality_findings
+		WHERE ($1::text = '' OR rule_key = $1)
+		  AND ($2::text = '' OR severity = $2)
+		  AND ($3::text = '' OR motion_key = $3)
+		  AND (resolved_at IS NOT NULL) = $4
+		ORDER BY array_position(ARRAY['error', 'warning', 'info'], severity),
+		         last_seen_at DESC,
+		         rule_key,
+		         subject_key
+		LIMIT $5
+	`, filter.Rule, string(filter.Severity), filter.MotionKey, filter.Resolved, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := []Finding{}
+	for rows.Next() {
+		var finding Finding
+		if err := rows.Scan(
+			&finding.Rule,
+			&finding.SubjectKey,
+			&finding.MotionKey,
+			&finding.Severity,
+			&finding.Detail,
+			&finding.FirstSeenAt,
+			&finding.LastSeenAt,
+			&finding.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		findings = append(findings, finding)
+	}
+	return findings, rows.Err()
+}
+
+// RuleSummary counts the open findings of a rule per severity.
+type RuleSummary struct {
+	Rule        string         `json:"rule"`
+	Description string         `json:"description"`
+	Open        map[string]int `json:"open"`
+	// LastFoundAt is when the rule last matched, nil when it never did.
+	LastFoundAt *time.Time `json:"lastFoundAt"`
+}
+
+func (summary RuleSummary) Total() int {
+	total := 0
+	for _, count := range summary.Open {
+		total += count
+	}
+	return total
+}
+
+// LoadRuleSummaries lists every rule, in rule order, with its open findings.
+func LoadRuleSummaries(ctx context.Context, pool *pgxpool.Pool) ([]RuleSummary, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT rule_key,
+		       severity,
+		       count(*) FILTER (WHERE resolved_at IS NULL)::int,
+		       max(last_seen_at)
+		FROM data_quality_findings
+		GROUP BY rule_key, severity
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byRule := map[string]*RuleSummary{}
+	summaries := make([]RuleSummary, len(Rules))
+	for index, rule := range Rules {
+		summaries[index] = RuleSummary{Rule: rule.Key, Description: rule.Description, Open: map[string]int{}}
+		byRule[rule.Key] = &summaries[index]
+	}
+	for rows.Next() {
+		var rule, severity string
+		var open int
+		var lastSeenAt time.Time
+		if err := rows.Scan(&rule, &severity, &open, &lastSeenAt); err != nil {
+			return nil, err
+		}
+		summary, ok := byRule[rule]
+		if !ok {
+			// Findings of a rule that was since removed.
+			continue
+		}
+		if open > 0 {
+			summary.Open[severity] = open
+		}
+		if summary.LastFoundAt == nil || lastSeenAt.After(*summary.LastFoundAt) {
+			summary.LastFoundAt = &lastSeenAt
+		}
+	}
+	return summaries, rows.Err()
+}
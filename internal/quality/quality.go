@@ -0,0 +1,427 @@
+// Package quality checks the projected data against rules that should hold
+// for every motion, decision and vote, and keeps the violations as findings.
+// A finding stays open while its rule keeps matching and is resolved on the
+// first run that no longer finds it.
+package quality
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"partijgedrag/internal/politics"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Severities are ordered from the most to the least severe.
+var Severities = []Severity{SeverityError, SeverityWarning, SeverityInfo}
+
+func ParseSeverity(value string) (Severity, error) {
+	severity := Severity(value)
+	if !slices.Contains(Severities, severity) {
+		return "", fmt.Errorf("unknown severity %q", value)
+	}
+	return severity, nil
+}
+
+// DefaultStaleAfter is how long a voted decision may go without votes before
+// decision-without-votes reports it.
+const DefaultStaleAfter = 14 * 24 * time.Hour
+
+// seatWeight counts a faction vote for its seats and a member vote for one.
+const seatWeight = `CASE WHEN v.person_source_id IS NULL THEN COALESCE(v.party_size, 1) ELSE 1 END`
+
+// candidate is one row of a rule query. Every query selects all columns, in
+// this order, and leaves the ones its rule does not read empty.
+type candidate struct {
+	SubjectKey   string
+	MotionKey    string
+	SeatsFor     int
+	SeatsAgainst int
+	DecisionText string
+	PartyName    string
+	VoteTypes    []string
+	VotingType   string
+	CreatedAt    time.Time
+}
+
+// violation is what a Check makes of a candidate that breaks its rule.
+type violation struct {
+	Severity Severity
+	Detail   string
+}
+
+// Rule is one check. Query gathers the candidates, one row per subject key
+// unique within the rule, and Check decides which of them break the rule.
+type Rule struct {
+	Key         string
+	Description string
+	Query       string
+	Check       func(candidate candidate, now time.Time, options Options) (violation, bool)
+}
+
+var Rules = []Rule{
+	{
+		Key:         "seat-total",
+		Description: "Voor en Tegen tellen per besluit niet op tot 150 zetels.",
+		Query: `
+			SELECT d.decision_key,
+			       d.motion_key,
+			       COALESCE(sum(` + seatWeight + `) FILTER (WHERE v.vote_type = 'Voor'), 0)::int,
+			       COALESCE(sum(` + seatWeight + `) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int,
+			       '', '', '{}'::text[], '', now()
+			FROM decisions d
+			JOIN votes v ON v.decision_key = d.decision_key
+			WHERE d.source_deleted = false
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+			  AND v.vote_type IN ('Voor', 'Tegen')
+			GROUP BY d.decision_key, d.motion_key
+		`,
+		Check: checkSeatTotal,
+	},
+	{
+		Key:         "outcome-mismatch",
+		Description: "De besluittekst noemt de motie aangenomen of verworpen, maar de zetels zeggen het omgekeerde.",
+		Query: `
+			SELECT d.decision_key,
+			       d.motion_key,
+			       COALESCE(sum(` + seatWeight + `) FILTER (WHERE v.vote_type = 'Voor'), 0)::int,
+			       COALESCE(sum(` + seatWeight + `) FILTER (WHERE v.vote_type = 'Tegen'), 0)::int,
+			       COALESCE(d.decision_text, ''), '', '{}'::text[], '', now()
+			FROM decisions d
+			JOIN votes v ON v.decision_key = d.decision_key
+			WHERE d.source_deleted = false
+			  AND v.source_deleted = false
+			  AND v.mistake = false
+			GROUP BY d.decision_key, d.motion_key, d.decision_text
+		`,
+		Check: checkOutcomeMismatch,
+	},
+	{
+		Key:         "duplicate-party-vote",
+		Description: "Een fractie stemde twee keer op hetzelfde besluit.",
+		// Loading every faction vote would be most of the table; the query
+		// only passes the decisions a fractie has more than one vote on.
+		Query: `
+			SELECT v.decision_key || ':' || v.party_source_id,
+			       min(v.motion_key),
+			       0, 0, '',
+			       COALESCE(min(v.party_name), v.party_source_id),
+			       array_agg(COALESCE(v.vote_type, '?') ORDER BY v.source_id),
+			       '', now()
+			FROM votes v
+			WHERE v.source_deleted = false
+			  AND v.mistake = false
+			  AND v.person_source_id IS NULL
+			  AND v.party_source_id IS NOT NULL
+			GROUP BY v.decision_key, v.party_source_id
+			HAVING count(*) > 1
+		`,
+		Check: checkDuplicatePartyVote,
+	},
+	{
+		Key:         "unknown-party",
+		Description: "Een stem verwijst naar een fractie die niet in parties staat.",
+		Query: `
+			SELECT v.motion_key || ':' || v.party_source_id,
+			       v.motion_key,
+			       0, 0, '',
+			       v.party_source_id || ' (' || COALESCE(min(v.party_name), '?') || ')',
+			       array_agg(COALESCE(v.vote_type, '?')),
+			       '', now()
+			FROM votes v
+			WHERE v.source_deleted = false
+			  AND v.party_source_id IS NOT NULL
+			  AND NOT EXISTS (
+			    SELECT 1
+			    FROM parties p
+			    WHERE p.source_key = v.source_key
+			      AND p.source_id = v.party_source_id
+			  )
+			GROUP BY v.motion_key, v.party_source_id
+		`,
+		Check: checkUnknownParty,
+	},
+	{
+		Key:         "decision-without-votes",
+		Description: "Over een besluit is gestemd, maar er zijn na de wachttijd nog geen stemmen.",
+		Query: `
+			SELECT d.decision_key,
+			       d.motion_key,
+			       0, 0, '', '', '{}'::text[],
+			       d.voting_type,
+			       d.created_at
+			FROM decisions d
+			WHERE d.source_deleted = false
+			  AND d.voting_type IS NOT NULL
+			  AND NOT EXISTS (
+			    SELECT 1
+			    FROM votes v
+			    WHERE v.decision_key = d.decision_key
+			      AND v.source_deleted = false
+			  )
+		`,
+		Check: checkDecisionWithoutVotes,
+	},
+}
+
+// checkSeatTotal reports a decision whose Voor and Tegen seats do not fill
+// the house: more seats than there are is an error, fewer a warning, since a
+// fractie that did not vote leaves seats out.
+func checkSeatTotal(candidate candidate, now time.Time, options Options) (violation, bool) {
+	total := candidate.SeatsFor + candidate.SeatsAgainst
+	if total == politics.HouseSeats {
+		return violation{}, false
+	}
+	severity := SeverityWarning
+	if total > politics.HouseSeats {
+		severity = SeverityError
+	}
+	return violation{
+		Severity: severity,
+		Detail:   fmt.Sprintf("%d zetels voor, %d tegen, samen %d", candidate.SeatsFor, candidate.SeatsAgainst, total),
+	}, true
+}
+
+// checkOutcomeMismatch compares the outcome the decision text names with the
+// seat-weighted tally. A tie is not carried.
+func checkOutcomeMismatch(candidate candidate, now time.Time, options Options) (violation, bool) {
+	text := strings.ToLower(candidate.DecisionText)
+	carried := politics.Carried(candidate.SeatsFor, candidate.SeatsAgainst)
+	if !(strings.Contains(text, "aangenomen") && !carried) && !(strings.Contains(text, "verworpen") && carried) {
+		return violation{}, false
+	}
+	return violation{
+		Severity: SeverityError,
+		Detail:   fmt.Sprintf("%s, maar %d zetels voor en %d tegen", candidate.DecisionText, candidate.SeatsFor, candidate.SeatsAgainst),
+	}, true
+}
+
+func checkDuplicatePartyVote(candidate candidate, now time.Time, options Options) (violation, bool) {
+	if len(candidate.VoteTypes) < 2 {
+		return violation{}, false
+	}
+	return violation{
+		Severity: SeverityError,
+		Detail:   fmt.Sprintf("%s stemde %d keer: %s", candidate.PartyName, len(candidate.VoteTypes), strings.Join(candidate.VoteTypes, ", ")),
+	}, true
+}
+
+// checkUnknownParty reports every candidate: the query already left out the
+// fracties parties knows.
+func checkUnknownParty(candidate candidate, now time.Time, options Options) (violation, bool) {
+	return violation{
+		Severity: SeverityWarning,
+		Detail:   fmt.Sprintf("fractie %s onbekend, %d stemmen", candidate.PartyName, len(candidate.VoteTypes)),
+	}, true
+}
+
+// checkDecisionWithoutVotes gives the votes of a decision the StaleAfter
+// grace to arrive before reporting it.
+func checkDecisionWithoutVotes(candidate candidate, now time.Time, options Options) (violation, bool) {
+	if !candidate.CreatedAt.Before(now.Add(-options.staleAfter())) {
+		return violation{}, false
+	}
+	return violation{
+		Severity: SeverityWarning,
+		Detail:   fmt.Sprintf("%s besluit zonder stemmen sinds %s", candidate.VotingType, candidate.CreatedAt.Format("2006-01-02")),
+	}, true
+}
+
+type Options struct {
+	// Rules selects rules by key; empty runs all.
+	Rules []string
+	// StaleAfter defaults to DefaultStaleAfter.
+	StaleAfter time.Duration
+}
+
+func (options Options) staleAfter() time.Duration {
+	if options.StaleAfter <= 0 {
+		return DefaultStaleAfter
+	}
+	return options.StaleAfter
+}
+
+type RuleStats struct {
+	Rule     string
+	Open     int
+	New      int
+	Resolved int
+}
+
+// SelectRules returns the rules with the given keys in rule order, or every
+// rule when keys is empty.
+func SelectRules(keys []string) ([]Rule, error) {
+	if len(keys) == 0 {
+		return Rules, nil
+	}
+	for _, key := range keys {
+		if !slices.ContainsFunc(Rules, func(rule Rule) bool { return rule.Key == key }) {
+			return nil, fmt.Errorf("unknown data-quality rule %q", key)
+		}
+	}
+	selected := []Rule{}
+	for _, rule := range Rules {
+		if slices.Contains(keys, rule.Key) {
+			selected = append(selected, rule)
+		}
+	}
+	return selected, nil
+}
+
+// Run evaluates the rules and updates their findings, one transaction per
+// rule.
+func Run(ctx context.Context, pool *pgxpool.Pool, options Options) ([]RuleStats, error) {
+	rules, err := SelectRules(options.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []RuleStats{}
+	for _, rule := range rules {
+		ruleStats, err := runRule(ctx, pool, rule, options)
+		if err != nil {
+			return stats, fmt.Errorf("data-quality rule %s: %w", rule.Key, err)
+		}
+		stats = append(stats, ruleStats)
+	}
+	return stats, nil
+}
+
+func runRule(ctx context.Context, pool *pgxpool.Pool, rule Rule, options Options) (RuleStats, error) {
+	stats := RuleStats{Rule: rule.Key}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, rule.Query)
+	if err != nil {
+		return stats, err
+	}
+	now := time.Now()
+	subjects, motions, severities, details := []string{}, []string{}, []string{}, []string{}
+	for rows.Next() {
+		var row candidate
+		if err := rows.Scan(
+			&row.SubjectKey,
+			&row.MotionKey,
+			&row.SeatsFor,
+			&row.SeatsAgainst,
+			&row.DecisionText,
+			&row.PartyName,
+			&row.VoteTypes,
+			&row.VotingType,
+			&row.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		found, ok := rule.Check(row, now, options)
+		if !ok {
+			continue
+		}
+		subjects = append(subjects, row.SubjectKey)
+		motions = append(motions, row.MotionKey)
+		severities = append(severities, string(found.Severity))
+		details = append(details, found.Detail)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+	stats.Open = len(subjects)
+
+	open, err := loadOpenSubjects(ctx, tx, rule.Key)
+	if err != nil {
+		return stats, err
+	}
+	stats.New, stats.Resolved = countFindingChanges(open, subjects)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO data_quality_findings (rule_key, subject_key, motion_key, severity, detail)
+		SELECT $1, f.subject_key, f.motion_key, f.severity, f.detail
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::text[]) AS f(subject_key, motion_key, severity, detail)
+		ON CONFLICT (rule_key, subject_key) DO UPDATE
+		SET motion_key = EXCLUDED.motion_key,
+		    severity = EXCLUDED.severity,
+		    detail = EXCLUDED.detail,
+		    first_seen_at = CASE WHEN data_quality_findings.resolved_at IS NULL THEN data_quality_findings.first_seen_at ELSE now() END,
+		    last_seen_at = now(),
+		    resolved_at = NULL
+	`, rule.Key, subjects, motions, severities, details); err != nil {
+		return stats, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE data_quality_findings
+		SET resolved_at = now()
+		WHERE rule_key = $1
+		  AND resolved_at IS NULL
+		  AND NOT (subject_key = ANY($2::text[]))
+	`, rule.Key, subjects); err != nil {
+		return stats, err
+	}
+	return stats, tx.Commit(ctx)
+}
+
+func loadOpenSubjects(ctx context.Context, tx pgx.Tx, ruleKey string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT subject_key
+		FROM data_quality_findings
+		WHERE rule_key = $1
+		  AND resolved_at IS NULL
+	`, ruleKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []string{}
+	for rows.Next() {
+		var subject string
+		if err := rows.Scan(&subject); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, rows.Err()
+}
+
+// countFindingChanges compares the findings open before a run with the ones
+// it found: a subject found but not open is new, also when it was resolved
+// before and reopens; one open but not found is resolved.
+func countFindingChanges(open []string, found []string) (opened int, resolved int) {
+	wasOpen := map[string]bool{}
+	for _, subject := range open {
+		wasOpen[subject] = true
+	}
+	stillFound := map[string]bool{}
+	for _, subject := range found {
+		stillFound[subject] = true
+		if !wasOpen[subject] {
+			opened++
+		}
+	}
+	for subject := range wasOpen {
+		if !stillFound[subject] {
+			resolved++
+		}
+	}
+	return opened, resolved
+}
@@ -0,0 +1,148 @@
+package quality
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRulesHaveUniqueKeys(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rule := range Rules {
+		if rule.Key == "" || rule.Description == "" || rule.Query == "" || rule.Check == nil {
+			t.Fatalf("incomplete rule %+v", rule)
+		}
+		if seen[rule.Key] {
+			t.Fatalf("rule %s defined twice", rule.Key)
+		}
+		seen[rule.Key] = true
+	}
+}
+
+func TestSelectRulesKeepsRuleOrder(t *testing.T) {
+	rules, err := SelectRules([]string{"unknown-party", "seat-total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].Key != "seat-total" || rules[1].Key != "unknown-party" {
+		t.Fatalf("selected %v", rules)
+	}
+	if _, err := SelectRules([]string{"seat-totals"}); err == nil {
+		t.Fatal("accepted an unknown rule")
+	}
+	if all, _ := SelectRules(nil); len(all) != len(Rules) {
+		t.Fatalf("no keys selected %d rules, want all %d", len(all), len(Rules))
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if severity, err := ParseSeverity("warning"); err != nil || severity != SeverityWarning {
+		t.Fatalf("ParseSeverity(warning) = %q, %v", severity, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Fatal("accepted an unknown severity")
+	}
+}
+
+func checkRule(t *testing.T, key string, row candidate, now time.Time, options Options) (violation, bool) {
+	t.Helper()
+	rules, err := SelectRules([]string{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules[0].Check(row, now, options)
+}
+
+func TestSeatTotalRule(t *testing.T) {
+	for _, test := range []struct {
+		seatsFor, seatsAgainst int
+		want                   Severity
+	}{
+		{seatsFor: 76, seatsAgainst: 74},
+		{seatsFor: 0, seatsAgainst: 150},
+		{seatsFor: 70, seatsAgainst: 74, want: SeverityWarning},
+		{seatsFor: 80, seatsAgainst: 74, want: SeverityError},
+	} {
+		found, ok := checkRule(t, "seat-total", candidate{SeatsFor: test.seatsFor, SeatsAgainst: test.seatsAgainst}, time.Now(), Options{})
+		if ok != (test.want != "") || found.Severity != test.want {
+			t.Fatalf("seat-total(%d, %d) = %+v, %v, want %q", test.seatsFor, test.seatsAgainst, found, ok, test.want)
+		}
+	}
+	found, _ := checkRule(t, "seat-total", candidate{SeatsFor: 80, SeatsAgainst: 74}, time.Now(), Options{})
+	if found.Detail != "80 zetels voor, 74 tegen, samen 154" {
+		t.Fatalf("detail = %q", found.Detail)
+	}
+}
+
+func TestOutcomeMismatchRule(t *testing.T) {
+	for _, test := range []struct {
+		text                   string
+		seatsFor, seatsAgainst int
+		want                   bool
+	}{
+		{text: "Aangenomen.", seatsFor: 76, seatsAgainst: 74},
+		{text: "Aangenomen.", seatsFor: 74, seatsAgainst: 76, want: true},
+		{text: "Aangenomen.", seatsFor: 75, seatsAgainst: 75, want: true},
+		{text: "Verworpen.", seatsFor: 75, seatsAgainst: 75},
+		{text: "Verworpen.", seatsFor: 76, seatsAgainst: 74, want: true},
+		{text: "Aangehouden.", seatsFor: 0, seatsAgainst: 150},
+	} {
+		found, ok := checkRule(t, "outcome-mismatch", candidate{DecisionText: test.text, SeatsFor: test.seatsFor, SeatsAgainst: test.seatsAgainst}, time.Now(), Options{})
+		if ok != test.want {
+			t.Fatalf("outcome-mismatch(%q, %d, %d) = %v, want %v", test.text, test.seatsFor, test.seatsAgainst, ok, test.want)
+		}
+		if ok && (found.Severity != SeverityError || found.Detail == "") {
+			t.Fatalf("outcome-mismatch(%q) = %+v", test.text, found)
+		}
+	}
+}
+
+func TestDuplicatePartyVoteRule(t *testing.T) {
+	if _, ok := checkRule(t, "duplicate-party-vote", candidate{PartyName: "VVD", VoteTypes: []string{"Voor"}}, time.Now(), Options{}); ok {
+		t.Fatal("one vote reported as a duplicate")
+	}
+	found, ok := checkRule(t, "duplicate-party-vote", candidate{PartyName: "VVD", VoteTypes: []string{"Voor", "Tegen"}}, time.Now(), Options{})
+	if !ok || found.Severity != SeverityError || found.Detail != "VVD stemde 2 keer: Voor, Tegen" {
+		t.Fatalf("duplicate-party-vote = %+v, %v", found, ok)
+	}
+}
+
+func TestUnknownPartyRule(t *testing.T) {
+	found, ok := checkRule(t, "unknown-party", candidate{PartyName: "abc (Nieuw)", VoteTypes: []string{"Voor", "Voor", "Tegen"}}, time.Now(), Options{})
+	if !ok || found.Severity != SeverityWarning || found.Detail != "fractie abc (Nieuw) onbekend, 3 stemmen" {
+		t.Fatalf("unknown-party = %+v, %v", found, ok)
+	}
+}
+
+func TestDecisionWithoutVotesRuleWaitsStaleAfter(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		createdAt  time.Time
+		staleAfter time.Duration
+		want       bool
+	}{
+		{createdAt: now.Add(-13 * 24 * time.Hour)},
+		{createdAt: now.Add(-DefaultStaleAfter)},
+		{createdAt: now.Add(-DefaultStaleAfter - time.Minute), want: true},
+		{createdAt: now.Add(-2 * 24 * time.Hour), staleAfter: 24 * time.Hour, want: true},
+	} {
+		row := candidate{VotingType: "Met handopsteken", CreatedAt: test.createdAt}
+		found, ok := checkRule(t, "decision-without-votes", row, now, Options{StaleAfter: test.staleAfter})
+		if ok != test.want {
+			t.Fatalf("decision-without-votes(%s, %s) = %v, want %v", test.createdAt, test.staleAfter, ok, test.want)
+		}
+		if ok && found.Detail != "Met handopsteken besluit zonder stemmen sinds "+test.createdAt.Format("2006-01-02") {
+			t.Fatalf("detail = %q", found.Detail)
+		}
+	}
+}
+
+func TestCountFindingChanges(t *testing.T) {
+	// b stays open, c is resolved, d is new and a, resolved before, reopens.
+	opened, resolved := countFindingChanges([]string{"b", "c"}, []string{"a", "b", "d"})
+	if opened != 2 || resolved != 1 {
+		t.Fatalf("opened, resolved = %d, %d, want 2, 1", opened, resolved)
+	}
+	if opened, resolved := countFindingChanges(nil, nil); opened != 0 || resolved != 0 {
+		t.Fatalf("empty run = %d, %d", opened, resolved)
+	}
+}
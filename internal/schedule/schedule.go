@@ -0,0 +1,258 @@
+// Package schedule plans the pipeline runs of the built-in scheduler. Each
+// pipeline runs on its own interval, optionally only inside a weekly window,
+// e.g. every 10 minutes on Tuesdays and Thursdays between 14:00 and 20:00
+// Amsterdam time, when the plenary votes come in.
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Schedule struct {
+	Interval time.Duration
+	// Days limits runs to these weekdays; empty means every day.
+	Days []time.Weekday
+	// Start and End bound the daily window as offsets from midnight. An End
+	// of zero means until midnight.
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// Every runs at interval at any time of day.
+func Every(interval time.Duration, location *time.Location) Schedule {
+	return Schedule{Interval: interval, Location: location}
+}
+
+// Next is the run after one at last: an interval later, moved forward to the
+// start of the next window if that falls outside it.
+func (schedule Schedule) Next(last time.Time) time.Time {
+	return schedule.Fit(last.Add(schedule.Interval))
+}
+
+// Resume returns when a run that was planned at planned should happen, as of
+// now. An overdue run, e.g. one missed while serve was down, moves to now or
+// the next window, and a plan further out than a fresh one, left over from a
+// longer interval, is brought forward.
+func (schedule Schedule) Resume(planned time.Time, now time.Time) time.Time {
+	if planned.Before(now) {
+		return schedule.Fit(now)
+	}
+	if fresh := schedule.Next(now); planned.After(fresh) {
+		return fresh
+	}
+	return planned
+}
+
+// Fit returns at if it lies inside the window, and otherwise the start of the
+// first window after it. Window bounds are wall-clock times, so they stay put
+// across daylight saving changes.
+func (schedule Schedule) Fit(at time.Time) time.Time {
+	location := schedule.Location
+	if location == nil {
+		location = time.UTC
+	}
+	local := at.In(location)
+	end := schedule.End
+	if end == 0 {
+		end = 24 * time.Hour
+	}
+
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, location)
+		if len(schedule.Days) > 0 && !slices.Contains(schedule.Days, day.Weekday()) {
+			continue
+		}
+		windowStart := wallClock(day, schedule.Start)
+		if offset > 0 || local.Before(windowStart) {
+			return windowStart
+		}
+		if local.Before(wallClock(day, end)) {
+			return at
+		}
+	}
+	return at
+}
+
+func wallClock(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// String formats the schedule in the syntax Parse accepts.
+func (schedule Schedule) String() string {
+	parts := []string{formatInterval(schedule.Interval)}
+	if len(schedule.Days) > 0 {
+		days := make([]string, 0, len(schedule.Days))
+		for _, day := range schedule.Days {
+			days = append(days, dayNames[day])
+		}
+		parts = append(parts, strings.Join(days, ","))
+	}
+	if schedule.Start != 0 || schedule.End != 0 {
+		end := schedule.End
+		if end == 0 {
+			end = 24 * time.Hour
+		}
+		parts = append(parts, formatClock(schedule.Start)+"-"+formatClock(end))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatInterval(interval time.Duration) string {
+	text := interval.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
+
+var dayNames = map[time.Weekday]string{
+	time.Monday:    "mon",
+	time.Tuesday:   "tue",
+	time.Wednesday: "wed",
+	time.Thursday:  "thu",
+	time.Friday:    "fri",
+	time.Saturday:  "sat",
+	time.Sunday:    "sun",
+}
+
+func parseDay(value string) (time.Weekday, bool) {
+	for day, name := range dayNames {
+		if name == value {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// Parse reads a schedule such as "10m tue,thu 14:00-20:00": an interval,
+// then optionally weekdays (a list, or a range like mon-fri) and a daily
+// window. Times are in location.
+func Parse(spec string, location *time.Location) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Schedule{}, fmt.Errorf("empty schedule")
+	}
+	interval, err := time.ParseDuration(fields[0])
+	if err != nil {
+		return Schedule{}, fmt.Errorf("interval: %w", err)
+	}
+	if interval <= 0 {
+		return Schedule{}, fmt.Errorf("interval must be greater than 0")
+	}
+	schedule := Schedule{Interval: interval, Location: location}
+
+	for _, field := range fields[1:] {
+		if strings.Contains(field, ":") {
+			if schedule.Start != 0 || schedule.End != 0 {
+				return Schedule{}, fmt.Errorf("more than one window in %q", spec)
+			}
+			if schedule.Start, schedule.End, err = parseWindow(field); err != nil {
+				return Schedule{}, err
+			}
+			continue
+		}
+		if len(schedule.Days) > 0 {
+			return Schedule{}, fmt.Errorf("more than one day list in %q", spec)
+		}
+		if schedule.Days, err = parseDays(field); err != nil {
+			return Schedule{}, err
+		}
+	}
+	return schedule, nil
+}
+
+func parseDays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, item := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		from, ok := parseDay(first)
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q, want mon, tue, wed, thu, fri, sat or sun", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = parseDay(last); !ok {
+				return nil, fmt.Errorf("unknown day %q, want mon, tue, wed, thu, fri, sat or sun", last)
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseWindow(value string) (time.Duration, time.Duration, error) {
+	first, last, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("window %q, want HH:MM-HH:MM", value)
+	}
+	start, err := parseClock(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(last)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start >= end {
+		return 0, 0, fmt.Errorf("window %q ends before it starts", value)
+	}
+	if end == 24*time.Hour {
+		end = 0
+	}
+	return start, end, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("time %q, want HH:MM", value)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("time %q out of range", value)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// ParseList reads pipeline schedules separated by semicolons, each as
+// name=schedule, e.g. "parties=24h; motion-votes=10m tue,thu 14:00-20:00".
+func ParseList(value string, location *time.Location) (map[string]Schedule, error) {
+	schedules := map[string]Schedule{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("schedule %q, want pipeline=interval [days] [HH:MM-HH:MM]", entry)
+		}
+		if _, ok := schedules[name]; ok {
+			return nil, fmt.Errorf("pipeline %s is scheduled twice", name)
+		}
+		schedule, err := Parse(spec, location)
+		if err != nil {
+			return nil, fmt.Errorf("schedule for %s: %w", name, err)
+		}
+		schedules[name] = schedule
+	}
+	return schedules, nil
+}
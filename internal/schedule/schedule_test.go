@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func amsterdam(t *testing.T) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skip("no zoneinfo for Europe/Amsterdam")
+	}
+	return location
+}
+
+func TestParse(t *testing.T) {
+	location := amsterdam(t)
+	schedule, err := Parse("10m tue,thu 14:00-20:00", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Interval != 10*time.Minute || !slices.Equal(schedule.Days, []time.Weekday{time.Tuesday, time.Thursday}) ||
+		schedule.Start != 14*time.Hour || schedule.End != 20*time.Hour {
+		t.Fatalf("parsed %+v", schedule)
+	}
+	if got := schedule.String(); got != "10m tue,thu 14:00-20:00" {
+		t.Fatalf("String() = %q", got)
+	}
+
+	weekdays, err := Parse("1h30m mon-fri", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weekdays.Days) != 5 || weekdays.String() != "1h30m mon,tue,wed,thu,fri" {
+		t.Fatalf("parsed %+v, String() = %q", weekdays, weekdays.String())
+	}
+
+	for _, spec := range []string{"", "0s", "10m funday", "10m 20:00-14:00", "10m 14:00", "10m 9:00-10:00", "10m tue wed"} {
+		if _, err := Parse(spec, location); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	schedules, err := ParseList("parties=24h; motion-votes=10m tue,thu 14:00-20:00;", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 2 || schedules["parties"].Interval != 24*time.Hour || schedules["parties"].String() != "24h" {
+		t.Fatalf("parsed %+v", schedules)
+	}
+	if _, err := ParseList("parties=24h;parties=1h", time.UTC); err == nil {
+		t.Fatal("accepted a pipeline scheduled twice")
+	}
+	if _, err := ParseList("parties 24h", time.UTC); err == nil {
+		t.Fatal("accepted an entry without =")
+	}
+}
+
+func TestNextStaysInsideTheWindow(t *testing.T) {
+	location := amsterdam(t)
+	schedule, err := Parse("10m tue,thu 14:00-20:00", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		last time.Time
+		want time.Time
+	}{
+		// Inside the window: an interval later.
+		{time.Date(2024, 3, 5, 15, 0, 0, 0, location), time.Date(2024, 3, 5, 15, 10, 0, 0, location)},
+		// Past the end on Tuesday: Thursday's window.
+		{time.Date(2024, 3, 5, 19, 55, 0, 0, location), time.Date(2024, 3, 7, 14, 0, 0, 0, location)},
+		// Before the start: the same day's window.
+		{time.Date(2024, 3, 7, 9, 0, 0, 0, location), time.Date(2024, 3, 7, 14, 0, 0, 0, location)},
+		// Thursday evening: next Tuesday.
+		{time.Date(2024, 3, 7, 21, 0, 0, 0, location), time.Date(2024, 3, 12, 14, 0, 0, 0, location)},
+		// Across the switch to summer time the window stays at 14:00 local.
+		{time.Date(2024, 3, 28, 20, 0, 0, 0, location), time.Date(2024, 4, 2, 14, 0, 0, 0, location)},
+	}
+	for _, testCase := range cases {
+		if got := schedule.Next(testCase.last); !got.Equal(testCase.want) {
+			t.Errorf("Next(%s) = %s, want %s", testCase.last, got.In(location), testCase.want)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	hourly := Every(time.Hour, time.UTC)
+
+	if got := hourly.Resume(now.Add(-3*time.Hour), now); !got.Equal(now) {
+		t.Fatalf("overdue plan resumes at %s, want now", got)
+	}
+	if got := hourly.Resume(now.Add(30*time.Minute), now); !got.Equal(now.Add(30 * time.Minute)) {
+		t.Fatalf("planned run moved to %s", got)
+	}
+	if got := hourly.Resume(now.Add(24*time.Hour), now); !got.Equal(now.Add(time.Hour)) {
+		t.Fatalf("plan of a longer interval resumes at %s, want in an hour", got)
+	}
+
+	evenings, err := Parse("1h 18:00-22:00", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := evenings.Resume(now.Add(-time.Hour), now); !got.Equal(time.Date(2024, 3, 5, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("overdue plan outside the window resumes at %s, want 18:00", got)
+	}
+}
@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Plan is the persisted state of one pipeline's schedule.
+type Plan struct {
+	Pipeline       string
+	NextRunAt      time.Time
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastError      *string
+}
+
+func LoadPlans(ctx context.Context, pool *pgxpool.Pool) (map[string]Plan, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT pipeline,
+		       next_run_at,
+		       last_started_at,
+		       last_finished_at,
+		       last_error
+		FROM sync_schedules
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := map[string]Plan{}
+	for rows.Next() {
+		var plan Plan
+		if err := rows.Scan(&plan.Pipeline, &plan.NextRunAt, &plan.LastStartedAt, &plan.LastFinishedAt, &plan.LastError); err != nil {
+			return nil, err
+		}
+		plans[plan.Pipeline] = plan
+	}
+	return plans, rows.Err()
+}
+
+// SaveNextRun plans the next run of pipeline, keeping what is known about
+// the last one.
+func SaveNextRun(ctx context.Context, pool *pgxpool.Pool, pipeline string, nextRunAt time.Time) error {
+	_, err := pool.Exec(ctx, `
+		INSERT INTO sync_schedules (pipeline, next_run_at)
+		VALUES ($1, $2)
+		ON CONFLICT (pipeline)
+		DO UPDATE SET next_run_at = EXCLUDED.next_run_at,
+		              updated_at = now()
+	`, pipeline, nextRunAt)
+	return err
+}
+
+// RecordRun stores the outcome of a run together with the next planned one.
+func RecordRun(ctx context.Context, pool *pgxpool.Pool, pipeline string, startedAt time.Time, runErr error, nextRunAt time.Time) error {
+	var lastError *string
+	if runErr != nil {
+		message := runErr.Error()
+		lastError = &message
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO sync_schedules (pipeline, next_run_at, last_started_at, last_finished_at, last_error)
+		VALUES ($1, $2, $3, now(), $4)
+		ON CONFLICT (pipeline)
+		DO UPDATE SET next_run_at = EXCLUDED.next_run_at,
+		              last_started_at = EXCLUDED.last_started_at,
+		              last_finished_at = EXCLUDED.last_finished_at,
+		              last_error = EXCLUDED.last_error,
+		              updated_at = now()
+	`, pipeline, nextRunAt, startedAt, lastError)
+	return err
+}
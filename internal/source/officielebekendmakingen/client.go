@@ -18,9 +18,16 @@ type Client struct {
 }
 
 func NewClient() *Client {
+	return NewClientWithTransport(nil)
+}
+
+// NewClientWithTransport sends requests through transport. A nil transport
+// uses http.DefaultTransport.
+func NewClientWithTransport(transport http.RoundTripper) *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Transport: transport,
+			Timeout:   60 * time.Second,
 		},
 	}
 }
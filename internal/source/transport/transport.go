@@ -0,0 +1,187 @@
+// Package transport lets the source clients record the responses they receive
+// to a fixture directory and replay them later without the network. A sync run
+// in replay mode against a recorded directory is deterministic, which makes it
+// usable for end-to-end tests and for reproducing an ingestion issue locally.
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type Mode string
+
+const (
+	// ModeLive uses the network and records nothing.
+	ModeLive Mode = "live"
+	// ModeRecord uses the network and writes every response to the fixture
+	// directory, replacing an earlier recording of the same request.
+	ModeRecord Mode = "record"
+	// ModeReplay serves responses from the fixture directory only.
+	ModeReplay Mode = "replay"
+)
+
+// ErrNotRecorded is returned in replay mode for a request that has no
+// recorded response.
+var ErrNotRecorded = errors.New("transport: no recorded response")
+
+// ParseMode accepts the Mode values; an empty string means ModeLive.
+func ParseMode(value string) (Mode, error) {
+	switch Mode(value) {
+	case "", ModeLive:
+		return ModeLive, nil
+	case ModeRecord, ModeReplay:
+		return Mode(value), nil
+	default:
+		return "", fmt.Errorf("unknown transport mode %q, want live, record or replay", value)
+	}
+}
+
+// New returns the transport for mode. It returns nil in live mode, so that
+// http.Client falls back to http.DefaultTransport.
+func New(mode Mode, dir string) (http.RoundTripper, error) {
+	switch mode {
+	case "", ModeLive:
+		return nil, nil
+	case ModeRecord:
+		if dir == "" {
+			return nil, errors.New("transport: record mode needs a fixture directory")
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		return &Recorder{Dir: dir, Next: http.DefaultTransport}, nil
+	case ModeReplay:
+		if dir == "" {
+			return nil, errors.New("transport: replay mode needs a fixture directory")
+		}
+		if _, err := os.Stat(dir); err != nil {
+			return nil, err
+		}
+		return &Replayer{Dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown transport mode %q", mode)
+	}
+}
+
+// recording is the metadata of one response. The body is stored next to it, so
+// a recorded payload can be read and edited as is.
+type recording struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+}
+
+// Recorder passes requests on to Next and records every response it receives,
+// including error statuses, so retries replay the same way.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+}
+
+func (recorder *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := recorder.Next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fixtureName(request)
+	meta, err := json.MarshalIndent(recording{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Status: response.StatusCode,
+		Header: response.Header,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(recorder.Dir, name+".body"), body); err != nil {
+		return nil, err
+	}
+	// The metadata is written last: replay treats it as the marker of a
+	// complete recording.
+	if err := writeFileAtomic(filepath.Join(recorder.Dir, name+".json"), meta); err != nil {
+		return nil, err
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	return response, nil
+}
+
+// Replayer serves recorded responses and never touches the network.
+type Replayer struct {
+	Dir string
+}
+
+func (replayer *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	name := fixtureName(request)
+	meta, err := os.ReadFile(filepath.Join(replayer.Dir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, request.Method, request.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recorded recording
+	if err := json.Unmarshal(meta, &recorded); err != nil {
+		return nil, fmt.Errorf("transport: parse %s.json: %w", name, err)
+	}
+	body, err := os.ReadFile(filepath.Join(replayer.Dir, name+".body"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+// fixtureName identifies a request by method and full URL. The clients only
+// issue GET requests, so the body is not part of it.
+func fixtureName(request *http.Request) string {
+	sum := sha256.Sum256([]byte(request.Method + " " + request.URL.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic keeps concurrent recorders and interrupted runs from leaving
+// a half-written fixture behind.
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requests++
+		response.Header().Set("Content-Type", "application/json")
+		if request.URL.Query().Get("missing") != "" {
+			response.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = io.WriteString(response, `{"value":[]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := New(ModeRecord, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/Zaak?$top=1", "/Zaak?missing=1"} {
+		if _, _, err := get(recorder, server.URL+path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replayer, err := New(ModeReplay, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body, err := get(replayer, server.URL+"/Zaak?$top=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK || body != `{"value":[]}` {
+		t.Fatalf("replayed %d %q", status, body)
+	}
+	if status, _, err := get(replayer, server.URL+"/Zaak?missing=1"); err != nil || status != http.StatusNotFound {
+		t.Fatalf("replayed %d, %v, want recorded 404", status, err)
+	}
+	if requests != 2 {
+		t.Fatalf("server saw %d requests, want 2", requests)
+	}
+
+	if _, _, err := get(replayer, server.URL+"/Zaak?$top=2"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("err = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ParseMode(""); err != nil || mode != ModeLive {
+		t.Fatalf("ParseMode(\"\") = %q, %v", mode, err)
+	}
+	if _, err := ParseMode("replay"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseMode("playback"); err == nil {
+		t.Fatal("ParseMode(\"playback\") succeeded")
+	}
+}
+
+func get(roundTripper http.RoundTripper, url string) (int, string, error) {
+	client := &http.Client{Transport: roundTripper}
+	response, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	return response.StatusCode, string(body), err
+}
@@ -48,14 +48,24 @@ func (value *Time) UnmarshalJSON(data []byte) error {
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	limiter    *tokenBucket
+	counters   RequestCounter
 }
 
 func NewClient(baseURL string) *Client {
+	return NewClientWithTransport(baseURL, nil)
+}
+
+// NewClientWithTransport sends requests through transport, e.g. to record or
+// replay them. A nil transport uses http.DefaultTransport.
+func NewClientWithTransport(baseURL string, transport http.RoundTripper) *Client {
 	return &Client{
 		baseURL: strings.TrimRight(baseURL, "/"),
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Transport: transport,
+			Timeout:   60 * time.Second,
 		},
+		limiter: hostBucket(baseURL),
 	}
 }
 
@@ -72,8 +82,22 @@ type MotionRecord struct {
 	GewijzigdOp    *Time   `json:"GewijzigdOp"`
 	ApiGewijzigdOp *Time   `json:"ApiGewijzigdOp"`
 	Verwijderd     *bool   `json:"Verwijderd"`
-	Raw            json.RawMessage
-}
+	// Dossiers is the Kamerstukdossier the zaak belongs to. For an amendement
+	// that is the wetsvoorstel it amends.
+	Dossiers []ZaakDossierRecord `json:"Kamerstukdossier"`
+	// Actors holds the indiener and medeindieners of the zaak. Other relations,
+	// such as the voortouwcommissie, are filtered out in the request.
+	Actors []ZaakActorRecord `json:"ZaakActor"`
+	Raw    json.RawMessage
+}
+
+// Zaak soorten ingested as motions. Amendementen and wetsvoorstellen are
+// stored alongside moties and distinguished by motions.kind.
+const (
+	ZaakSoortMotie        = "Motie"
+	ZaakSoortAmendement   = "Amendement"
+	ZaakSoortWetsvoorstel = "Wetsvoorstel"
+)
 
 type PartyRecord struct {
 	ID            string  `json:"Id"`
@@ -112,6 +136,54 @@ func (record *PartyRecord) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MemberRecord is a Persoon: anyone the Kamer keeps a record of, not only
+// sitting members. FractieZetels is expanded so a member can be linked to the
+// fracties they sat for without a second round trip.
+type MemberRecord struct {
+	ID             string             `json:"Id"`
+	Nummer         *int               `json:"Nummer"`
+	Titels         *string            `json:"Titels"`
+	Initialen      *string            `json:"Initialen"`
+	Voornamen      *string            `json:"Voornamen"`
+	Roepnaam       *string            `json:"Roepnaam"`
+	Tussenvoegsel  *string            `json:"Tussenvoegsel"`
+	Achternaam     *string            `json:"Achternaam"`
+	Geslacht       *string            `json:"Geslacht"`
+	Functie        *string            `json:"Functie"`
+	Fractielabel   *string            `json:"Fractielabel"`
+	Woonplaats     *string            `json:"Woonplaats"`
+	GewijzigdOp    *Time              `json:"GewijzigdOp"`
+	ApiGewijzigdOp *Time              `json:"ApiGewijzigdOp"`
+	Verwijderd     *bool              `json:"Verwijderd"`
+	FractieZetels  []MemberSeatRecord `json:"FractieZetelPersoon"`
+	Raw            json.RawMessage
+}
+
+func (record *MemberRecord) UnmarshalJSON(data []byte) error {
+	type alias MemberRecord
+	var decoded alias
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*record = MemberRecord(decoded)
+	record.Raw = append(record.Raw[:0], data...)
+	return nil
+}
+
+// MemberSeatRecord is a FractieZetelPersoon: one stretch of a member holding a
+// seat for a fractie.
+type MemberSeatRecord struct {
+	ID           string  `json:"Id"`
+	Functie      *string `json:"Functie"`
+	Van          *Time   `json:"Van"`
+	TotEnMet     *Time   `json:"TotEnMet"`
+	Verwijderd   *bool   `json:"Verwijderd"`
+	FractieZetel *struct {
+		FractieID *string `json:"Fractie_Id"`
+	} `json:"FractieZetel"`
+}
+
 type DecisionRecord struct {
 	ID                            string  `json:"Id"`
 	AgendapuntID                  *string `json:"Agendapunt_Id"`
@@ -190,6 +262,25 @@ type ZaakDocumentRecord struct {
 	Verwijderd     *bool   `json:"Verwijderd"`
 }
 
+// ZaakActorRecord links a zaak to a member or fractie. Persoon_Id and
+// Fractie_Id are the same ids votes carry.
+type ZaakActorRecord struct {
+	ID           string  `json:"Id"`
+	Relatie      *string `json:"Relatie"`
+	ActorNaam    *string `json:"ActorNaam"`
+	ActorFractie *string `json:"ActorFractie"`
+	Functie      *string `json:"Functie"`
+	PersoonID    *string `json:"Persoon_Id"`
+	FractieID    *string `json:"Fractie_Id"`
+	Verwijderd   *bool   `json:"Verwijderd"`
+}
+
+// ZaakActor relations ingested with a zaak.
+const (
+	ZaakActorIndiener     = "Indiener"
+	ZaakActorMedeindiener = "Medeindiener"
+)
+
 type ZaakDossierRecord struct {
 	Nummer     json.Number `json:"Nummer"`
 	Toevoeging *string     `json:"Toevoeging"`
@@ -212,10 +303,21 @@ type ChangedPartiesPage struct {
 	NextURL string
 }
 
+type ChangedMembersPage struct {
+	Records []MemberRecord
+	NextURL string
+}
+
 func (client *Client) FetchChangedMotions(ctx context.Context, since time.Time, top int, skip int, nextURL string) (ChangedMotionsPage, error) {
+	return client.FetchChangedZaken(ctx, ZaakSoortMotie, since, top, skip, nextURL)
+}
+
+// FetchChangedZaken pages through zaken of one Soort, such as Motie or
+// Amendement, changed since the given time.
+func (client *Client) FetchChangedZaken(ctx context.Context, soort string, since time.Time, top int, skip int, nextURL string) (ChangedMotionsPage, error) {
 	requestURL := nextURL
 	if requestURL == "" {
-		requestURL = client.changedMotionsURL(since, top, skip)
+		requestURL = client.changedZakenURL(soort, since, top, skip)
 	}
 
 	var body struct {
@@ -232,6 +334,50 @@ func (client *Client) FetchChangedMotions(ctx context.Context, since time.Time,
 	}, nil
 }
 
+// CountChangedZaken counts the zaken FetchChangedZaken pages through, so a
+// run can tell how far along it is.
+func (client *Client) CountChangedZaken(ctx context.Context, soort string, since time.Time) (int, error) {
+	return client.CountEntities(ctx, "Zaak", changedZakenFilter(soort, since))
+}
+
+// MaxIDsPerRequest bounds FetchZakenByID and FetchPartiesByID, which put every
+// id in the URL.
+const MaxIDsPerRequest = 25
+
+// FetchZakenByID fetches zaken by id with the same fields and expansions as
+// FetchChangedZaken. Ids that do not exist are absent from the result.
+func (client *Client) FetchZakenByID(ctx context.Context, ids []string) ([]MotionRecord, error) {
+	var records []MotionRecord
+	for start := 0; start < len(ids); start += MaxIDsPerRequest {
+		end := min(start+MaxIDsPerRequest, len(ids))
+		var body struct {
+			Value []MotionRecord `json:"value"`
+		}
+		if err := client.fetchJSON(ctx, client.zakenByIDURL(ids[start:end]), &body); err != nil {
+			return nil, err
+		}
+		records = append(records, body.Value...)
+	}
+	return records, nil
+}
+
+// FetchPartiesByID fetches fracties by id. Ids that do not exist are absent
+// from the result.
+func (client *Client) FetchPartiesByID(ctx context.Context, ids []string) ([]PartyRecord, error) {
+	var records []PartyRecord
+	for start := 0; start < len(ids); start += MaxIDsPerRequest {
+		end := min(start+MaxIDsPerRequest, len(ids))
+		var body struct {
+			Value []PartyRecord `json:"value"`
+		}
+		if err := client.fetchJSON(ctx, client.partiesByIDURL(ids[start:end]), &body); err != nil {
+			return nil, err
+		}
+		records = append(records, body.Value...)
+	}
+	return records, nil
+}
+
 func (client *Client) FetchChangedParties(ctx context.Context, since time.Time, top int, skip int, nextURL string) (ChangedPartiesPage, error) {
 	requestURL := nextURL
 	if requestURL == "" {
@@ -252,6 +398,34 @@ func (client *Client) FetchChangedParties(ctx context.Context, since time.Time,
 	}, nil
 }
 
+func (client *Client) CountChangedParties(ctx context.Context, since time.Time) (int, error) {
+	return client.CountEntities(ctx, "Fractie", changedSinceFilter(since))
+}
+
+func (client *Client) FetchChangedMembers(ctx context.Context, since time.Time, top int, skip int, nextURL string) (ChangedMembersPage, error) {
+	requestURL := nextURL
+	if requestURL == "" {
+		requestURL = client.changedMembersURL(since, top, skip)
+	}
+
+	var body struct {
+		Value   []MemberRecord `json:"value"`
+		NextURL string         `json:"@odata.nextLink"`
+	}
+	if err := client.fetchJSON(ctx, requestURL, &body); err != nil {
+		return ChangedMembersPage{}, err
+	}
+
+	return ChangedMembersPage{
+		Records: body.Value,
+		NextURL: body.NextURL,
+	}, nil
+}
+
+func (client *Client) CountChangedMembers(ctx context.Context, since time.Time) (int, error) {
+	return client.CountEntities(ctx, "Persoon", changedSinceFilter(since))
+}
+
 // maxLogoBytes caps what we accept for a party logo. The largest logo the API
 // currently serves is ~160 KB; anything far beyond that is not an icon and has
 // no business being stored inline in the parties table.
@@ -280,6 +454,9 @@ func (client *Client) FetchPartyLogo(ctx context.Context, partySourceID string)
 	request.Header.Set("Accept", "image/*")
 	request.Header.Set("User-Agent", "partijgedrag-rewrite/0.1")
 
+	if err := client.limiter.Wait(ctx); err != nil {
+		return PartyLogo{}, err
+	}
 	response, err := client.httpClient.Do(request)
 	if err != nil {
 		return PartyLogo{}, err
@@ -388,9 +565,29 @@ func (client *Client) motionDocumentInfoURL(motionSourceID string) string {
 }
 
 func (client *Client) changedMotionsURL(since time.Time, top int, skip int) string {
+	return client.changedZakenURL(ZaakSoortMotie, since, top, skip)
+}
+
+func (client *Client) changedZakenURL(soort string, since time.Time, top int, skip int) string {
+	return client.zakenURL(changedZakenFilter(soort, since), top, skip)
+}
+
+func changedZakenFilter(soort string, since time.Time) string {
+	return fmt.Sprintf("Soort eq '%s' and %s", strings.ReplaceAll(soort, "'", "''"), changedSinceFilter(since))
+}
+
+func changedSinceFilter(since time.Time) string {
+	return fmt.Sprintf("ApiGewijzigdOp ge %s", formatODataDate(since))
+}
+
+func (client *Client) zakenByIDURL(ids []string) string {
+	return client.zakenURL(idFilter(ids), len(ids), 0)
+}
+
+func (client *Client) zakenURL(filter string, top int, skip int) string {
 	u, _ := url.Parse(client.baseURL + "/Zaak")
 	query := u.Query()
-	query.Set("$filter", fmt.Sprintf("Soort eq 'Motie' and ApiGewijzigdOp ge %s", formatODataDate(since)))
+	query.Set("$filter", filter)
 	query.Set("$select", strings.Join([]string{
 		"Id",
 		"Nummer",
@@ -405,6 +602,14 @@ func (client *Client) changedMotionsURL(since time.Time, top int, skip int) stri
 		"ApiGewijzigdOp",
 		"Verwijderd",
 	}, ","))
+	query.Set("$expand", strings.Join([]string{
+		"Kamerstukdossier($select=Nummer,Toevoeging)",
+		fmt.Sprintf(
+			"ZaakActor($select=Id,Relatie,ActorNaam,ActorFractie,Functie,Persoon_Id,Fractie_Id,Verwijderd;$filter=Relatie eq '%s' or Relatie eq '%s')",
+			ZaakActorIndiener,
+			ZaakActorMedeindiener,
+		),
+	}, ","))
 	query.Set("$orderby", "ApiGewijzigdOp asc,Id asc")
 	query.Set("$top", fmt.Sprintf("%d", top))
 	if skip > 0 {
@@ -416,9 +621,17 @@ func (client *Client) changedMotionsURL(since time.Time, top int, skip int) stri
 }
 
 func (client *Client) changedPartiesURL(since time.Time, top int, skip int) string {
+	return client.partiesURL(changedSinceFilter(since), top, skip)
+}
+
+func (client *Client) partiesByIDURL(ids []string) string {
+	return client.partiesURL(idFilter(ids), len(ids), 0)
+}
+
+func (client *Client) partiesURL(filter string, top int, skip int) string {
 	u, _ := url.Parse(client.baseURL + "/Fractie")
 	query := u.Query()
-	query.Set("$filter", fmt.Sprintf("ApiGewijzigdOp ge %s", formatODataDate(since)))
+	query.Set("$filter", filter)
 	query.Set("$select", strings.Join([]string{
 		"Id",
 		"Nummer",
@@ -445,6 +658,38 @@ func (client *Client) changedPartiesURL(since time.Time, top int, skip int) stri
 	return u.String()
 }
 
+func (client *Client) changedMembersURL(since time.Time, top int, skip int) string {
+	u, _ := url.Parse(client.baseURL + "/Persoon")
+	query := u.Query()
+	query.Set("$filter", changedSinceFilter(since))
+	query.Set("$select", strings.Join([]string{
+		"Id",
+		"Nummer",
+		"Titels",
+		"Initialen",
+		"Voornamen",
+		"Roepnaam",
+		"Tussenvoegsel",
+		"Achternaam",
+		"Geslacht",
+		"Functie",
+		"Fractielabel",
+		"Woonplaats",
+		"GewijzigdOp",
+		"ApiGewijzigdOp",
+		"Verwijderd",
+	}, ","))
+	query.Set("$expand", "FractieZetelPersoon($select=Id,Functie,Van,TotEnMet,Verwijderd;$expand=FractieZetel($select=Fractie_Id))")
+	query.Set("$orderby", "ApiGewijzigdOp asc,Id asc")
+	query.Set("$top", fmt.Sprintf("%d", top))
+	if skip > 0 {
+		query.Set("$skip", fmt.Sprintf("%d", skip))
+	}
+	query.Set("$count", "false")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 func (client *Client) motionDecisionsURL(motionSourceID string) string {
 	u, _ := url.Parse(fmt.Sprintf("%s/Zaak(%s)/Besluit", client.baseURL, motionSourceID))
 	query := u.Query()
@@ -490,44 +735,22 @@ func (client *Client) decisionVotesURL(decisionSourceID string) string {
 	return u.String()
 }
 
+// fetchJSON retries transient failures with backoff and gives up at once on a
+// permanent one; see IsRetryable.
 func (client *Client) fetchJSON(ctx context.Context, requestURL string, target any) error {
-	var lastErr error
-	for attempt := 1; attempt <= 3; attempt++ {
-		err := client.fetchJSONOnce(ctx, requestURL, target)
-		if err == nil {
-			return nil
-		}
-		lastErr = err
-
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(time.Duration(attempt) * time.Second):
-		}
-	}
-	return lastErr
+	return get(ctx, client.httpClient, client.limiter, &client.counters, requestURL, "application/json", func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(target)
+	})
 }
 
-func (client *Client) fetchJSONOnce(ctx context.Context, requestURL string, target any) error {
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
-		return err
+// idFilter matches any of the given ids. Ids are GUIDs, so they need no
+// quoting beyond the OData guid literal.
+func idFilter(ids []string) string {
+	terms := make([]string, 0, len(ids))
+	for _, id := range ids {
+		terms = append(terms, "Id eq "+id)
 	}
-	request.Header.Set("Accept", "application/json")
-	request.Header.Set("User-Agent", "partijgedrag-rewrite/0.1")
-
-	response, err := client.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(response.Body, 2048))
-		return fmt.Errorf("tweede kamer odata returned %d for %s: %s", response.StatusCode, requestURL, strings.TrimSpace(string(body)))
-	}
-
-	return json.NewDecoder(response.Body).Decode(target)
+	return strings.Join(terms, " or ")
 }
 
 func formatODataDate(value time.Time) string {
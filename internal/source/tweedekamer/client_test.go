@@ -64,6 +64,50 @@ func TestChangedMotionsURLUsesValidLeanQuery(t *testing.T) {
 	}
 }
 
+func TestChangedZakenURLFiltersOnSoortAndExpandsDossierAndActors(t *testing.T) {
+	client := NewClient("https://example.test/OData/v4/2.0")
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	parsed, err := url.Parse(client.changedZakenURL(ZaakSoortAmendement, since, 100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	if got := query.Get("$filter"); got != "Soort eq 'Amendement' and ApiGewijzigdOp ge 2024-01-02T03:04:05Z" {
+		t.Fatalf("unexpected filter %q", got)
+	}
+	wantExpand := "Kamerstukdossier($select=Nummer,Toevoeging)," +
+		"ZaakActor($select=Id,Relatie,ActorNaam,ActorFractie,Functie,Persoon_Id,Fractie_Id,Verwijderd;" +
+		"$filter=Relatie eq 'Indiener' or Relatie eq 'Medeindiener')"
+	if got := query.Get("$expand"); got != wantExpand {
+		t.Fatalf("unexpected expand %q", got)
+	}
+	if query.Has("$skip") {
+		t.Fatalf("unexpected skip %q", query.Get("$skip"))
+	}
+}
+
+func TestZakenByIDURLFiltersOnIDs(t *testing.T) {
+	client := NewClient("https://example.test/OData/v4/2.0")
+
+	parsed, err := url.Parse(client.zakenByIDURL([]string{"a1", "b2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	if got := query.Get("$filter"); got != "Id eq a1 or Id eq b2" {
+		t.Fatalf("unexpected filter %q", got)
+	}
+	if got := query.Get("$top"); got != "2" {
+		t.Fatalf("unexpected top %q", got)
+	}
+	if !strings.Contains(query.Get("$expand"), "ZaakActor(") {
+		t.Fatalf("unexpected expand %q", query.Get("$expand"))
+	}
+}
+
 func TestMotionDecisionsURLUsesNavigationEndpoint(t *testing.T) {
 	client := NewClient("https://example.test/OData/v4/2.0")
 
@@ -190,3 +234,102 @@ func TestDecisionVotesURLUsesNavigationEndpoint(t *testing.T) {
 		}
 	}
 }
+
+func TestChangedMembersURLExpandsSeats(t *testing.T) {
+	client := NewClient("https://example.test/OData/v4/2.0")
+	since := time.Date(2026, 2, 23, 12, 0, 0, 0, time.UTC)
+
+	queryURL := client.changedMembersURL(since, 50, 0)
+	parsed, err := url.Parse(queryURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Path != "/OData/v4/2.0/Persoon" {
+		t.Fatalf("unexpected path %q", parsed.Path)
+	}
+
+	query := parsed.Query()
+	if got := query.Get("$filter"); got != "ApiGewijzigdOp ge 2026-02-23T12:00:00Z" {
+		t.Fatalf("unexpected filter %q", got)
+	}
+	if query.Has("$skip") {
+		t.Fatalf("unexpected skip %q", query.Get("$skip"))
+	}
+	if got := query.Get("$expand"); !strings.HasPrefix(got, "FractieZetelPersoon(") || !strings.Contains(got, "FractieZetel($select=Fractie_Id)") {
+		t.Fatalf("unexpected expand %q", got)
+	}
+
+	selectFields := strings.Split(query.Get("$select"), ",")
+	required := map[string]bool{
+		"Id":             false,
+		"Voornamen":      false,
+		"Achternaam":     false,
+		"Fractielabel":   false,
+		"ApiGewijzigdOp": false,
+		"Verwijderd":     false,
+	}
+
+	for _, field := range selectFields {
+		if _, ok := required[field]; ok {
+			required[field] = true
+		}
+	}
+
+	for field, seen := range required {
+		if !seen {
+			t.Fatalf("query is missing required field %q", field)
+		}
+	}
+}
+
+func TestReconcileFilterScopesChildrenToZakenInTheWindow(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	soorten := []string{ZaakSoortMotie, ZaakSoortAmendement}
+
+	filter, err := ReconcileFilter("Besluit", soorten, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Verwijderd eq false and Zaak/any(z: (z/Soort eq 'Motie' or z/Soort eq 'Amendement') and z/GestartOp ge 2024-03-01T00:00:00Z and z/GestartOp lt 2024-04-01T00:00:00Z)"
+	if filter != want {
+		t.Fatalf("unexpected filter %q", filter)
+	}
+	if filter, _ := ReconcileFilter("Fractie", soorten, from, to); filter != "Verwijderd eq false" {
+		t.Fatalf("unexpected Fractie filter %q", filter)
+	}
+	if _, err := ReconcileFilter("Persoon", soorten, from, to); err == nil {
+		t.Fatal("accepted an unsupported collection")
+	}
+}
+
+func TestCountAndEntityRefsURLs(t *testing.T) {
+	client := NewClient("https://example.test/OData/v4/2.0")
+
+	count, err := url.Parse(client.countURL("Zaak", "Verwijderd eq false"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Path != "/OData/v4/2.0/Zaak" || count.Query().Get("$count") != "true" || count.Query().Get("$top") != "0" {
+		t.Fatalf("unexpected count URL %s", count)
+	}
+	if all, _ := url.Parse(client.countURL("Zaak", "")); all.Query().Has("$filter") {
+		t.Fatalf("count of a whole collection has a filter: %s", all)
+	}
+
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	changed, _ := url.Parse(client.changedZakenURL("Motie", since, 250, 0))
+	if got := changedZakenFilter("Motie", since); got != changed.Query().Get("$filter") {
+		t.Fatalf("count filter %q differs from the paged filter %q", got, changed.Query().Get("$filter"))
+	}
+
+	refs, err := url.Parse(client.entityRefsURL("Stemming", "Verwijderd eq false", 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := refs.Query()
+	if query.Get("$select") != "Id,Besluit_Id" || query.Get("$orderby") != "Id asc" || query.Get("$skip") != "500" {
+		t.Fatalf("unexpected refs query %s", refs.RawQuery)
+	}
+}
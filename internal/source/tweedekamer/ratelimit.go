@@ -0,0 +1,341 @@
+package tweedekamer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Requests to one host share a token bucket, whichever Client sends them. Each
+// pipeline has its own workers, up to 16 with --concurrency, and the server
+// throttles them as one.
+var hostBuckets = struct {
+	sync.Mutex
+	perSecond float64
+	burst     int
+	buckets   map[string]*tokenBucket
+}{perSecond: 10, burst: 10, buckets: map[string]*tokenBucket{}}
+
+// ConfigureRateLimit sets the requests per second and burst allowed per host,
+// for existing and future clients. A perSecond of zero disables the limit;
+// a Retry-After from the server is still honoured.
+func ConfigureRateLimit(perSecond float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	hostBuckets.Lock()
+	defer hostBuckets.Unlock()
+	hostBuckets.perSecond = perSecond
+	hostBuckets.burst = burst
+	for _, bucket := range hostBuckets.buckets {
+		bucket.configure(perSecond, float64(burst))
+	}
+}
+
+func hostBucket(baseURL string) *tokenBucket {
+	host := baseURL
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	hostBuckets.Lock()
+	defer hostBuckets.Unlock()
+	bucket, ok := hostBuckets.buckets[host]
+	if !ok {
+		bucket = newTokenBucket(hostBuckets.perSecond, float64(hostBuckets.burst), time.Now())
+		hostBuckets.buckets[host] = bucket
+	}
+	return bucket
+}
+
+type tokenBucket struct {
+	mu          sync.Mutex
+	perSecond   float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(perSecond float64, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{perSecond: perSecond, burst: burst, tokens: burst, last: now}
+}
+
+func (bucket *tokenBucket) configure(perSecond float64, burst float64) {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.perSecond = perSecond
+	bucket.burst = burst
+	bucket.tokens = min(bucket.tokens, burst)
+}
+
+// Wait blocks until a request may be sent.
+func (bucket *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := bucket.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token, or returns how long to wait before trying again.
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if now.Before(bucket.pausedUntil) {
+		return bucket.pausedUntil.Sub(now)
+	}
+	if bucket.perSecond <= 0 {
+		return 0
+	}
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens = min(bucket.burst, bucket.tokens+elapsed*bucket.perSecond)
+		bucket.last = now
+	}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0
+	}
+	return time.Duration((1 - bucket.tokens) / bucket.perSecond * float64(time.Second))
+}
+
+// pause holds every request to the host until the given time, so a Retry-After
+// sent to one worker slows down all of them.
+func (bucket *tokenBucket) pause(until time.Time) {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	if until.After(bucket.pausedUntil) {
+		bucket.pausedUntil = until
+	}
+}
+
+// RequestStats counts what a Client did on top of the requests it was asked
+// for.
+type RequestStats struct {
+	Retries int64
+	// Throttled counts 429 and 503 responses.
+	Throttled int64
+}
+
+// RequestCounter counts the retries and throttled responses of the requests
+// made with one context. Pipelines share a Client when they run side by side,
+// so each run carries its own counter to record on its ingestion_runs row.
+type RequestCounter struct {
+	retries   atomic.Int64
+	throttled atomic.Int64
+}
+
+func (counter *RequestCounter) Stats() RequestStats {
+	return RequestStats{
+		Retries:   counter.retries.Load(),
+		Throttled: counter.throttled.Load(),
+	}
+}
+
+type requestCounterKey struct{}
+
+// WithRequestCounter returns a context whose requests are also counted on
+// the returned counter.
+func WithRequestCounter(ctx context.Context) (context.Context, *RequestCounter) {
+	counter := &RequestCounter{}
+	return context.WithValue(ctx, requestCounterKey{}, counter), counter
+}
+
+// Stats counts every request of the client, whatever context it was made
+// with.
+func (client *Client) Stats() RequestStats {
+	return client.counters.Stats()
+}
+
+// countRetry counts on the client's counters, when there are any, and on the
+// context's.
+func countRetry(ctx context.Context, counters *RequestCounter) {
+	if counters != nil {
+		counters.retries.Add(1)
+	}
+	if counter, ok := ctx.Value(requestCounterKey{}).(*RequestCounter); ok {
+		counter.retries.Add(1)
+	}
+}
+
+func countThrottled(ctx context.Context, counters *RequestCounter) {
+	if counters != nil {
+		counters.throttled.Add(1)
+	}
+	if counter, ok := ctx.Value(requestCounterKey{}).(*RequestCounter); ok {
+		counter.throttled.Add(1)
+	}
+}
+
+// Get sends a GET the way Client sends its OData requests, for other clients
+// of the same servers such as the SyncFeed: through the token bucket of the
+// host, retrying transient failures with backoff and Retry-After, and
+// counting retries and throttled responses on the context's RequestCounter.
+// read gets the body of a 200 response; any other status is a StatusError.
+func Get(ctx context.Context, httpClient *http.Client, requestURL string, accept string, read func(io.Reader) error) error {
+	return get(ctx, httpClient, hostBucket(requestURL), nil, requestURL, accept, read)
+}
+
+func get(ctx context.Context, httpClient *http.Client, limiter *tokenBucket, counters *RequestCounter, requestURL string, accept string, read func(io.Reader) error) error {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			countRetry(ctx, counters)
+		}
+		err := getOnce(ctx, httpClient, limiter, counters, requestURL, accept, read)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !IsRetryable(err) || attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay(attempt, err)):
+		}
+	}
+	return lastErr
+}
+
+func getOnce(ctx context.Context, httpClient *http.Client, limiter *tokenBucket, counters *RequestCounter, requestURL string, accept string, read func(io.Reader) error) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", accept)
+	request.Header.Set("User-Agent", "partijgedrag-rewrite/0.1")
+
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 2048))
+		statusErr := &StatusError{
+			StatusCode: response.StatusCode,
+			URL:        requestURL,
+			Body:       strings.TrimSpace(string(body)),
+			RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+		}
+		if statusErr.Throttled() {
+			countThrottled(ctx, counters)
+			if statusErr.RetryAfter > 0 {
+				limiter.pause(time.Now().Add(min(statusErr.RetryAfter, maxRetryAfter)))
+			}
+		}
+		return statusErr
+	}
+
+	return read(response.Body)
+}
+
+// StatusError is a non-200 response.
+type StatusError struct {
+	StatusCode int
+	URL        string
+	Body       string
+	// RetryAfter is the delay the server asked for, zero if it did not.
+	RetryAfter time.Duration
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("tweede kamer returned %d for %s: %s", err.StatusCode, err.URL, err.Body)
+}
+
+// Throttled reports whether the server asked us to slow down.
+func (err *StatusError) Throttled() bool {
+	return err.StatusCode == http.StatusTooManyRequests || err.StatusCode == http.StatusServiceUnavailable
+}
+
+// IsRetryable separates transient failures (throttling, server errors,
+// timeouts, dropped connections) from permanent ones such as a 400 for a bad
+// query or a 404, which fail the same way on every attempt.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+const (
+	maxAttempts      = 5
+	retryBackoffBase = time.Second
+	retryBackoffMax  = 30 * time.Second
+	// maxRetryAfter bounds how long one Retry-After can stall a pipeline.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// retryDelay is the wait before attempt+1. It honours Retry-After and is
+// otherwise exponential with jitter, so throttled workers do not retry in
+// lockstep.
+func retryDelay(attempt int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, maxRetryAfter)
+	}
+	backoff := retryBackoffMax
+	if attempt < 6 {
+		backoff = min(retryBackoffBase<<(attempt-1), retryBackoffMax)
+	}
+	return backoff/2 + rand.N(backoff/2)
+}
+
+// parseRetryAfter accepts both forms of the header: delay seconds or an HTTP
+// date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
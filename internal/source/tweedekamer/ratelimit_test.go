@@ -0,0 +1,169 @@
+package tweedekamer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		" 3 ":                           3 * time.Second,
+		"-1":                            0,
+		"Tue, 02 Jan 2024 03:05:05 GMT": time.Minute,
+		"Tue, 02 Jan 2024 03:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestIsRetryableSeparatesTransientFromPermanent(t *testing.T) {
+	retryable := []error{
+		&StatusError{StatusCode: http.StatusTooManyRequests},
+		&StatusError{StatusCode: http.StatusServiceUnavailable},
+		&StatusError{StatusCode: http.StatusBadGateway},
+		fmt.Errorf("page 3: %w", &StatusError{StatusCode: http.StatusGatewayTimeout}),
+		io.ErrUnexpectedEOF,
+	}
+	for _, err := range retryable {
+		if !IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = false", err)
+		}
+	}
+
+	permanent := []error{
+		&StatusError{StatusCode: http.StatusBadRequest},
+		&StatusError{StatusCode: http.StatusNotFound},
+		context.Canceled,
+		errors.New("invalid character '<' looking for beginning of value"),
+	}
+	for _, err := range permanent {
+		if IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = true", err)
+		}
+	}
+}
+
+func TestRetryDelayHonoursRetryAfterAndJittersBackoff(t *testing.T) {
+	if got := retryDelay(1, &StatusError{StatusCode: 429, RetryAfter: 7 * time.Second}); got != 7*time.Second {
+		t.Fatalf("retryDelay with Retry-After = %s", got)
+	}
+	if got := retryDelay(1, &StatusError{StatusCode: 429, RetryAfter: time.Hour}); got != maxRetryAfter {
+		t.Fatalf("retryDelay with an hour Retry-After = %s, want %s", got, maxRetryAfter)
+	}
+
+	for attempt, upper := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: retryBackoffMax} {
+		for range 50 {
+			got := retryDelay(attempt, io.ErrUnexpectedEOF)
+			if got < upper/2 || got >= upper {
+				t.Fatalf("retryDelay(%d) = %s, want in [%s, %s)", attempt, got, upper/2, upper)
+			}
+		}
+	}
+}
+
+func TestTokenBucketRefillsAtRate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, 2, start)
+
+	for range 2 {
+		if delay := bucket.reserve(start); delay != 0 {
+			t.Fatalf("burst request waited %s", delay)
+		}
+	}
+	if delay := bucket.reserve(start); delay != 500*time.Millisecond {
+		t.Fatalf("third request waits %s, want 500ms", delay)
+	}
+	if delay := bucket.reserve(start.Add(500 * time.Millisecond)); delay != 0 {
+		t.Fatalf("request after refill waited %s", delay)
+	}
+
+	bucket.pause(start.Add(10 * time.Second))
+	if delay := bucket.reserve(start.Add(4 * time.Second)); delay != 6*time.Second {
+		t.Fatalf("paused request waits %s, want 6s", delay)
+	}
+}
+
+func TestClientsShareTheBucketOfTheirHost(t *testing.T) {
+	first := NewClient("https://shared.example.test/OData/v4/2.0")
+	second := NewClient("https://shared.example.test/OData/v4/2.0/")
+	other := NewClient("https://other.example.test/OData/v4/2.0")
+	if first.limiter != second.limiter {
+		t.Fatal("clients of one host got separate buckets")
+	}
+	if first.limiter == other.limiter {
+		t.Fatal("clients of different hosts share a bucket")
+	}
+}
+
+func TestFetchJSONDoesNotRetryPermanentErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requests++
+		http.Error(response, "bad filter", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	var target map[string]any
+	err := client.fetchJSON(context.Background(), server.URL+"/Zaak", &target)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v, want a 400 StatusError", err)
+	}
+	if requests != 1 {
+		t.Fatalf("server saw %d requests, want 1", requests)
+	}
+	if stats := client.Stats(); stats != (RequestStats{}) {
+		t.Fatalf("stats = %+v, want none", stats)
+	}
+}
+
+func TestRequestCounterOnlyCountsItsOwnContext(t *testing.T) {
+	throttled := true
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/Zaak" && throttled {
+			throttled = false
+			response.Header().Set("Retry-After", "0")
+			http.Error(response, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		response.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	zaakCtx, zaakCounter := WithRequestCounter(context.Background())
+	otherCtx, otherCounter := WithRequestCounter(context.Background())
+	var target map[string]any
+	if err := client.fetchJSON(zaakCtx, server.URL+"/Zaak", &target); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.fetchJSON(otherCtx, server.URL+"/Besluit", &target); err != nil {
+		t.Fatal(err)
+	}
+
+	want := RequestStats{Retries: 1, Throttled: 1}
+	if stats := zaakCounter.Stats(); stats != want {
+		t.Fatalf("zaak stats = %+v, want %+v", stats, want)
+	}
+	if stats := otherCounter.Stats(); stats != (RequestStats{}) {
+		t.Fatalf("other stats = %+v, want none", stats)
+	}
+	if stats := client.Stats(); stats != want {
+		t.Fatalf("client stats = %+v, want %+v", stats, want)
+	}
+}
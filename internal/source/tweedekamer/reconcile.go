@@ -0,0 +1,162 @@
+package tweedekamer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// refsPageSize is the page size of FetchEntityRefs. The API caps $top at 250.
+const refsPageSize = 250
+
+// EntityRef identifies a record and the parents that route it to a motion.
+type EntityRef struct {
+	ID string
+	// ParentIDs are the zaak ids of a Besluit and the besluit id of a
+	// Stemming; they are empty for other collections.
+	ParentIDs []string
+}
+
+// ReconcileFilter selects the live records of collection that belong to the
+// zaken of the given soorten started in [from, to). Fractie is not tied to a
+// zaak and ignores the window.
+func ReconcileFilter(collection string, soorten []string, from time.Time, to time.Time) (string, error) {
+	zaak := func(prefix string) string {
+		terms := make([]string, 0, len(soorten))
+		for _, soort := range soorten {
+			terms = append(terms, fmt.Sprintf("%sSoort eq '%s'", prefix, strings.ReplaceAll(soort, "'", "''")))
+		}
+		return fmt.Sprintf(
+			"(%s) and %sGestartOp ge %s and %sGestartOp lt %s",
+			strings.Join(terms, " or "),
+			prefix, formatODataDate(from),
+			prefix, formatODataDate(to),
+		)
+	}
+
+	switch collection {
+	case "Fractie":
+		return "Verwijderd eq false", nil
+	case "Zaak":
+		return "Verwijderd eq false and " + zaak(""), nil
+	case "Besluit":
+		return "Verwijderd eq false and Zaak/any(z: " + zaak("z/") + ")", nil
+	case "Stemming":
+		return "Verwijderd eq false and Besluit/Verwijderd eq false and Besluit/Zaak/any(z: " + zaak("z/") + ")", nil
+	default:
+		return "", fmt.Errorf("unsupported reconcile collection %q", collection)
+	}
+}
+
+// CountEntities returns the $count of collection under filter without
+// fetching any record. An empty filter counts the whole collection, deleted
+// records included.
+func (client *Client) CountEntities(ctx context.Context, collection string, filter string) (int, error) {
+	var body struct {
+		Count *int `json:"@odata.count"`
+	}
+	if err := client.fetchJSON(ctx, client.countURL(collection, filter), &body); err != nil {
+		return 0, err
+	}
+	if body.Count == nil {
+		return 0, fmt.Errorf("%s count response has no @odata.count", collection)
+	}
+	return *body.Count, nil
+}
+
+// FetchEntityRefs lists the ids of every record of collection under filter,
+// following the pages to the end.
+func (client *Client) FetchEntityRefs(ctx context.Context, collection string, filter string) ([]EntityRef, error) {
+	refs := []EntityRef{}
+	requestURL := client.entityRefsURL(collection, filter, 0)
+	for requestURL != "" {
+		var body struct {
+			Value []struct {
+				ID        string  `json:"Id"`
+				BesluitID *string `json:"Besluit_Id"`
+				Zaak      []struct {
+					ID string `json:"Id"`
+				} `json:"Zaak"`
+			} `json:"value"`
+			NextURL string `json:"@odata.nextLink"`
+		}
+		if err := client.fetchJSON(ctx, requestURL, &body); err != nil {
+			return nil, err
+		}
+
+		for _, record := range body.Value {
+			ref := EntityRef{ID: record.ID}
+			if record.BesluitID != nil {
+				ref.ParentIDs = append(ref.ParentIDs, *record.BesluitID)
+			}
+			for _, zaak := range record.Zaak {
+				ref.ParentIDs = append(ref.ParentIDs, zaak.ID)
+			}
+			refs = append(refs, ref)
+		}
+
+		switch {
+		case body.NextURL != "":
+			requestURL = body.NextURL
+		case len(body.Value) == refsPageSize:
+			requestURL = client.entityRefsURL(collection, filter, len(refs))
+		default:
+			requestURL = ""
+		}
+	}
+	return refs, nil
+}
+
+// FetchLiveIDs returns the ids that still exist upstream and are not marked
+// Verwijderd.
+func (client *Client) FetchLiveIDs(ctx context.Context, collection string, ids []string) ([]string, error) {
+	live := []string{}
+	for start := 0; start < len(ids); start += MaxIDsPerRequest {
+		end := min(start+MaxIDsPerRequest, len(ids))
+		refs, err := client.FetchEntityRefs(ctx, collection, "Verwijderd eq false and ("+idFilter(ids[start:end])+")")
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			live = append(live, ref.ID)
+		}
+	}
+	return live, nil
+}
+
+func (client *Client) countURL(collection string, filter string) string {
+	u, _ := url.Parse(client.baseURL + "/" + collection)
+	query := u.Query()
+	if filter != "" {
+		query.Set("$filter", filter)
+	}
+	query.Set("$top", "0")
+	query.Set("$count", "true")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (client *Client) entityRefsURL(collection string, filter string, skip int) string {
+	u, _ := url.Parse(client.baseURL + "/" + collection)
+	query := u.Query()
+	query.Set("$filter", filter)
+	switch collection {
+	case "Besluit":
+		query.Set("$select", "Id")
+		query.Set("$expand", "Zaak($select=Id)")
+	case "Stemming":
+		query.Set("$select", "Id,Besluit_Id")
+	default:
+		query.Set("$select", "Id")
+	}
+	query.Set("$orderby", "Id asc")
+	query.Set("$top", fmt.Sprintf("%d", refsPageSize))
+	if skip > 0 {
+		query.Set("$skip", fmt.Sprintf("%d", skip))
+	}
+	query.Set("$count", "false")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
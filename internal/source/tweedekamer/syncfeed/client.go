@@ -0,0 +1,112 @@
+// Package syncfeed reads the Tweede Kamer SyncFeed: an Atom feed per entity
+// category that lists every change in the order it was made. Unlike polling
+// OData on ApiGewijzigdOp, the feed reports changes to Besluiten and
+// Stemmingen without fetching them per motion, and it reports deletions.
+package syncfeed
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"partijgedrag/internal/source/tweedekamer"
+)
+
+// Categories the ingest follows. The feed names them after the OData entity.
+const (
+	CategoryZaak     = "Zaak"
+	CategoryFractie  = "Fractie"
+	CategoryBesluit  = "Besluit"
+	CategoryStemming = "Stemming"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return NewClientWithTransport(baseURL, nil)
+}
+
+// NewClientWithTransport sends requests through transport. A nil transport
+// uses http.DefaultTransport.
+func NewClientWithTransport(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   60 * time.Second,
+		},
+	}
+}
+
+// Entry is one changed entity. The feed carries the entity's own fields, but
+// only Fields and Refs are kept: the ingest refetches full records from OData.
+type Entry struct {
+	ID       string
+	Category string
+	Updated  *time.Time
+	Deleted  bool
+	// Fields holds the text of the entity's simple child elements, keyed by
+	// element name, e.g. "soort" for a Zaak.
+	Fields map[string]string
+	// Refs holds the ids of related entities, keyed by element name, e.g.
+	// "zaak" for a Besluit or "besluit" for a Stemming.
+	Refs map[string][]string
+}
+
+type Page struct {
+	Entries []Entry
+	// NextURL is empty on the last page.
+	NextURL string
+	// SkipToken resumes the feed after this page. It is the skiptoken of
+	// NextURL, or zero on the last page.
+	SkipToken int64
+}
+
+// FetchPage reads one page of a category, starting after skipToken. A zero
+// skipToken starts at the beginning of the feed. The feed is served by the
+// same host as OData, so requests share its rate limit and retry policy; see
+// tweedekamer.Get.
+func (client *Client) FetchPage(ctx context.Context, category string, skipToken int64) (Page, error) {
+	var body []byte
+	err := tweedekamer.Get(ctx, client.httpClient, client.feedURL(category, skipToken), "application/atom+xml", func(reader io.Reader) error {
+		var err error
+		body, err = io.ReadAll(reader)
+		return err
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	return ParseFeed(body)
+}
+
+func (client *Client) feedURL(category string, skipToken int64) string {
+	u, _ := url.Parse(client.baseURL + "/Feed")
+	query := u.Query()
+	query.Set("category", category)
+	if skipToken > 0 {
+		query.Set("skiptoken", strconv.FormatInt(skipToken, 10))
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// SkipToken extracts the skiptoken query parameter of a feed link.
+func SkipToken(link string) (int64, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return 0, err
+	}
+	value := u.Query().Get("skiptoken")
+	if value == "" {
+		return 0, errors.New("syncfeed: link has no skiptoken")
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,65 @@
+package syncfeed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"partijgedrag/internal/source/tweedekamer"
+)
+
+func TestFetchPageHonoursRetryAfter(t *testing.T) {
+	feed, err := os.ReadFile("testdata/feed_besluit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requests++
+		if requests == 1 {
+			response.Header().Set("Retry-After", "1")
+			http.Error(response, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		response.Write(feed)
+	}))
+	defer server.Close()
+
+	ctx, counter := tweedekamer.WithRequestCounter(context.Background())
+	page, err := NewClient(server.URL).FetchPage(ctx, CategoryBesluit, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || len(page.Entries) == 0 {
+		t.Fatalf("requests = %d, entries = %d, want a retried page", requests, len(page.Entries))
+	}
+	if stats := counter.Stats(); stats != (tweedekamer.RequestStats{Retries: 1, Throttled: 1}) {
+		t.Fatalf("stats = %+v, want one retry after one throttle", stats)
+	}
+}
+
+func TestFetchPageDoesNotRetryNotFound(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requests++
+		http.NotFound(response, request)
+	}))
+	defer server.Close()
+
+	ctx, counter := tweedekamer.WithRequestCounter(context.Background())
+	_, err := NewClient(server.URL).FetchPage(ctx, CategoryZaak, 0)
+
+	var statusErr *tweedekamer.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want a 404 StatusError", err)
+	}
+	if requests != 1 {
+		t.Fatalf("server saw %d requests, want 1", requests)
+	}
+	if stats := counter.Stats(); stats != (tweedekamer.RequestStats{}) {
+		t.Fatalf("stats = %+v, want none", stats)
+	}
+}
@@ -0,0 +1,131 @@
+package syncfeed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type atomFeed struct {
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title    string `xml:"title"`
+	Updated  string `xml:"updated"`
+	Category struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Content struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"content"`
+}
+
+// ParseFeed parses one page of the Atom feed.
+func ParseFeed(data []byte) (Page, error) {
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return Page{}, fmt.Errorf("parsing syncfeed: %w", err)
+	}
+
+	page := Page{Entries: make([]Entry, 0, len(feed.Entries))}
+	for _, link := range feed.Links {
+		if link.Rel != "next" {
+			continue
+		}
+		skipToken, err := SkipToken(link.Href)
+		if err != nil {
+			return Page{}, fmt.Errorf("parsing syncfeed next link %q: %w", link.Href, err)
+		}
+		page.NextURL = link.Href
+		page.SkipToken = skipToken
+	}
+
+	for _, raw := range feed.Entries {
+		entry, err := parseEntry(raw)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page, nil
+}
+
+func parseEntry(raw atomEntry) (Entry, error) {
+	entry := Entry{
+		ID:       strings.TrimSpace(raw.Title),
+		Category: raw.Category.Term,
+		Fields:   map[string]string{},
+		Refs:     map[string][]string{},
+	}
+	if updated, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(raw.Updated)); err == nil {
+		entry.Updated = &updated
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw.Content.Inner))
+	depth := 0
+	field := ""
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Entry{}, fmt.Errorf("parsing syncfeed entry %s: %w", entry.ID, err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				// The entity itself, e.g. <zaak id="..." tk:verwijderd="false">.
+				for _, attr := range token.Attr {
+					switch attr.Name.Local {
+					case "id":
+						entry.ID = attr.Value
+					case "verwijderd":
+						entry.Deleted = attr.Value == "true"
+					}
+				}
+			case 2:
+				field = token.Name.Local
+				text.Reset()
+				for _, attr := range token.Attr {
+					if attr.Name.Local == "ref" && attr.Value != "" {
+						entry.Refs[field] = append(entry.Refs[field], attr.Value)
+						field = ""
+					}
+				}
+			default:
+				// Nested structures are not needed to route a change.
+				field = ""
+			}
+		case xml.CharData:
+			if depth == 2 && field != "" {
+				text.Write(token)
+			}
+		case xml.EndElement:
+			if depth == 2 && field != "" {
+				entry.Fields[field] = strings.TrimSpace(text.String())
+				field = ""
+			}
+			depth--
+		}
+	}
+
+	if entry.ID == "" {
+		return Entry{}, fmt.Errorf("parsing syncfeed entry: missing id")
+	}
+	return entry, nil
+}
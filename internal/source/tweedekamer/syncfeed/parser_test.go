@@ -0,0 +1,86 @@
+package syncfeed
+
+import (
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestParseFeed(t *testing.T) {
+	data, err := os.ReadFile("testdata/feed_besluit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := ParseFeed(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.SkipToken != 18250250 {
+		t.Fatalf("SkipToken = %d, want 18250250", page.SkipToken)
+	}
+	if page.NextURL == "" {
+		t.Fatal("NextURL is empty")
+	}
+	if len(page.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(page.Entries))
+	}
+
+	changed := page.Entries[0]
+	if changed.ID != "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f" || changed.Category != CategoryBesluit || changed.Deleted {
+		t.Fatalf("unexpected entry %+v", changed)
+	}
+	if got := changed.Fields["besluitSoort"]; got != "Stemmen - aangenomen" {
+		t.Fatalf("besluitSoort = %q", got)
+	}
+	if refs := changed.Refs["zaak"]; len(refs) != 1 || refs[0] != "2965764e-cc8e-45f1-8f55-00003c0ab123" {
+		t.Fatalf("zaak refs = %v", refs)
+	}
+	if _, ok := changed.Fields["zaak"]; ok {
+		t.Fatal("ref element recorded as a field")
+	}
+	if changed.Updated == nil || changed.Updated.Format("2006-01-02T15:04:05Z07:00") != "2026-04-29T14:23:18Z" {
+		t.Fatalf("Updated = %v", changed.Updated)
+	}
+
+	deleted := page.Entries[1]
+	if !deleted.Deleted || deleted.ID != "d2e3f4a5-b6c7-4d8e-9f0a-1b2c3d4e5f60" {
+		t.Fatalf("unexpected deleted entry %+v", deleted)
+	}
+}
+
+func TestParseFeedLastPageHasNoSkipToken(t *testing.T) {
+	page, err := ParseFeed([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><link rel="self" href="https://example.test/Feed?category=Zaak&amp;skiptoken=5" /></feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextURL != "" || page.SkipToken != 0 || len(page.Entries) != 0 {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+}
+
+func TestFeedURLCarriesCategoryAndSkipToken(t *testing.T) {
+	client := NewClient("https://example.test/SyncFeed/2.0/")
+
+	parsed, err := url.Parse(client.feedURL(CategoryStemming, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Path != "/SyncFeed/2.0/Feed" {
+		t.Fatalf("unexpected path %q", parsed.Path)
+	}
+	if got := parsed.Query().Get("category"); got != CategoryStemming {
+		t.Fatalf("category = %q", got)
+	}
+	if got := parsed.Query().Get("skiptoken"); got != "42" {
+		t.Fatalf("skiptoken = %q", got)
+	}
+
+	parsed, err = url.Parse(client.feedURL(CategoryZaak, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Query().Has("skiptoken") {
+		t.Fatal("unexpected skiptoken on the first page")
+	}
+}
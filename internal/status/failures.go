@@ -0,0 +1,80 @@
+package status
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IngestionFailure struct {
+	SourceKey     string
+	Pipeline      string
+	ItemKey       string
+	Attempts      int
+	LastError     string
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+	NextRetryAt   time.Time
+}
+
+// LoadIngestionFailures lists recorded per-motion failures, the ones retried
+// soonest first. An empty pipeline lists every pipeline.
+func LoadIngestionFailures(ctx context.Context, pool *pgxpool.Pool, pipeline string, limit int) ([]IngestionFailure, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT source_key,
+		       pipeline,
+		       item_key,
+		       attempts,
+		       last_error,
+		       first_failed_at,
+		       last_failed_at,
+		       next_retry_at
+		FROM ingestion_failures
+		WHERE ($1::text = '' OR pipeline = $1)
+		ORDER BY next_retry_at ASC, item_key ASC
+		LIMIT $2
+	`, pipeline, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := []IngestionFailure{}
+	for rows.Next() {
+		var failure IngestionFailure
+		if err := rows.Scan(&failure.SourceKey, &failure.Pipeline, &failure.ItemKey, &failure.Attempts, &failure.LastError, &failure.FirstFailedAt, &failure.LastFailedAt, &failure.NextRetryAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, failure)
+	}
+	return failures, rows.Err()
+}
+
+// CountIngestionFailures counts recorded failures per pipeline.
+func CountIngestionFailures(ctx context.Context, pool *pgxpool.Pool) (map[string]int, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT pipeline, count(*)::int
+		FROM ingestion_failures
+		GROUP BY pipeline
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var pipeline string
+		var count int
+		if err := rows.Scan(&pipeline, &count); err != nil {
+			return nil, err
+		}
+		counts[pipeline] = count
+	}
+	return counts, rows.Err()
+}
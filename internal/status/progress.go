@@ -0,0 +1,91 @@
+package status
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunProgress is what a running pipeline last reported about itself.
+type RunProgress struct {
+	RunID     int64
+	SourceKey string
+	Pipeline  string
+	StartedAt time.Time
+	// UpdatedAt is nil until the run first reports progress.
+	UpdatedAt *time.Time
+	Pages     int
+	Items     int
+	// Total is zero when the pipeline does not know its total up front.
+	Total int
+}
+
+// Rate is the throughput in items per second up to the last report.
+func (progress RunProgress) Rate() float64 {
+	if progress.UpdatedAt == nil {
+		return 0
+	}
+	elapsed := progress.UpdatedAt.Sub(progress.StartedAt).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(progress.Items) / elapsed
+}
+
+// Fraction is the share of Total done, 0 when the total is unknown.
+func (progress RunProgress) Fraction() float64 {
+	if progress.Total <= 0 {
+		return 0
+	}
+	return min(1, float64(progress.Items)/float64(progress.Total))
+}
+
+// ETA estimates when the run finishes at its current rate. It is zero when
+// there is no total or no rate yet.
+func (progress RunProgress) ETA() time.Time {
+	rate := progress.Rate()
+	if progress.Total <= 0 || rate <= 0 {
+		return time.Time{}
+	}
+	remaining := max(0, progress.Total-progress.Items)
+	return progress.UpdatedAt.Add(time.Duration(float64(remaining) / rate * float64(time.Second)))
+}
+
+// Bar draws Fraction as a text progress bar of width cells.
+func (progress RunProgress) Bar(width int) string {
+	done := int(progress.Fraction() * float64(width))
+	return "[" + strings.Repeat("#", done) + strings.Repeat(".", width-done) + "]"
+}
+
+// LoadRunProgress lists the running runs, the oldest first.
+func LoadRunProgress(ctx context.Context, pool *pgxpool.Pool) ([]RunProgress, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT id,
+		       source_key,
+		       pipeline,
+		       started_at,
+		       progress_updated_at,
+		       progress_pages,
+		       progress_items,
+		       COALESCE(progress_total, 0)
+		FROM ingestion_runs
+		WHERE status = 'running'
+		ORDER BY started_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []RunProgress{}
+	for rows.Next() {
+		var run RunProgress
+		if err := rows.Scan(&run.RunID, &run.SourceKey, &run.Pipeline, &run.StartedAt, &run.UpdatedAt, &run.Pages, &run.Items, &run.Total); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,40 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunProgressEstimatesFromRate(t *testing.T) {
+	startedAt := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	updatedAt := startedAt.Add(100 * time.Second)
+	progress := RunProgress{StartedAt: startedAt, UpdatedAt: &updatedAt, Items: 200, Total: 500}
+
+	if rate := progress.Rate(); rate != 2 {
+		t.Fatalf("Rate() = %v, want 2", rate)
+	}
+	if fraction := progress.Fraction(); fraction != 0.4 {
+		t.Fatalf("Fraction() = %v, want 0.4", fraction)
+	}
+	if eta := progress.ETA(); !eta.Equal(updatedAt.Add(150 * time.Second)) {
+		t.Fatalf("ETA() = %s, want 150s after the last report", eta)
+	}
+	if bar := progress.Bar(10); bar != "[####......]" {
+		t.Fatalf("Bar(10) = %q", bar)
+	}
+}
+
+func TestRunProgressWithoutTotalHasNoETA(t *testing.T) {
+	startedAt := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	updatedAt := startedAt.Add(time.Minute)
+
+	paged := RunProgress{StartedAt: startedAt, UpdatedAt: &updatedAt, Pages: 3, Items: 300}
+	if !paged.ETA().IsZero() || paged.Fraction() != 0 {
+		t.Fatalf("paged run without a total got ETA %s, fraction %v", paged.ETA(), paged.Fraction())
+	}
+
+	unreported := RunProgress{StartedAt: startedAt, Total: 100}
+	if unreported.Rate() != 0 || !unreported.ETA().IsZero() {
+		t.Fatalf("run without a report got rate %v, ETA %s", unreported.Rate(), unreported.ETA())
+	}
+}
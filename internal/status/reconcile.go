@@ -0,0 +1,100 @@
+package status
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReconcileResult is the last reconcile outcome of one collection and window.
+type ReconcileResult struct {
+	SourceKey           string
+	Collection          string
+	Window              string
+	RemoteCount         int
+	LocalCount          int
+	Compared            bool
+	MissingLocal        int
+	MissingRemote       int
+	MissingLocalSample  []string
+	MissingRemoteSample []string
+	Refetched           int
+	Tombstoned          int
+	CheckedAt           time.Time
+}
+
+// Applied reports whether the run that found the differences also wrote
+// repairs. Besluiten and Stemmingen are repaired through the motion-votes
+// pipeline, so only the next reconcile run confirms the window is clean.
+func (result ReconcileResult) Applied() bool {
+	return result.Refetched+result.Tombstoned > 0
+}
+
+// ReconcileReport summarizes the stored reconcile results.
+type ReconcileReport struct {
+	// CheckedAt is nil when reconcile never ran.
+	CheckedAt *time.Time
+	Windows   int
+	// Differences are the windows with missing records, the latest month
+	// first.
+	Differences []ReconcileResult
+}
+
+func LoadReconcileReport(ctx context.Context, pool *pgxpool.Pool) (ReconcileReport, error) {
+	var report ReconcileReport
+	if err := pool.QueryRow(ctx, `
+		SELECT max(checked_at), count(*)
+		FROM reconcile_results
+	`).Scan(&report.CheckedAt, &report.Windows); err != nil {
+		return ReconcileReport{}, err
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT source_key,
+		       collection,
+		       window_label,
+		       remote_count,
+		       local_count,
+		       compared,
+		       missing_local,
+		       missing_remote,
+		       missing_local_sample,
+		       missing_remote_sample,
+		       refetched,
+		       tombstoned,
+		       checked_at
+		FROM reconcile_results
+		WHERE missing_local > 0
+		   OR missing_remote > 0
+		ORDER BY window_start DESC NULLS FIRST, collection ASC
+	`)
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	defer rows.Close()
+
+	report.Differences = []ReconcileResult{}
+	for rows.Next() {
+		var result ReconcileResult
+		if err := rows.Scan(
+			&result.SourceKey,
+			&result.Collection,
+			&result.Window,
+			&result.RemoteCount,
+			&result.LocalCount,
+			&result.Compared,
+			&result.MissingLocal,
+			&result.MissingRemote,
+			&result.MissingLocalSample,
+			&result.MissingRemoteSample,
+			&result.Refetched,
+			&result.Tombstoned,
+			&result.CheckedAt,
+		); err != nil {
+			return ReconcileReport{}, err
+		}
+		report.Differences = append(report.Differences, result)
+	}
+	return report, rows.Err()
+}
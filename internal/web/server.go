@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"io/fs"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -21,7 +22,9 @@ import (
 	"partijgedrag/internal/analysis"
 	"partijgedrag/internal/cache"
 	"partijgedrag/internal/categorize"
+	"partijgedrag/internal/inspect"
 	"partijgedrag/internal/politics"
+	"partijgedrag/internal/quality"
 	"partijgedrag/internal/status"
 )
 
@@ -57,7 +60,7 @@ func New(pool *pgxpool.Pool, dev bool) (Server, error) {
 	}
 
 	templates := make(map[string]*template.Template)
-	for _, name := range []string{"home", "about", "motions", "motion", "party_likeness", "party_comparison", "party_focus", "coalition_analysis", "coalition_motions", "voting_compass", "voting_compass_settings", "compass_results", "data_quality"} {
+	for _, name := range []string{"home", "about", "motions", "motion", "motion_history", "party_likeness", "party_comparison", "party_focus", "party_map", "coalition_analysis", "coalition_motions", "rebellions", "bills", "bill", "voting_compass", "voting_compass_settings", "compass_results", "data_quality"} {
 		parsed, err := parseTemplate(source, name, dev)
 		if err != nil {
 			return Server{}, err
@@ -97,6 +100,10 @@ func (server Server) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /party-focus", c.Middleware(cache.PolicyDynamic, server.partyFocus))
 	mux.HandleFunc("GET /coalition-analysis", c.Middleware(cache.PolicyDynamic, server.coalitionAnalysis))
 	mux.HandleFunc("GET /coalition-analysis/motions", c.Middleware(cache.PolicyDynamic, server.coalitionMotions))
+	mux.HandleFunc("GET /party-map", c.Middleware(cache.PolicyDynamic, server.partyMap))
+	mux.HandleFunc("GET /rebellions", c.Middleware(cache.PolicyDynamic, server.rebellions))
+	mux.HandleFunc("GET /wetsvoorstellen", c.Middleware(cache.PolicyDynamic, server.bills))
+	mux.HandleFunc("GET /wetsvoorstellen/{billKey}", c.Middleware(cache.PolicyDynamic, server.bill))
 	mux.HandleFunc("GET /voting-compass", c.Middleware(cache.PolicyDynamic, server.votingCompass))
 	mux.HandleFunc("GET /voting-compass/settings", c.Middleware(cache.PolicyDynamic, server.votingCompassSettings))
 	mux.HandleFunc("GET /compass/results/{sessionKey}", c.Middleware(cache.PolicyImmutable, server.compassResults))
@@ -107,6 +114,7 @@ func (server Server) Register(mux *http.ServeMux) {
 	}
 	mux.HandleFunc("GET /motions", c.Middleware(cache.PolicyDynamic, server.motions))
 	mux.HandleFunc("GET /motions/{motionKey}", c.Middleware(cache.PolicyDynamic, server.motion))
+	mux.HandleFunc("GET /motions/{motionKey}/history", c.Middleware(cache.PolicyDynamic, server.motionHistory))
 }
 
 func parseTemplate(source fs.FS, name string, dev bool) (*template.Template, error) {
@@ -190,6 +198,11 @@ func (server Server) motions(response http.ResponseWriter, request *http.Request
 	search := strings.TrimSpace(query.Get("search"))
 	withVotes := query.Get("withVotes") == "true"
 	category := query.Get("category")
+	kind, ok := analysis.NormalizeMotionKind(query.Get("kind"))
+	if !ok {
+		http.Error(response, "invalid kind", http.StatusBadRequest)
+		return
+	}
 
 	categories, err := categorize.LoadCategories(request.Context(), server.Pool, "nl-tweede-kamer")
 	if err != nil {
@@ -202,6 +215,7 @@ func (server Server) motions(response http.ResponseWriter, request *http.Request
 		Search:       search,
 		WithVotes:    withVotes,
 		Category:     category,
+		Kind:         kind,
 		Limit:        limit,
 		Offset:       offset,
 	})
@@ -218,13 +232,14 @@ func (server Server) motions(response http.ResponseWriter, request *http.Request
 		Search:     search,
 		WithVotes:  withVotes,
 		Category:   category,
+		Kind:       kind,
 		Categories: categories,
 	}
 	if offset > 0 {
-		page.PrevURL = motionsURL(search, withVotes, category, limit, max(offset-limit, 0))
+		page.PrevURL = motionsURL(search, withVotes, category, kind, limit, max(offset-limit, 0))
 	}
 	if offset+limit < total {
-		page.NextURL = motionsURL(search, withVotes, category, limit, offset+limit)
+		page.NextURL = motionsURL(search, withVotes, category, kind, limit, offset+limit)
 	}
 
 	server.render(response, "motions", page)
@@ -257,15 +272,65 @@ func (server Server) motion(response http.ResponseWriter, request *http.Request)
 		writeError(response, err)
 		return
 	}
+	memberVotes, err := loadMemberVotes(request.Context(), server.Pool, motionKey)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	submitters, err := loadMotionSubmitters(request.Context(), server.Pool, motionKey)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	findings, err := quality.LoadFindings(request.Context(), server.Pool, quality.FindingFilter{MotionKey: motionKey})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
 
 	server.render(response, "motion", motionPage{
-		Motion:     motion,
-		Decisions:  decisions,
-		Positions:  positions,
-		Categories: categories,
+		Motion:      motion,
+		Decisions:   decisions,
+		Positions:   positions,
+		Categories:  categories,
+		MemberVotes: memberVotes,
+		Submitters:  submitters,
+		Findings:    findings,
 	})
 }
 
+// motionHistory lists how the source records of a motion changed over time,
+// e.g. a Stemming corrected after a vergissing. Records that never changed are
+// only counted.
+func (server Server) motionHistory(response http.ResponseWriter, request *http.Request) {
+	motionKey := request.PathValue("motionKey")
+
+	motion, err := loadMotion(request.Context(), server.Pool, motionKey)
+	if err == pgx.ErrNoRows {
+		http.NotFound(response, request)
+		return
+	}
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	histories, err := inspect.LoadMotionHistory(request.Context(), server.Pool, motionKey)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	page := motionHistoryPage{Motion: motion, Records: []inspect.RecordHistory{}}
+	for _, history := range histories {
+		if history.Changed() {
+			page.Records = append(page.Records, history)
+		} else {
+			page.Unchanged++
+		}
+	}
+	server.render(response, "motion_history", page)
+}
+
 // partyLogo serves a party logo straight from the parties table. Logos change
 // about as often as a party is founded, so they are cached hard; ServeContent
 // handles conditional requests off the sync timestamp.
@@ -374,6 +439,11 @@ func (server Server) partyComparison(response http.ResponseWriter, request *http
 		http.Error(response, "invalid relation", http.StatusBadRequest)
 		return
 	}
+	interval, ok := analysis.NormalizeLikenessInterval(query.Get("interval"))
+	if !ok {
+		http.Error(response, "invalid interval", http.StatusBadRequest)
+		return
+	}
 
 	periods, err := analysis.LoadCabinetPeriods(request.Context(), server.Pool, "nl-tweede-kamer")
 	if err != nil {
@@ -422,6 +492,19 @@ func (server Server) partyComparison(response http.ResponseWriter, request *http
 		writeError(response, err)
 		return
 	}
+	series, err := analysis.LoadPartyLikenessSeries(request.Context(), server.Pool, analysis.PartyLikenessSeriesOptions{
+		Jurisdiction:   "nl-tweede-kamer",
+		Party1SourceID: party1SourceID,
+		Party2SourceID: party2SourceID,
+		DateFrom:       &period.StartedOn,
+		DateTo:         period.EndedOn,
+		MinCommon:      1,
+		Interval:       interval,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
 	motions, total, err := analysis.LoadComparisonMotions(request.Context(), server.Pool, analysis.ComparisonMotionOptions{
 		PartyComparisonOptions: options,
 		Relation:               relation,
@@ -435,7 +518,20 @@ func (server Server) partyComparison(response http.ResponseWriter, request *http
 	}
 
 	link := func(relation string, category string, offset int) string {
-		return partyComparisonURL(period.PeriodKey, minCommon, party1SourceID, party2SourceID, relation, category, limit, offset)
+		return withLikenessInterval(partyComparisonURL(period.PeriodKey, minCommon, party1SourceID, party2SourceID, relation, category, limit, offset), interval)
+	}
+	intervalViews := []likenessIntervalView{}
+	for _, option := range []struct{ key, label string }{{"month", "Per maand"}, {"quarter", "Per kwartaal"}, {"year", "Per vergaderjaar"}} {
+		intervalViews = append(intervalViews, likenessIntervalView{
+			Key:      option.key,
+			Label:    option.label,
+			URL:      withLikenessInterval(partyComparisonURL(period.PeriodKey, minCommon, party1SourceID, party2SourceID, relation, category, limit, offset), option.key),
+			Selected: option.key == interval,
+		})
+	}
+	var chart likenessChart
+	if len(series.Series) > 0 {
+		chart = likenessChartFor(series.Series[0])
 	}
 
 	page := partyComparisonPage{
@@ -455,7 +551,10 @@ func (server Server) partyComparison(response http.ResponseWriter, request *http
 		Offset:     offset,
 		BackURL:    partyLikenessURL(period.PeriodKey, minCommon),
 		ClearURL:   link(relation, "", 0),
-		SwapURL:    partyComparisonURL(period.PeriodKey, minCommon, party2SourceID, party1SourceID, relation, category, limit, 0),
+		SwapURL:    withLikenessInterval(partyComparisonURL(period.PeriodKey, minCommon, party2SourceID, party1SourceID, relation, category, limit, 0), interval),
+		Interval:   interval,
+		Intervals:  intervalViews,
+		Chart:      chart,
 	}
 	if category != "" {
 		page.CategoryName = comparisonCategoryName(comparison.Categories, category)
@@ -518,6 +617,35 @@ func (server Server) partyFocus(response http.ResponseWriter, request *http.Requ
 		}
 		page.Focus = &focus
 		page.Likeness = partyFocusLikenessViews(period.PeriodKey, minCommon, page.Party, focus.Likeness)
+
+		cohesion, err := analysis.LoadPartyCohesion(request.Context(), server.Pool, analysis.PartyCohesionOptions{
+			Jurisdiction:  "nl-tweede-kamer",
+			PartySourceID: page.Party,
+			DateFrom:      &period.StartedOn,
+			DateTo:        period.EndedOn,
+		})
+		if err != nil {
+			writeError(response, err)
+			return
+		}
+		page.Cohesion = &cohesion
+
+		// Loaded for every party so the coalition and opposition rates give the
+		// party's own numbers something to be compared with.
+		effectiveness, err := analysis.LoadSubmitterEffectiveness(request.Context(), server.Pool, analysis.SubmitterEffectivenessOptions{
+			Period: period,
+		})
+		if err != nil {
+			writeError(response, err)
+			return
+		}
+		page.Effectiveness = &effectiveness
+		for index := range effectiveness.Parties {
+			if effectiveness.Parties[index].PartySourceID == page.Party {
+				page.Submitted = &effectiveness.Parties[index]
+				break
+			}
+		}
 	}
 
 	server.render(response, "party_focus", page)
@@ -546,10 +674,27 @@ func (server Server) coalitionAnalysis(response http.ResponseWriter, request *ht
 		return
 	}
 
+	pivotal, err := analysis.LoadPivotalParties(request.Context(), server.Pool, analysis.PivotalPartyOptions{
+		Period: period,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	power, err := analysis.LoadVotingPower(request.Context(), server.Pool, period)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
 	server.render(response, "coalition_analysis", coalitionAnalysisPage{
 		Periods:   periods,
 		Period:    period,
 		Analysis:  coalition,
+		Pivotal:   pivotal,
+		Power:     power,
+		PowerRows: votingPowerRows(power),
 		Parties:   coalitionPartyAlignmentViews(period.PeriodKey, minCommon, coalition.Parties),
 		MinCommon: minCommon,
 	})
@@ -610,6 +755,123 @@ func (server Server) coalitionMotions(response http.ResponseWriter, request *htt
 	})
 }
 
+func (server Server) partyMap(response http.ResponseWriter, request *http.Request) {
+	periods, err := analysis.LoadCabinetPeriods(request.Context(), server.Pool, "nl-tweede-kamer")
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	period, err := selectedCabinetPeriod(periods, request.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(response, "invalid period", http.StatusBadRequest)
+		return
+	}
+
+	partyMap, err := analysis.LoadPartyMap(request.Context(), server.Pool, analysis.PartyMapOptions{
+		Jurisdiction: "nl-tweede-kamer",
+		DateFrom:     &period.StartedOn,
+		DateTo:       period.EndedOn,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	server.render(response, "party_map", partyMapPage{
+		Periods: periods,
+		Period:  period,
+		Map:     partyMap,
+		Points:  partyMapPoints(partyMap.Parties),
+		Axes:    partyMapAxes(partyMap.Axes),
+	})
+}
+
+func (server Server) rebellions(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	periods, err := analysis.LoadCabinetPeriods(request.Context(), server.Pool, "nl-tweede-kamer")
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	period, err := selectedCabinetPeriod(periods, query.Get("period"))
+	if err != nil {
+		http.Error(response, "invalid period", http.StatusBadRequest)
+		return
+	}
+	categories, err := categorize.LoadCategories(request.Context(), server.Pool, "nl-tweede-kamer")
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	category := query.Get("category")
+	rebellions, err := analysis.LoadRebellions(request.Context(), server.Pool, analysis.RebellionOptions{
+		Jurisdiction: "nl-tweede-kamer",
+		DateFrom:     &period.StartedOn,
+		DateTo:       period.EndedOn,
+		Category:     category,
+		Limit:        100,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	server.render(response, "rebellions", rebellionsPage{
+		Periods:    periods,
+		Period:     period,
+		Categories: categories,
+		Category:   category,
+		Rebellions: rebellions,
+	})
+}
+
+func (server Server) bills(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	limit := clamp(parseInt(query.Get("limit"), 25), 1, 100)
+	offset := max(parseInt(query.Get("offset"), 0), 0)
+	search := strings.TrimSpace(query.Get("search"))
+
+	bills, total, err := analysis.LoadBills(request.Context(), server.Pool, analysis.BillListOptions{
+		Jurisdiction: "nl-tweede-kamer",
+		Search:       search,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	page := billsPage{
+		Bills:  bills,
+		Total:  total,
+		Search: search,
+	}
+	if offset > 0 {
+		page.PrevURL = billsURL(search, limit, max(offset-limit, 0))
+	}
+	if offset+limit < total {
+		page.NextURL = billsURL(search, limit, offset+limit)
+	}
+
+	server.render(response, "bills", page)
+}
+
+func (server Server) bill(response http.ResponseWriter, request *http.Request) {
+	bill, err := analysis.LoadBill(request.Context(), server.Pool, request.PathValue("billKey"))
+	if analysis.IsNotFound(err) {
+		http.NotFound(response, request)
+		return
+	}
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+
+	server.render(response, "bill", billPage{Bill: bill})
+}
+
 func (server Server) votingCompass(response http.ResponseWriter, request *http.Request) {
 	// Arriving without a profile means the visitor has not chosen a period,
 	// subject, or party yet. The profile is required to answer, so redirect to
@@ -713,11 +975,34 @@ func (server Server) dataQuality(response http.ResponseWriter, request *http.Req
 		writeError(response, err)
 		return
 	}
+	running, err := status.LoadRunProgress(request.Context(), server.Pool)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	reconcile, err := status.LoadReconcileReport(request.Context(), server.Pool)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	rules, err := quality.LoadRuleSummaries(request.Context(), server.Pool)
+	if err != nil {
+		writeError(response, err)
+		return
+	}
+	now := time.Now()
+	progress := make([]runProgressView, 0, len(running))
+	for _, run := range running {
+		progress = append(progress, newRunProgressView(run, now))
+	}
 
 	server.render(response, "data_quality", dataQualityPage{
 		Summary:                 summary,
 		Backfill:                backfill,
 		RunHealth:               runHealth,
+		Progress:                progress,
+		Reconcile:               reconcile,
+		Rules:                   rules,
 		Runs:                    runs,
 		BackfillBatchSize:       500,
 		BackfillBatchesEstimate: ceilDiv(backfill.EligibleMotions, 500),
@@ -796,6 +1081,7 @@ func loadMotions(ctx context.Context, pool *pgxpool.Pool, options motionListOpti
 			FROM motions m
 			WHERE m.jurisdiction_key = $1
 			  AND m.source_deleted = false
+			  AND ($7::text = 'all' OR m.kind = $7)
 			  AND (
 			    $2::text IS NULL
 			    OR m.title ILIKE '%' || $2 || '%'
@@ -854,7 +1140,7 @@ func loadMotions(ctx context.Context, pool *pgxpool.Pool, options motionListOpti
 		       p.total
 		FROM paged p
 		ORDER BY p.proposed_at DESC NULLS LAST, p.source_updated_at DESC NULLS LAST
-	`, options.Jurisdiction, search, options.Limit, options.Offset, options.WithVotes, options.Category)
+	`, options.Jurisdiction, search, options.Limit, options.Offset, options.WithVotes, options.Category, options.Kind)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -926,12 +1212,17 @@ func loadDecisions(ctx context.Context, pool *pgxpool.Pool, motionKey string) ([
 		       d.status,
 		       d.decision_order,
 		       count(v.vote_key) FILTER (WHERE v.source_deleted = false)::int AS vote_count,
-		       count(v.vote_key) FILTER (WHERE v.source_deleted = false AND v.mistake = true)::int AS mistake_count
+		       count(v.vote_key) FILTER (WHERE v.source_deleted = false AND v.mistake = true)::int AS mistake_count,
+		       COALESCE(t.seats_for, 0),
+		       COALESCE(t.seats_against, 0),
+		       COALESCE(t.seats_not_voting, 0),
+		       COALESCE(t.outcome, 'unknown')
 		FROM decisions d
 		LEFT JOIN votes v ON v.decision_key = d.decision_key
+		LEFT JOIN decision_tallies t ON t.decision_key = d.decision_key
 		WHERE d.motion_key = $1
 		  AND d.source_deleted = false
-		GROUP BY d.decision_key
+		GROUP BY d.decision_key, t.decision_key
 		ORDER BY d.decision_order NULLS LAST, d.source_updated_at NULLS LAST
 	`, motionKey)
 	if err != nil {
@@ -951,6 +1242,10 @@ func loadDecisions(ctx context.Context, pool *pgxpool.Pool, motionKey string) ([
 			&decision.DecisionOrder,
 			&decision.VoteCount,
 			&decision.MistakeCount,
+			&decision.SeatsFor,
+			&decision.SeatsAgainst,
+			&decision.SeatsNotVoting,
+			&decision.Outcome,
 		); err != nil {
 			return nil, err
 		}
@@ -991,6 +1286,72 @@ func loadPartyPositions(ctx context.Context, pool *pgxpool.Pool, motionKey strin
 	return positions, rows.Err()
 }
 
+// loadMemberVotes returns the per-member votes of a hoofdelijke stemming. Other
+// votes are cast per fractie and carry no person, so for most motions this is
+// empty.
+func loadMemberVotes(ctx context.Context, pool *pgxpool.Pool, motionKey string) ([]memberVote, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT v.person_source_id,
+		       COALESCE(m.full_name, v.actor_name, v.person_source_id) AS member_name,
+		       COALESCE(v.party_name, m.party_label, v.party_source_id, 'onbekend') AS party_name,
+		       v.vote_type
+		FROM votes v
+		LEFT JOIN members m ON m.source_key = v.source_key
+		                   AND m.source_id = v.person_source_id
+		WHERE v.motion_key = $1
+		  AND v.person_source_id IS NOT NULL
+		  AND v.source_deleted = false
+		  AND v.mistake = false
+		ORDER BY party_name, m.last_name NULLS LAST, member_name
+	`, motionKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := []memberVote{}
+	for rows.Next() {
+		var vote memberVote
+		if err := rows.Scan(&vote.PersonSourceID, &vote.MemberName, &vote.PartyName, &vote.VoteType); err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+// loadMotionSubmitters lists the indiener first, then the medeindieners.
+func loadMotionSubmitters(ctx context.Context, pool *pgxpool.Pool, motionKey string) ([]motionSubmitter, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT s.relation,
+		       s.person_source_id,
+		       COALESCE(m.full_name, s.actor_name, s.person_source_id, 'onbekend') AS member_name,
+		       COALESCE(p.short_name, s.party_name) AS party_name
+		FROM motion_submitters s
+		LEFT JOIN members m ON m.source_key = s.source_key
+		                   AND m.source_id = s.person_source_id
+		LEFT JOIN parties p ON p.source_key = s.source_key
+		                   AND p.source_id = s.party_source_id
+		WHERE s.motion_key = $1
+		  AND s.source_deleted = false
+		ORDER BY s.relation <> 'Indiener', m.last_name NULLS LAST, member_name
+	`, motionKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submitters := []motionSubmitter{}
+	for rows.Next() {
+		var submitter motionSubmitter
+		if err := rows.Scan(&submitter.Relation, &submitter.PersonSourceID, &submitter.MemberName, &submitter.PartyName); err != nil {
+			return nil, err
+		}
+		submitters = append(submitters, submitter)
+	}
+	return submitters, rows.Err()
+}
+
 func loadRecentVotedMotions(ctx context.Context, pool *pgxpool.Pool, jurisdiction string, limit int) ([]votedMotion, error) {
 	rows, err := pool.Query(ctx, `
 		WITH recent AS (
@@ -1117,16 +1478,41 @@ type motionsPage struct {
 	Search     string
 	WithVotes  bool
 	Category   string
+	Kind       string
 	Categories []categorize.Category
 	PrevURL    string
 	NextURL    string
 }
 
 type motionPage struct {
-	Motion     motion
-	Decisions  []decision
-	Positions  []partyPosition
-	Categories []motionCategory
+	Motion      motion
+	Decisions   []decision
+	Positions   []partyPosition
+	Categories  []motionCategory
+	MemberVotes []memberVote
+	Submitters  []motionSubmitter
+	// Findings are the open data-quality findings on the motion.
+	Findings []quality.Finding
+}
+
+type motionHistoryPage struct {
+	Motion    motion
+	Records   []inspect.RecordHistory
+	Unchanged int
+}
+
+type motionSubmitter struct {
+	Relation       string
+	PersonSourceID *string
+	MemberName     string
+	PartyName      *string
+}
+
+type memberVote struct {
+	PersonSourceID string
+	MemberName     string
+	PartyName      string
+	VoteType       *string
 }
 
 type motionCategory struct {
@@ -1167,6 +1553,40 @@ type partyComparisonPage struct {
 	SwapURL      string
 	PrevURL      string
 	NextURL      string
+	Interval     string
+	Intervals    []likenessIntervalView
+	Chart        likenessChart
+}
+
+type likenessIntervalView struct {
+	Key      string
+	Label    string
+	URL      string
+	Selected bool
+}
+
+// likenessChart is the agreement of a pair per bucket drawn in the SVG
+// viewBox of the comparison page. Path skips empty buckets, so a gap in the
+// line is a bucket without common motions.
+type likenessChart struct {
+	Points []likenessChartPoint
+	Path   string
+	Labels []likenessChartPoint
+	Grid   []likenessChartGridLine
+}
+
+type likenessChartPoint struct {
+	analysis.PartyLikenessPoint
+	X float64
+	Y float64
+	// Thin buckets have too few common motions for their percentage to say
+	// much and are drawn greyed out.
+	Thin bool
+}
+
+type likenessChartGridLine struct {
+	Y     float64
+	Label string
 }
 
 type comparisonCategoryView struct {
@@ -1194,6 +1614,11 @@ type partyFocusPage struct {
 	MinCommon int
 	Focus     *analysis.PartyFocus
 	Likeness  []partyFocusLikenessView
+	// Cohesion is limited to the party, so Parties holds at most one row.
+	Cohesion *analysis.PartyCohesion
+	// Submitted is nil when the party filed no motions in the period.
+	Submitted     *analysis.PartySubmitterStats
+	Effectiveness *analysis.SubmitterEffectiveness
 }
 
 type partyFocusLikenessView struct {
@@ -1205,10 +1630,73 @@ type coalitionAnalysisPage struct {
 	Periods   []analysis.CabinetPeriod
 	Period    analysis.CabinetPeriod
 	Analysis  analysis.CoalitionAnalysis
+	Pivotal   analysis.PivotalParties
+	Power     analysis.VotingPower
+	PowerRows []votingPowerRow
 	Parties   []coalitionPartyAlignmentView
 	MinCommon int
 }
 
+// votingPowerRow shows the fractions of a PartyPower as percentages.
+type votingPowerRow struct {
+	analysis.PartyPower
+}
+
+func (row votingPowerRow) SeatPercent() float64 {
+	return row.SeatShare * 100
+}
+
+func (row votingPowerRow) ShapleyShubikPercent() float64 {
+	return row.ShapleyShubik * 100
+}
+
+func (row votingPowerRow) BanzhafPercent() float64 {
+	return row.Banzhaf * 100
+}
+
+type partyMapPage struct {
+	Periods []analysis.CabinetPeriod
+	Period  analysis.CabinetPeriod
+	Map     analysis.PartyMap
+	Points  []partyMapPoint
+	Axes    []partyMapAxis
+}
+
+type partyMapAxis struct {
+	analysis.PartyMapAxis
+}
+
+func (axis partyMapAxis) ExplainedPercent() float64 {
+	return axis.ExplainedVariance * 100
+}
+
+// partyMapPoint is a fractie placed in the SVG viewBox of the party map.
+type partyMapPoint struct {
+	analysis.PartyMapPoint
+	CX float64
+	CY float64
+}
+
+type rebellionsPage struct {
+	Periods    []analysis.CabinetPeriod
+	Period     analysis.CabinetPeriod
+	Categories []categorize.Category
+	Category   string
+	Rebellions analysis.Rebellions
+}
+
+type billsPage struct {
+	Bills   []analysis.BillSummary
+	Total   int
+	Search  string
+	PrevURL string
+	NextURL string
+}
+
+type billPage struct {
+	Bill analysis.Bill
+}
+
 type coalitionMotionsPage struct {
 	Period    analysis.CabinetPeriod
 	PartyName string
@@ -1243,6 +1731,9 @@ type dataQualityPage struct {
 	Summary                 status.Summary
 	Backfill                status.VoteBackfill
 	RunHealth               status.IngestionRunHealth
+	Progress                []runProgressView
+	Reconcile               status.ReconcileReport
+	Rules                   []quality.RuleSummary
 	Runs                    []ingestionRun
 	BackfillBatchSize       int64
 	BackfillBatchesEstimate int64
@@ -1263,6 +1754,32 @@ type likenessParty struct {
 	Monogram string
 }
 
+// runProgressView is a running run as the progress bar on /data-quality shows
+// it. Percent is -1 for pipelines that do not know their total.
+type runProgressView struct {
+	status.RunProgress
+	Percent int
+	Rate    string
+	Elapsed string
+	ETA     string
+}
+
+func newRunProgressView(run status.RunProgress, now time.Time) runProgressView {
+	view := runProgressView{
+		RunProgress: run,
+		Percent:     -1,
+		Rate:        fmt.Sprintf("%.1f/s", run.Rate()),
+		Elapsed:     now.Sub(run.StartedAt).Round(time.Second).String(),
+	}
+	if run.Total > 0 {
+		view.Percent = int(run.Fraction() * 100)
+	}
+	if eta := run.ETA(); !eta.IsZero() {
+		view.ETA = max(0, eta.Sub(now)).Round(time.Second).String()
+	}
+	return view
+}
+
 type ingestionRun struct {
 	ID             int64
 	Pipeline       string
@@ -1278,6 +1795,7 @@ type motionListOptions struct {
 	Search       string
 	WithVotes    bool
 	Category     string
+	Kind         string
 	Limit        int
 	Offset       int
 }
@@ -1311,6 +1829,37 @@ type decision struct {
 	DecisionOrder *int
 	VoteCount     int
 	MistakeCount  int
+	// Seats come from decision_tallies and are zero until the decision has
+	// votes.
+	SeatsFor       int
+	SeatsAgainst   int
+	SeatsNotVoting int
+	Outcome        politics.Outcome
+}
+
+func (decision decision) Margin() int {
+	return decision.SeatsFor - decision.SeatsAgainst
+}
+
+// SeatWidth sizes one segment of the seat bar against the whole house.
+func (decision decision) SeatWidth(seats int) string {
+	return shareValue(seats, decision.SeatsFor+decision.SeatsAgainst+decision.SeatsNotVoting-seats)
+}
+
+// OutcomeLabel is the outcome in Dutch.
+func (decision decision) OutcomeLabel() string {
+	switch decision.Outcome {
+	case politics.OutcomeAdopted:
+		return "aangenomen"
+	case politics.OutcomeRejected:
+		return "verworpen"
+	case politics.OutcomeWithdrawn:
+		return "ingetrokken"
+	case politics.OutcomeHeld:
+		return "aangehouden"
+	default:
+		return "onbekend"
+	}
 }
 
 type partyPosition struct {
@@ -1322,7 +1871,7 @@ type partyPosition struct {
 	TotalVotes    int
 }
 
-func motionsURL(search string, withVotes bool, category string, limit int, offset int) string {
+func motionsURL(search string, withVotes bool, category string, kind string, limit int, offset int) string {
 	query := url.Values{}
 	if search != "" {
 		query.Set("search", search)
@@ -1333,6 +1882,9 @@ func motionsURL(search string, withVotes bool, category string, limit int, offse
 	if category != "" {
 		query.Set("category", category)
 	}
+	if kind != "" && kind != analysis.MotionKindMotion {
+		query.Set("kind", kind)
+	}
 	if limit != 25 {
 		query.Set("limit", strconv.Itoa(limit))
 	}
@@ -1345,6 +1897,23 @@ func motionsURL(search string, withVotes bool, category string, limit int, offse
 	return "/motions"
 }
 
+func billsURL(search string, limit int, offset int) string {
+	query := url.Values{}
+	if search != "" {
+		query.Set("search", search)
+	}
+	if limit != 25 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if encoded := query.Encode(); encoded != "" {
+		return "/wetsvoorstellen?" + encoded
+	}
+	return "/wetsvoorstellen"
+}
+
 func likenessParties(rows []analysis.PartyLikeness, logos map[string]bool) []likenessParty {
 	seen := map[string]string{}
 	for _, row := range rows {
@@ -1603,6 +2172,132 @@ func coalitionMotionsURL(periodKey string, partySourceID string, partyName strin
 	return "/coalition-analysis/motions?" + query.Encode()
 }
 
+// votingPowerRows lists the fracties with the coalition total last, when the
+// period has coalition parties.
+func votingPowerRows(power analysis.VotingPower) []votingPowerRow {
+	rows := make([]votingPowerRow, 0, len(power.Parties)+1)
+	for _, party := range power.Parties {
+		rows = append(rows, votingPowerRow{PartyPower: party})
+	}
+	if power.Coalition.Seats > 0 {
+		rows = append(rows, votingPowerRow{PartyPower: power.Coalition})
+	}
+	return rows
+}
+
+func partyMapAxes(axes []analysis.PartyMapAxis) []partyMapAxis {
+	views := make([]partyMapAxis, 0, len(axes))
+	for _, axis := range axes {
+		views = append(views, partyMapAxis{PartyMapAxis: axis})
+	}
+	return views
+}
+
+// Size of the party map viewBox and the margin kept free for labels.
+const (
+	partyMapWidth   = 640
+	partyMapHeight  = 440
+	partyMapPadding = 48
+)
+
+// partyMapPoints scales the coordinates into the viewBox. Both axes share one
+// scale so distances stay comparable; the map is centred on the origin and
+// the SVG y axis runs downwards.
+func partyMapPoints(parties []analysis.PartyMapPoint) []partyMapPoint {
+	extent := 0.0
+	for _, party := range parties {
+		extent = max(extent, math.Abs(party.X), math.Abs(party.Y))
+	}
+	scale := 0.0
+	if extent > 0 {
+		scale = min(partyMapWidth/2-partyMapPadding, partyMapHeight/2-partyMapPadding) / extent
+	}
+
+	points := make([]partyMapPoint, 0, len(parties))
+	for _, party := range parties {
+		points = append(points, partyMapPoint{
+			PartyMapPoint: party,
+			CX:            partyMapWidth/2 + party.X*scale,
+			CY:            partyMapHeight/2 - party.Y*scale,
+		})
+	}
+	return points
+}
+
+// Size of the likeness chart viewBox, the margins kept free for the axis
+// labels, and the fewest common motions for a bucket to count as full.
+const (
+	likenessChartWidth       = 640
+	likenessChartHeight      = 240
+	likenessChartLeft        = 44
+	likenessChartRight       = 16
+	likenessChartTop         = 12
+	likenessChartBottom      = 32
+	likenessChartThinMotions = 5
+	likenessChartMaxLabels   = 8
+)
+
+// withLikenessInterval adds the chart interval to a comparison URL unless it
+// is the default.
+func withLikenessInterval(rawURL string, interval string) string {
+	if interval == "" || interval == "quarter" {
+		return rawURL
+	}
+	return rawURL + "&interval=" + url.QueryEscape(interval)
+}
+
+// likenessChartFor spreads the buckets evenly over the x axis and maps 0 to
+// 100 percent agreement onto the y axis, which runs downwards in SVG.
+func likenessChartFor(series analysis.PartyLikenessSeries) likenessChart {
+	chart := likenessChart{}
+	plotWidth := float64(likenessChartWidth - likenessChartLeft - likenessChartRight)
+	plotHeight := float64(likenessChartHeight - likenessChartTop - likenessChartBottom)
+	for percent := 0; percent <= 100; percent += 25 {
+		chart.Grid = append(chart.Grid, likenessChartGridLine{
+			Y:     likenessChartTop + plotHeight*float64(100-percent)/100,
+			Label: strconv.Itoa(percent) + "%",
+		})
+	}
+
+	step := 1
+	if len(series.Points) > likenessChartMaxLabels {
+		step = (len(series.Points) + likenessChartMaxLabels - 1) / likenessChartMaxLabels
+	}
+	path := strings.Builder{}
+	drawing := false
+	for index, point := range series.Points {
+		x := likenessChartLeft + plotWidth/2
+		if len(series.Points) > 1 {
+			x = likenessChartLeft + plotWidth*float64(index)/float64(len(series.Points)-1)
+		}
+		chartPoint := likenessChartPoint{
+			PartyLikenessPoint: point,
+			X:                  x,
+			Y:                  likenessChartTop + plotHeight*(100-point.Similarity)/100,
+			Thin:               point.CommonMotions < likenessChartThinMotions,
+		}
+		if index%step == 0 {
+			chart.Labels = append(chart.Labels, chartPoint)
+		}
+		if point.CommonMotions == 0 {
+			drawing = false
+			continue
+		}
+		command := "L"
+		if !drawing {
+			command = "M"
+		}
+		if path.Len() > 0 {
+			path.WriteString(" ")
+		}
+		fmt.Fprintf(&path, "%s%.1f %.1f", command, chartPoint.X, chartPoint.Y)
+		drawing = true
+		chart.Points = append(chart.Points, chartPoint)
+	}
+	chart.Path = path.String()
+	return chart
+}
+
 func selectedCabinetPeriod(periods []analysis.CabinetPeriod, periodKey string) (analysis.CabinetPeriod, error) {
 	if periodKey == "" {
 		if len(periods) == 0 {
@@ -3,9 +3,14 @@ package web
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"partijgedrag/internal/analysis"
+	"partijgedrag/internal/inspect"
+	"partijgedrag/internal/politics"
+	"partijgedrag/internal/status"
 )
 
 func TestNewParsesTemplates(t *testing.T) {
@@ -14,7 +19,7 @@ func TestNewParsesTemplates(t *testing.T) {
 		t.Fatalf("New() returned error: %v", err)
 	}
 
-	for _, name := range []string{"home", "motions", "motion", "party_likeness", "party_comparison", "party_focus", "coalition_analysis", "coalition_motions", "voting_compass", "compass_results", "data_quality"} {
+	for _, name := range []string{"home", "motions", "motion", "motion_history", "party_likeness", "party_comparison", "party_focus", "party_map", "coalition_analysis", "coalition_motions", "rebellions", "bills", "bill", "voting_compass", "compass_results", "data_quality"} {
 		if server.templates[name] == nil {
 			t.Fatalf("template %q was not parsed", name)
 		}
@@ -102,11 +107,17 @@ func TestComparisonRelationViewsHandleUnknownCategory(t *testing.T) {
 }
 
 func TestMotionsURL(t *testing.T) {
-	got := motionsURL("zorg wonen", true, "zorg-en-gezondheid", 50, 100)
+	got := motionsURL("zorg wonen", true, "zorg-en-gezondheid", analysis.MotionKindMotion, 50, 100)
 	want := "/motions?category=zorg-en-gezondheid&limit=50&offset=100&search=zorg+wonen&withVotes=true"
 	if got != want {
 		t.Fatalf("motionsURL() = %q, want %q", got, want)
 	}
+
+	got = motionsURL("", false, "", analysis.MotionKindAmendment, 25, 0)
+	want = "/motions?kind=Amendement"
+	if got != want {
+		t.Fatalf("motionsURL() = %q, want %q", got, want)
+	}
 }
 
 func TestCoalitionMotionsURL(t *testing.T) {
@@ -136,3 +147,229 @@ func TestStaticCacheControl(t *testing.T) {
 		t.Fatalf("expected Cache-Control %q for static files, got %q", want, got)
 	}
 }
+
+func TestDataQualityRendersRunProgress(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startedAt := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	updatedAt := startedAt.Add(100 * time.Second)
+	now := updatedAt.Add(time.Second)
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "data_quality", dataQualityPage{
+		Progress: []runProgressView{
+			newRunProgressView(status.RunProgress{RunID: 7, Pipeline: "motion_votes.raw", StartedAt: startedAt, UpdatedAt: &updatedAt, Items: 200, Total: 500}, now),
+			newRunProgressView(status.RunProgress{RunID: 8, Pipeline: "motions.raw", StartedAt: startedAt, UpdatedAt: &updatedAt, Pages: 3, Items: 300}, now),
+		},
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"width: 40%", "200 / 500", "2m29s", "totaal onbekend"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
+
+func TestMotionHistoryRendersFieldChanges(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seenAt := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "motion_history", motionHistoryPage{
+		Motion: motion{MotionKey: "tk:motion:1", SourceID: "z1"},
+		Records: []inspect.RecordHistory{{
+			Collection: "Stemming",
+			SourceID:   "s1",
+			Label:      "VVD",
+			Versions: []inspect.RecordVersion{
+				{Number: 1, SeenAt: seenAt},
+				{Number: 2, SeenAt: seenAt.AddDate(0, 0, 7), Changes: []inspect.FieldChange{{Field: "Vergissing", Before: "false", After: "true"}}},
+			},
+		}},
+		Unchanged: 14,
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Stemming VVD", "eerste versie", "Vergissing", "2024-03-12 12:00", "14 ongewijzigd"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
+
+func TestMotionRendersDecisionSeatBar(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "motion", motionPage{
+		Motion: motion{MotionKey: "tk:motion:1", SourceID: "z1"},
+		Decisions: []decision{{
+			DecisionKey:    "tk:decision:1",
+			VoteCount:      15,
+			SeatsFor:       74,
+			SeatsAgainst:   72,
+			SeatsNotVoting: 4,
+			Outcome:        politics.OutcomeAdopted,
+		}},
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"aangenomen", "marge 2, 4 niet gestemd", "width: 49.3%", "width: 48.0%"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
+
+func TestPartyFocusRendersCohesion(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := "Motie over de zorg"
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "party_focus", partyFocusPage{
+		Party: "vvd",
+		Focus: &analysis.PartyFocus{Party: analysis.Party{SourceID: "vvd", ShortName: "VVD"}},
+		Cohesion: &analysis.PartyCohesion{
+			Parties: []analysis.PartyCohesionStats{{PartySourceID: "vvd", PartyName: "VVD", CohesionStats: analysis.CohesionStats{Decisions: 40, SplitVotes: 2, Rice: 0.9875}}},
+			Divided: []analysis.DividedPartyVote{{MotionKey: "tk:motion:1", Subject: &subject, SeatsFor: 15, SeatsAgainst: 5, Rice: 0.5}},
+		},
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Eensgezindheid", "0.988", "Motie over de zorg", "0.500"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
+
+func TestPartyMapPointsShareOneScale(t *testing.T) {
+	points := partyMapPoints([]analysis.PartyMapPoint{
+		{PartySourceID: "gl", X: -2, Y: 0},
+		{PartySourceID: "pvv", X: 2, Y: 1},
+	})
+
+	// The widest coordinate reaches the padding on the shorter side.
+	if points[0].CX != 148 || points[0].CY != 220 {
+		t.Fatalf("gl at (%v, %v), want (148, 220)", points[0].CX, points[0].CY)
+	}
+	if points[1].CX != 492 || points[1].CY != 134 {
+		t.Fatalf("pvv at (%v, %v), want (492, 134)", points[1].CX, points[1].CY)
+	}
+}
+
+func TestLikenessChartBreaksLineAtEmptyBuckets(t *testing.T) {
+	chart := likenessChartFor(analysis.PartyLikenessSeries{Points: []analysis.PartyLikenessPoint{
+		{Bucket: "2024-Q1", CommonMotions: 20, SameVotes: 20, Similarity: 100},
+		{Bucket: "2024-Q2", CommonMotions: 10, SameVotes: 5, Similarity: 50},
+		{Bucket: "2024-Q3"},
+		{Bucket: "2024-Q4", CommonMotions: 2, SameVotes: 0, Similarity: 0},
+	}})
+
+	if want := "M44.0 12.0 L237.3 110.0 M624.0 208.0"; chart.Path != want {
+		t.Fatalf("path = %q, want %q", chart.Path, want)
+	}
+	if len(chart.Points) != 3 || chart.Points[0].Thin || !chart.Points[2].Thin {
+		t.Fatalf("points = %+v, want three with only the last thin", chart.Points)
+	}
+	if len(chart.Labels) != 4 || len(chart.Grid) != 5 {
+		t.Fatalf("labels = %d, grid = %d, want 4 and 5", len(chart.Labels), len(chart.Grid))
+	}
+}
+
+func TestPartyComparisonRendersLikenessChart(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "party_comparison", partyComparisonPage{
+		Period: analysis.CabinetPeriod{PeriodKey: "rutte-iv", Name: "Rutte IV"},
+		Party1: likenessParty{SourceID: "vvd-id", ShortName: "VVD"},
+		Party2: likenessParty{SourceID: "pvv-id", ShortName: "PVV"},
+		Intervals: []likenessIntervalView{
+			{Key: "month", Label: "Per maand", URL: "/party-likeness/compare?interval=month"},
+			{Key: "quarter", Label: "Per kwartaal", URL: "/party-likeness/compare", Selected: true},
+		},
+		Chart: likenessChartFor(analysis.PartyLikenessSeries{Points: []analysis.PartyLikenessPoint{
+			{Bucket: "2024-Q1", CommonMotions: 20, SameVotes: 15, Similarity: 75},
+			{Bucket: "2024-Q2", CommonMotions: 3, SameVotes: 3, Similarity: 100},
+		}}),
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{`class="likeness-chart"`, "2024-Q1: 75% gelijk over 20 moties", `class="thin"`, `toggle toggle-on" href="/party-likeness/compare">Per kwartaal`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("comparison page does not contain %q", want)
+		}
+	}
+}
+
+func TestCoalitionAnalysisRendersPivotalParties(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	number := "36200-12"
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "coalition_analysis", coalitionAnalysisPage{
+		Period: analysis.CabinetPeriod{PeriodKey: "schoof-i", Name: "Schoof I"},
+		Pivotal: analysis.PivotalParties{
+			Decisions:         40,
+			CoalitionDefeated: 3,
+			Parties: []analysis.PivotalPartyStats{
+				{PartySourceID: "nsc-id", PartyName: "NSC", CoalitionParty: true, Decisions: 40, Winning: 30, Pivotal: 12, SolePivotal: 4},
+			},
+			CoalitionDefeats: []analysis.PivotalDecision{
+				{MotionKey: "m1", Number: &number, SeatsFor: 76, SeatsAgainst: 74, Adopted: true, PivotalParties: []string{"CDA", "JA21"}},
+			},
+		},
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Doorslaggevend", "40 stemmingen · 3 verloren door de coalitie", "Doorslag: CDA, JA21", "76 zetels voor, 74 zetels tegen"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("coalition analysis does not contain %q", want)
+		}
+	}
+}
+
+func TestCoalitionAnalysisRendersVotingPower(t *testing.T) {
+	server, err := New(nil, false)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	power := analysis.ComputeVotingPower(analysis.SeatSourceVotes, []analysis.PartySeats{
+		{PartySourceID: "a", PartyName: "A", Seats: 50, CoalitionParty: true},
+		{PartySourceID: "b", PartyName: "B", Seats: 50, CoalitionParty: true},
+		{PartySourceID: "c", PartyName: "C", Seats: 50},
+	})
+	recorder := httptest.NewRecorder()
+	server.render(recorder, "coalition_analysis", coalitionAnalysisPage{
+		Period:    analysis.CabinetPeriod{PeriodKey: "test", Name: "Test"},
+		Power:     power,
+		PowerRows: votingPowerRows(power),
+	})
+
+	body := recorder.Body.String()
+	for _, want := range []string{"150 zetels · meerderheid 76", "<td>A <span class=\"muted\">coalitie</span></td>", "<td>Coalitie</td>", "66.7%"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("coalition analysis does not contain %q", want)
+		}
+	}
+}